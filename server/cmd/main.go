@@ -33,12 +33,21 @@ func main() {
 	http.HandleFunc("/api/new", apiServer.NewGame)
 	http.HandleFunc("/api/join", apiServer.JoinGame)
 	http.HandleFunc("/api/join-state/", apiServer.JoinGameState)
+	http.HandleFunc("/api/add-bot", apiServer.AddBot)
+	http.HandleFunc("/api/leave", apiServer.LeaveGame)
+	http.HandleFunc("/api/seat", apiServer.ChangeSeat)
+	http.HandleFunc("/api/seat-respond", apiServer.RespondToSeatSwap)
+	http.HandleFunc("/api/replace", apiServer.ReplacePlayer)
 	http.HandleFunc("/api/deal", apiServer.DealCards)
 	http.HandleFunc("/api/pass", apiServer.PassCard)
+	http.HandleFunc("/api/misdeal", apiServer.ClaimMisdeal)
 	http.HandleFunc("/api/bid", apiServer.PlaceBid)
 	http.HandleFunc("/api/trump", apiServer.CallTrump)
 	http.HandleFunc("/api/play", apiServer.PlayCard)
+	http.HandleFunc("/api/undo", apiServer.RequestUndo)
+	http.HandleFunc("/api/undo-respond", apiServer.RespondToUndo)
 	http.HandleFunc("/api/state/", apiServer.GameState)
+	http.HandleFunc("/api/turn-clock", apiServer.EnforceTurnClock)
 
 	appengine.Main()
 }
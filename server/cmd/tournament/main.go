@@ -0,0 +1,265 @@
+// Command tournament pits two bot strategies against each other over seeded deals and reports how each did.
+//
+// Each seed is played as a full game twice, once with each strategy in each partnership. Both games have the seed's
+// deals and first dealer, so each strategy is dealt the cards the other had, in the same seats relative to the dealer,
+// and card and seat luck cancel out; rotating the deal between the two games would break that pairing. The first
+// dealer rotates with the seed, so neither set of seats always deals first. The games are played by the
+// game package's engine, so any error it reports is an engine bug and stops the tournament. A tournament plays out
+// the same again from the same seed, unless the search strategy is limited by time.
+//
+//	go run ./cmd/tournament -a heuristic-hard -b heuristic-easy -deals 500
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"math/rand"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/squee1945/threespot/server/pkg/deck"
+	"github.com/squee1945/threespot/server/pkg/game"
+)
+
+var (
+	flagA          = flag.String("a", game.HeuristicHardStrategy, "first strategy; one of "+strings.Join(game.StrategyNames(), ", "))
+	flagB          = flag.String("b", game.HeuristicEasyStrategy, "second strategy")
+	flagDeals      = flag.Int("deals", 100, "number of seeded deals; each is played as a game from both sides")
+	flagSeed       = flag.Int64("seed", 1, "first seed")
+	flagPreset     = flag.String("preset", "", "rules preset (default the standard rules)")
+	flagMaxHands   = flag.Int("max-hands", 200, "hands played, not counting those thrown in, after which a game is abandoned unfinished")
+	flagIterations = flag.Int("search-iterations", game.TournamentSearchBudget.Iterations, "search strategy iterations per decision; 0 for no limit")
+	flagDuration   = flag.Duration("search-duration", game.TournamentSearchBudget.Duration, "search strategy time per decision; 0 for no limit")
+	flagVerbose    = flag.Bool("v", false, "log the result of each game")
+)
+
+// z is the normal quantile for 95% confidence intervals.
+const z = 1.96
+
+// record is how a strategy did over the tournament.
+type record struct {
+	name       string
+	games      int
+	wins       int
+	unfinished int
+	points     []int // The score the strategy's team gained each hand played; thrown-in hands are not counted.
+	bids       int
+	made       int
+}
+
+func main() {
+	flag.Parse()
+	rules := game.NewRules()
+	if *flagPreset != "" {
+		var err error
+		if rules, err = game.NewRulesFromPreset(*flagPreset); err != nil {
+			log.Fatalf("preset %q: %v", *flagPreset, err)
+		}
+	}
+	if game.NumTeams(rules.Players()) != 2 {
+		log.Fatalf("tournaments are for two partnerships, rules have %d players", rules.Players())
+	}
+	budget := game.SearchBudget{Iterations: *flagIterations, Duration: *flagDuration}
+	for _, name := range []string{*flagA, *flagB} {
+		if _, err := newStrategy(name, 0, budget); err != nil {
+			log.Fatalf("strategy %q: %v", name, err)
+		}
+	}
+
+	records := []*record{{name: *flagA}, {name: *flagB}}
+	for seed := *flagSeed; seed < *flagSeed+int64(*flagDeals); seed++ {
+		for side := 0; side < 2; side++ {
+			if err := playGame(rules, seed, side, budget, *flagMaxHands, records); err != nil {
+				log.Fatalf("seed %d, %s as team 0: %v", seed, records[side].name, err)
+			}
+		}
+	}
+	report(os.Stdout, records)
+}
+
+// newStrategy returns the named strategy; the strategies that make random choices draw them from the seed, so a
+// tournament can be played again.
+func newStrategy(name string, seed int64, budget game.SearchBudget) (game.Strategy, error) {
+	switch name {
+	case game.RandomStrategy:
+		return game.NewRandomStrategy(rand.NewSource(seed)), nil
+	case game.SearchStrategy:
+		return game.NewSearchStrategy(budget, rand.NewSource(seed)), nil
+	}
+	return game.NewStrategy(name)
+}
+
+// newTable sets up the seed's game with records[side] as team 0 and the other as team 1. It returns the engine, the
+// strategy in each seat, and the record of each team. Only side changes who sits where; the deals and the first
+// dealer come from the seed.
+func newTable(rules game.Rules, seed int64, side int, budget game.SearchBudget, records []*record) (*game.Engine, []game.Strategy, []*record, error) {
+	players := rules.Players()
+	e, err := game.NewEngine(rules, int(seed%int64(players)), rand.NewSource(seed))
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	seats := make([]game.Strategy, players)
+	teamRecords := make([]*record, 2)
+	for pos := range seats {
+		team := game.TeamOf(pos, players)
+		teamRecords[team] = records[(side+team)%2]
+		if seats[pos], err = newStrategy(teamRecords[team].name, seed*int64(players)+int64(pos), budget); err != nil {
+			return nil, nil, nil, err
+		}
+	}
+	return e, seats, teamRecords, nil
+}
+
+// playGame plays the seed's game with records[side] as team 0 and the other as team 1, abandoning it after maxHands
+// hands are played, and adds the results to the records.
+func playGame(rules game.Rules, seed int64, side int, budget game.SearchBudget, maxHands int, records []*record) error {
+	players := rules.Players()
+	e, seats, teamRecords, err := newTable(rules, seed, side, budget, records)
+	if err != nil {
+		return err
+	}
+
+	for e.State() != game.CompletedState && playedHands(e.Score()) < maxHands {
+		pos, err := e.PosToPlay()
+		if err != nil {
+			return err
+		}
+		if err := e.Act(pos, seats[pos]); err != nil {
+			return fmt.Errorf("%v by %d in hand %d: %v", e.State(), pos, len(e.Score().Hands())+1, err)
+		}
+	}
+
+	score := e.Score()
+	if err := checkHands(score); err != nil {
+		return err
+	}
+	for team, r := range teamRecords {
+		r.games++
+		switch {
+		case e.State() != game.CompletedState:
+			r.unfinished++
+		case score.Winner() == team:
+			r.wins++
+		}
+	}
+	scores := score.Scores()
+	for _, hand := range score.Hands() {
+		if hand.ThrownIn() {
+			continue
+		}
+		for team, r := range teamRecords {
+			gained := scores[hand.ScoreIndex][team]
+			if hand.ScoreIndex > 0 {
+				gained -= scores[hand.ScoreIndex-1][team]
+			}
+			r.points = append(r.points, gained)
+		}
+		r := teamRecords[game.TeamOf(hand.BidderPos, players)]
+		r.bids++
+		if hand.MadeBid() {
+			r.made++
+		}
+	}
+	if *flagVerbose {
+		log.Printf("seed %d: %s %v %s, winner %d after %d hands", seed, teamRecords[0].name, score.CurrentScore(), teamRecords[1].name, score.Winner(), playedHands(score))
+	}
+	return nil
+}
+
+// playedHands returns the number of hands of the score that were played, not thrown in.
+func playedHands(score game.Score) int {
+	played := 0
+	for _, hand := range score.Hands() {
+		if !hand.ThrownIn() {
+			played++
+		}
+	}
+	return played
+}
+
+// checkHands returns an error if a hand played does not add up: every trick is taken, and every point in the deck.
+func checkHands(score game.Score) error {
+	for i, hand := range score.Hands() {
+		if hand.ThrownIn() {
+			continue
+		}
+		tricks, points := 0, 0
+		for team := range hand.Tricks {
+			tricks += hand.Tricks[team]
+			points += hand.Points[team]
+		}
+		if want := deck.CardsPerHand; tricks != want {
+			return fmt.Errorf("hand %d took %d tricks, want %d", i+1, tricks, want)
+		}
+		// Each trick is a point, the 5 of hearts is 5 more and the 3 of spades 3 fewer.
+		if want := deck.CardsPerHand + 5 - 3; points != want {
+			return fmt.Errorf("hand %d took %d points, want %d", i+1, points, want)
+		}
+	}
+	return nil
+}
+
+// report writes a table of the records with 95% confidence intervals.
+func report(out io.Writer, records []*record) {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "strategy\tgames\tunfinished\twin rate (95% CI)\thands\tpoints/hand (95% CI)\tbids\tmade (95% CI)")
+	for _, r := range records {
+		winLow, winHigh := wilson(r.wins, r.games)
+		mean, margin := meanInterval(r.points)
+		madeLow, madeHigh := wilson(r.made, r.bids)
+		fmt.Fprintf(w, "%s\t%d\t%d\t%s [%s, %s]\t%d\t%+.2f [%+.2f, %+.2f]\t%d\t%s [%s, %s]\n",
+			r.name, r.games, r.unfinished,
+			percent(r.wins, r.games), percentOf(winLow), percentOf(winHigh),
+			len(r.points), mean, mean-margin, mean+margin,
+			r.bids, percent(r.made, r.bids), percentOf(madeLow), percentOf(madeHigh))
+	}
+	w.Flush()
+}
+
+// wilson returns the Wilson score interval of the proportion of successes in trials, within [0, 1].
+func wilson(successes, trials int) (float64, float64) {
+	if trials == 0 {
+		return 0, 1
+	}
+	n := float64(trials)
+	p := float64(successes) / n
+	center := (p + z*z/(2*n)) / (1 + z*z/n)
+	margin := z * math.Sqrt(p*(1-p)/n+z*z/(4*n*n)) / (1 + z*z/n)
+	return math.Max(0, center-margin), math.Min(1, center+margin)
+}
+
+// meanInterval returns the mean of the values and the margin of its normal confidence interval.
+func meanInterval(values []int) (float64, float64) {
+	if len(values) == 0 {
+		return 0, 0
+	}
+	n := float64(len(values))
+	var sum float64
+	for _, v := range values {
+		sum += float64(v)
+	}
+	mean := sum / n
+	if len(values) == 1 {
+		return mean, 0
+	}
+	var squares float64
+	for _, v := range values {
+		squares += (float64(v) - mean) * (float64(v) - mean)
+	}
+	return mean, z * math.Sqrt(squares/(n-1)/n)
+}
+
+func percent(part, whole int) string {
+	if whole == 0 {
+		return "-"
+	}
+	return percentOf(float64(part) / float64(whole))
+}
+
+func percentOf(p float64) string {
+	return fmt.Sprintf("%.1f%%", 100*p)
+}
@@ -0,0 +1,188 @@
+package main
+
+import (
+	"math"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/squee1945/threespot/server/pkg/deck"
+	"github.com/squee1945/threespot/server/pkg/game"
+)
+
+func TestWilson(t *testing.T) {
+	testCases := []struct {
+		name      string
+		successes int
+		trials    int
+		wantLow   float64
+		wantHigh  float64
+	}{
+		{name: "no trials", successes: 0, trials: 0, wantLow: 0, wantHigh: 1},
+		{name: "half", successes: 50, trials: 100, wantLow: 0.4038, wantHigh: 0.5962},
+		{name: "none", successes: 0, trials: 10, wantLow: 0, wantHigh: 0.2775},
+		{name: "all", successes: 10, trials: 10, wantLow: 0.7225, wantHigh: 1},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			low, high := wilson(tc.successes, tc.trials)
+
+			if math.Abs(low-tc.wantLow) > 1e-4 || math.Abs(high-tc.wantHigh) > 1e-4 {
+				t.Errorf("wilson(%d, %d)=[%.4f, %.4f] want=[%.4f, %.4f]", tc.successes, tc.trials, low, high, tc.wantLow, tc.wantHigh)
+			}
+		})
+	}
+}
+
+func TestMeanInterval(t *testing.T) {
+	testCases := []struct {
+		name       string
+		values     []int
+		wantMean   float64
+		wantMargin float64
+	}{
+		{name: "no values", values: nil, wantMean: 0, wantMargin: 0},
+		{name: "one value", values: []int{7}, wantMean: 7, wantMargin: 0},
+		{name: "the same values", values: []int{4, 4, 4}, wantMean: 4, wantMargin: 0},
+		{name: "spread values", values: []int{1, 2, 3}, wantMean: 2, wantMargin: z / math.Sqrt(3)},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mean, margin := meanInterval(tc.values)
+
+			if math.Abs(mean-tc.wantMean) > 1e-9 || math.Abs(margin-tc.wantMargin) > 1e-9 {
+				t.Errorf("meanInterval(%v)=%v, %v want=%v, %v", tc.values, mean, margin, tc.wantMean, tc.wantMargin)
+			}
+		})
+	}
+}
+
+func TestCheckHands(t *testing.T) {
+	testCases := []struct {
+		name    string
+		encoded string
+		wantErr bool
+	}{
+		{
+			name:    "played and thrown in hands",
+			encoded: "52-0|0||0|10##0|3|0|P,P,P,P|-1|||0,0|0,0|-1|-1~~1|0|1|7,P,P,P|1|7|H|0,8|0,10|1|1",
+		},
+		{
+			name:    "trick missing",
+			encoded: "52-0|9##0|3|0|P,P,P,7|3|7|H|1,6|1,8|1|1",
+			wantErr: true,
+		},
+		{
+			name:    "points missing",
+			encoded: "52-0|8##0|3|0|P,P,P,7|3|7|H|0,8|0,8|1|1",
+			wantErr: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			score, err := game.NewScoreFromEncoded(tc.encoded)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			err = checkHands(score)
+
+			if gotErr := err != nil; gotErr != tc.wantErr {
+				t.Errorf("checkHands()=%v wantErr=%t", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+// TestNewTable checks that the two games of a seed swap the partnerships and nothing else: the same dealer deals the
+// same cards to the same seats.
+func TestNewTable(t *testing.T) {
+	rules := game.NewRules()
+	records := []*record{{name: game.HeuristicHardStrategy}, {name: game.HeuristicEasyStrategy}}
+	for seed := int64(1); seed <= int64(rules.Players()); seed++ {
+		var dealers []int
+		var hands [][][]deck.Card
+		for side := 0; side < 2; side++ {
+			e, _, teamRecords, err := newTable(rules, seed, side, game.SearchBudget{}, records)
+			if err != nil {
+				t.Fatalf("seed %d, side %d: %v", seed, side, err)
+			}
+			if got, want := teamRecords[0], records[side]; got != want {
+				t.Errorf("seed %d, side %d: team 0 is %s want=%s", seed, side, got.name, want.name)
+			}
+			var dealt [][]deck.Card
+			for pos := 0; pos < rules.Players(); pos++ {
+				hand, err := e.Hand(pos)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if got, want := len(hand.Cards()), deck.CardsPerHand; got != want {
+					t.Fatalf("seed %d, side %d: %d cards dealt to %d want=%d", seed, side, got, pos, want)
+				}
+				dealt = append(dealt, hand.Cards())
+			}
+			dealers = append(dealers, e.DealerPos())
+			hands = append(hands, dealt)
+		}
+
+		if got, want := dealers[0], int(seed)%rules.Players(); got != want {
+			t.Errorf("seed %d: dealer=%d want=%d", seed, got, want)
+		}
+		if dealers[0] != dealers[1] {
+			t.Errorf("seed %d: dealers=%v want the same", seed, dealers)
+		}
+		if diff := cmp.Diff(hands[0], hands[1], cmp.Comparer(func(a, b deck.Card) bool { return a.IsSameAs(b) })); diff != "" {
+			t.Errorf("seed %d: hands mismatch (-side 0 +side 1):\n%s", seed, diff)
+		}
+	}
+}
+
+func TestPlayGame(t *testing.T) {
+	rules := game.NewRules()
+	records := []*record{{name: game.HeuristicHardStrategy}, {name: game.HeuristicEasyStrategy}}
+	for seed := int64(1); seed <= 2; seed++ {
+		for side := 0; side < 2; side++ {
+			if err := playGame(rules, seed, side, game.SearchBudget{}, 200, records); err != nil {
+				t.Fatalf("seed %d, side %d: %v", seed, side, err)
+			}
+		}
+	}
+
+	for _, r := range records {
+		if r.games != 4 {
+			t.Errorf("%s played %d games want=4", r.name, r.games)
+		}
+		if r.unfinished != 0 {
+			t.Errorf("%s left %d games unfinished", r.name, r.unfinished)
+		}
+		if r.made > r.bids {
+			t.Errorf("%s made %d of %d bids", r.name, r.made, r.bids)
+		}
+	}
+	if wins := records[0].wins + records[1].wins; wins != 4 {
+		t.Errorf("wins=%d want=4", wins)
+	}
+	// Both teams score every hand played, and each hand played has a bidder.
+	if got, want := len(records[0].points), len(records[1].points); got != want {
+		t.Errorf("hands scored=%d and %d want the same", got, want)
+	}
+	if got, want := records[0].bids+records[1].bids, len(records[0].points); got != want {
+		t.Errorf("bids=%d want one for each of the %d hands played", got, want)
+	}
+}
+
+func TestPlayGameMaxHands(t *testing.T) {
+	records := []*record{{name: game.HeuristicHardStrategy}, {name: game.HeuristicEasyStrategy}}
+
+	if err := playGame(game.NewRules(), 1, 0, game.SearchBudget{}, 1, records); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, r := range records {
+		if r.games != 1 || r.unfinished != 1 {
+			t.Errorf("%s games=%d unfinished=%d want 1 unfinished game", r.name, r.games, r.unfinished)
+		}
+		if len(r.points) != 1 {
+			t.Errorf("%s points=%v want one hand played", r.name, r.points)
+		}
+	}
+}
@@ -18,24 +18,34 @@ type Card interface {
 	Encoded() string
 	// IsSameAs returns true if the cards are the same.
 	IsSameAs(Card) bool
+	// Index returns a number unique to the card on the interval [0,NumCardIndexes), for tables by card: the Index of
+	// the suit times the nums in a suit, plus the rank of the num, lowest first.
+	Index() int
 }
 
+// NumCardIndexes is the number of card indexes: one for every num of every suit.
+const NumCardIndexes = NumSuits * len(orderedNums)
+
 type card struct {
 	encoded string
+	suit    Suit // Kept to avoid a lookup; cards are compared often during play.
+	index   int
 }
 
 var _ Card = (*card)(nil) // Ensure interface is implemented.
 
 var (
 	// ThreeOfSpades is the three of spades.
-	ThreeOfSpades = &card{"3S"}
+	ThreeOfSpades = newCard("3", Spades)
 
 	// FiveOfHearts is the five of hearts.
-	FiveOfHearts = &card{"5H"}
+	FiveOfHearts = newCard("5", Hearts)
 
 	humanFromNum = map[string]string{
 		"3": "3",
+		"4": "4",
 		"5": "5",
+		"6": "6",
 		"7": "7",
 		"8": "8",
 		"9": "9",
@@ -45,8 +55,30 @@ var (
 		"K": "King",
 		"A": "Ace",
 	}
+
+	// cards are the cards of every num and suit, by encoded form. Cards are immutable, so the same card is shared.
+	cards = allCards()
 )
 
+func allCards() map[string]*card {
+	all := map[string]*card{
+		ThreeOfSpades.encoded: ThreeOfSpades,
+		FiveOfHearts.encoded:  FiveOfHearts,
+	}
+	for num := range humanFromNum {
+		for _, suit := range []Suit{Hearts, Diamonds, Spades, Clubs} {
+			if _, present := all[num+suit.Encoded()]; !present {
+				all[num+suit.Encoded()] = newCard(num, suit)
+			}
+		}
+	}
+	return all
+}
+
+func newCard(num string, suit Suit) *card {
+	return &card{encoded: num + suit.Encoded(), suit: suit, index: suit.Index()*len(orderedNums) + strings.Index(orderedNums, num)}
+}
+
 // NewCardFromEncoded builds a card from the Encoded() form.
 func NewCardFromEncoded(encoded string) (Card, error) {
 	if len(encoded) != 2 {
@@ -64,19 +96,19 @@ func NewCard(num string, suit Suit) (Card, error) {
 	if suit == NoTrump {
 		return nil, fmt.Errorf("card cannot be no trump suit")
 	}
-	num = strings.ToUpper(num)
-	if _, present := humanFromNum[num]; !present {
+	c, present := cards[strings.ToUpper(num)+suit.Encoded()]
+	if !present {
 		return nil, fmt.Errorf("invalid num %q", num)
 	}
-	return &card{encoded: num + suit.Encoded()}, nil
+	return c, nil
 }
 
 func (c *card) Num() string {
-	return string(c.encoded[0])
+	return c.encoded[:1]
 }
 
 func (c *card) Suit() Suit {
-	return suitFromEncoded[string(c.encoded[1])]
+	return c.suit
 }
 
 func (c *card) Human() string {
@@ -87,6 +119,10 @@ func (c *card) Encoded() string {
 	return c.encoded
 }
 
+func (c *card) Index() int {
+	return c.index
+}
+
 func (c *card) IsSameAs(other Card) bool {
-	return c.Encoded() == other.Encoded()
+	return c.index == other.Index()
 }
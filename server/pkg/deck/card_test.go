@@ -26,7 +26,7 @@ func TestNewCardFromEncoded(t *testing.T) {
 		},
 		{
 			name:    "bad num",
-			encoded: "4S",
+			encoded: "2S",
 			wantErr: true,
 		},
 		{
@@ -1,60 +1,105 @@
 package deck
 
 import (
+	"fmt"
 	"math/rand"
+	"strings"
 	"time"
 )
 
 // Deck is a deck of Kaiser cards.
 type Deck interface {
-	// Shuffle shuffles the cards.
+	// Shuffle shuffles the cards using the deck's random source.
 	Shuffle()
-	// Deal returns 4 hands of 8 cards (without shuffling).
+	// Deal returns a hand of 8 cards for each player (without shuffling).
 	Deal() [][]Card
 }
 
+const (
+	// CardsPerHand is the number of cards dealt to each player, whatever the number of players.
+	CardsPerHand = 8
+	// MinPlayers is the fewest players a deck can be built for.
+	MinPlayers = 3
+	// MaxPlayers is the most players a deck can be built for.
+	MaxPlayers = 6
+
+	// orderedNums are the card nums, lowest first.
+	orderedNums = "3456789TJQKA"
+)
+
 type deck struct {
-	cards []Card
+	cards   []Card
+	players int
+	rnd     *rand.Rand
 }
 
 var _ Deck = (*deck)(nil) // Ensure interface is implemented.
 
-// NewDeck returns a full deck of Kaiser cards.
-func NewDeck() (Deck, error) {
-	d := &deck{}
-	numset := []string{"8", "9", "T", "J", "Q", "K", "A"}
-	suitset := []Suit{Hearts, Diamonds, Spades, Clubs}
-	for j := 0; j < 4; j++ {
-		for k := 0; k < 7; k++ {
-			c, err := NewCard(numset[k], suitset[j])
-			if err != nil {
-				return nil, err
-			}
-			d.cards = append(d.cards, c)
-		}
+// NewDeck returns a full deck of Kaiser cards for four players, shuffled with the random source.
+// If src is nil, a source seeded with the current time is used.
+func NewDeck(src rand.Source) (Deck, error) {
+	return NewDeckForPlayers(4, src)
+}
+
+// NewDeckForPlayers returns a full deck of Kaiser cards for the given number of players.
+// The deck uses the highest two nums per player in every suit, so each player is dealt 8 cards;
+// the lowest heart and spade are swapped for the 5 of hearts and 3 of spades if they are not already present.
+// For four players, this is 8 through Ace, the 7 of clubs and diamonds, the 5 of hearts and the 3 of spades.
+// The deck is shuffled with the random source; if src is nil, a source seeded with the current time is used.
+func NewDeckForPlayers(players int, src rand.Source) (Deck, error) {
+	if players < MinPlayers || players > MaxPlayers {
+		return nil, fmt.Errorf("players must be on the interval [%d,%d]", MinPlayers, MaxPlayers)
 	}
-	sevenOfClubs, err := NewCard("7", Clubs)
-	if err != nil {
-		return nil, err
+	if src == nil {
+		src = rand.NewSource(time.Now().UnixNano())
 	}
-	sevenOfDiamonds, err := NewCard("7", Diamonds)
-	if err != nil {
-		return nil, err
+	cards := deckCards[players]
+	return &deck{cards: append(make([]Card, 0, len(cards)), cards...), players: players, rnd: rand.New(src)}, nil
+}
+
+// deckCards are the cards of an unshuffled deck for each number of players, so a deck is copied rather than built.
+var deckCards = allDeckCards()
+
+func allDeckCards() [][]Card {
+	all := make([][]Card, MaxPlayers+1)
+	for players := MinPlayers; players <= MaxPlayers; players++ {
+		nums := orderedNums[len(orderedNums)-CardsPerHand*players/4:]
+		for _, s := range []Suit{Hearts, Diamonds, Spades, Clubs} {
+			for i, n := range nums {
+				num := string(n)
+				if i == 0 && s == Hearts && !strings.Contains(nums, FiveOfHearts.Num()) {
+					all[players] = append(all[players], FiveOfHearts)
+					continue
+				}
+				if i == 0 && s == Spades && !strings.Contains(nums, ThreeOfSpades.Num()) {
+					all[players] = append(all[players], ThreeOfSpades)
+					continue
+				}
+				c, err := NewCard(num, s)
+				if err != nil {
+					panic(err) // Every num and suit is a card.
+				}
+				all[players] = append(all[players], c)
+			}
+		}
 	}
-	d.cards = append(d.cards, ThreeOfSpades, FiveOfHearts, sevenOfClubs, sevenOfDiamonds)
-	return d, nil
+	return all
 }
 
 func (d *deck) Shuffle() {
-	rand.Seed(time.Now().UnixNano())
-	rand.Shuffle(len(d.cards), func(i, j int) { d.cards[i], d.cards[j] = d.cards[j], d.cards[i] })
-	return
+	d.rnd.Shuffle(len(d.cards), func(i, j int) { d.cards[i], d.cards[j] = d.cards[j], d.cards[i] })
 }
 
 func (d *deck) Deal() [][]Card {
-	result := make([][]Card, 4)
-	for i := 0; i < 32; i++ {
-		result[i%4] = append(result[i%4], d.cards[i])
+	// The hands share one array; each is exactly a hand long, so appending to one does not change another.
+	cards := make([]Card, len(d.cards))
+	perHand := len(d.cards) / d.players
+	result := make([][]Card, d.players)
+	for i := range result {
+		result[i] = cards[i*perHand : i*perHand : (i+1)*perHand]
+	}
+	for i := range d.cards {
+		result[i%d.players] = append(result[i%d.players], d.cards[i])
 	}
 	return result
 }
@@ -1,13 +1,15 @@
 package deck
 
 import (
+	"fmt"
+	"math/rand"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
 )
 
 func TestNewDeckHasCorrectCards(t *testing.T) {
-	nd, err := NewDeck()
+	nd, err := NewDeck(nil)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -63,7 +65,7 @@ func TestNewDeckHasCorrectCards(t *testing.T) {
 }
 
 func TestShuffle(t *testing.T) {
-	nd, err := NewDeck()
+	nd, err := NewDeck(nil)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -86,8 +88,26 @@ func TestShuffle(t *testing.T) {
 	}
 }
 
+func TestShuffleIsReproducible(t *testing.T) {
+	deal := func(seed int64) [][]Card {
+		d, err := NewDeck(rand.NewSource(seed))
+		if err != nil {
+			t.Fatal(err)
+		}
+		d.Shuffle()
+		return d.Deal()
+	}
+	byEncoded := cmp.Comparer(func(c1, c2 Card) bool { return c1.Encoded() == c2.Encoded() })
+	if diff := cmp.Diff(deal(42), deal(42), byEncoded); diff != "" {
+		t.Errorf("same seed dealt different hands (-first +second):\n%s", diff)
+	}
+	if diff := cmp.Diff(deal(42), deal(43), byEncoded); diff == "" {
+		t.Errorf("different seeds dealt the same hands")
+	}
+}
+
 func TestDeal(t *testing.T) {
-	deck, err := NewDeck()
+	deck, err := NewDeck(nil)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -122,3 +142,77 @@ func hasCard(t *testing.T, d *deck, want Card) bool {
 	}
 	return false
 }
+
+func TestNewDeckForPlayers(t *testing.T) {
+	testCases := []struct {
+		players int
+		want    []string
+		wantErr bool
+	}{
+		{
+			players: 2,
+			wantErr: true,
+		},
+		{
+			players: 3,
+			want:    []string{"5H", "TH", "AH", "9D", "AD", "3S", "TS", "AS", "9C", "AC"},
+		},
+		{
+			players: 4,
+			want:    []string{"5H", "8H", "7D", "3S", "8S", "7C", "AC"},
+		},
+		{
+			players: 5,
+			want:    []string{"5H", "6H", "5D", "3S", "6S", "5C", "AC"},
+		},
+		{
+			players: 6,
+			want:    []string{"3H", "4H", "5H", "3D", "3S", "4S", "5S", "3C", "AC"},
+		},
+		{
+			players: 7,
+			wantErr: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(fmt.Sprintf("%d players", tc.players), func(t *testing.T) {
+			nd, err := NewDeckForPlayers(tc.players, nil)
+			if tc.wantErr && err == nil {
+				t.Fatal("missing expected error")
+			}
+			if !tc.wantErr && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tc.wantErr {
+				return
+			}
+			d := nd.(*deck)
+
+			if got, want := len(d.cards), 8*tc.players; got != want {
+				t.Fatalf("incorrect number of cards, got=%d, want=%d", got, want)
+			}
+			for _, encoded := range tc.want {
+				if !hasCard(t, d, buildCard(t, encoded)) {
+					t.Errorf("card %s not found", encoded)
+				}
+			}
+			m := make(map[string]bool)
+			for _, c := range d.cards {
+				if m[c.Encoded()] {
+					t.Fatalf("card already present, %s", c)
+				}
+				m[c.Encoded()] = true
+			}
+
+			hands := d.Deal()
+			if got, want := len(hands), tc.players; got != want {
+				t.Fatalf("incorrect number of hands dealt, got=%d, want=%d", got, want)
+			}
+			for _, hand := range hands {
+				if got, want := len(hand), 8; got != want {
+					t.Errorf("incorrect number of cards dealt, got=%d, want=%d", got, want)
+				}
+			}
+		})
+	}
+}
@@ -13,25 +13,31 @@ type Suit interface {
 	Human() string
 	// IsSameAs returns true if the suits are the same.
 	IsSameAs(Suit) bool
+	// Index returns a number for the suit on the interval [0,NumSuits], NumSuits for no trump, for tables by suit.
+	Index() int
 }
 
+// NumSuits is the number of suits of cards.
+const NumSuits = 4
+
 type suit struct {
 	encoded string
+	index   int
 }
 
 var _ Suit = (*suit)(nil) // Ensure interface is implemented.
 
 var (
 	// Hearts suit.
-	Hearts Suit = &suit{encoded: "H"}
+	Hearts Suit = &suit{encoded: "H", index: 0}
 	// Diamonds suit.
-	Diamonds Suit = &suit{encoded: "D"}
+	Diamonds Suit = &suit{encoded: "D", index: 1}
 	// Spades suit.
-	Spades Suit = &suit{encoded: "S"}
+	Spades Suit = &suit{encoded: "S", index: 2}
 	// Clubs suit.
-	Clubs Suit = &suit{encoded: "C"}
+	Clubs Suit = &suit{encoded: "C", index: 3}
 	// NoTrump suit; not suitable for cards, but useful in bidding and trick-tracking.
-	NoTrump Suit = &suit{encoded: "N"}
+	NoTrump Suit = &suit{encoded: "N", index: NumSuits}
 
 	suitFromEncoded = map[string]Suit{
 		"H": Hearts,
@@ -68,5 +74,9 @@ func (s *suit) Encoded() string {
 }
 
 func (s *suit) IsSameAs(other Suit) bool {
-	return s.Encoded() == other.Encoded()
+	return s.index == other.Index()
+}
+
+func (s *suit) Index() int {
+	return s.index
 }
@@ -19,14 +19,19 @@ type Bid interface {
 	IsNoTrump() bool
 	// IsPass returns true if the bid is a pass.
 	IsPass() bool
-	// Value() returns the numeric value of the bid (e.g., "7" and "7N" return 7).
+	// IsKaiser returns true if the bid is a Kaiser bid (the bidding team must take every trick).
+	IsKaiser() bool
+	// Value() returns the numeric value of the bid (e.g., "7" and "7N" return 7). Pass and Kaiser bids have no value.
 	Value() (int, error)
 }
 
 var (
 	pass             = "P"
+	kaiser           = "K"
 	humanFromEncoded = map[string]string{
 		pass: "Pass",
+		"6":  "6",
+		"6N": "6 No Trump",
 		"7":  "7",
 		"7N": "7 No Trump",
 		"8":  "8",
@@ -39,25 +44,11 @@ var (
 		"BN": "11 No Trump",
 		"C":  "12",
 		"CN": "12 No Trump",
-		// TODO: Kaiser bid
-	}
-	bidFromEncoded = map[string]Bid{
-		pass: &bid{value: pass},
-		"7":  &bid{value: "7"},
-		"7N": &bid{value: "7N"},
-		"8":  &bid{value: "8"},
-		"8N": &bid{value: "8N"},
-		"9":  &bid{value: "9"},
-		"9N": &bid{value: "9N"},
-		"A":  &bid{value: "A"},
-		"AN": &bid{value: "AN"},
-		"B":  &bid{value: "B"},
-		"BN": &bid{value: "BN"},
-		"C":  &bid{value: "C"},
-		"CN": &bid{value: "CN"},
-		// TODO: Kaiser bid
+		"K":  "Kaiser",
 	}
 	bidValueFromEncoded = map[string]int{
+		"6":  6,
+		"6N": 6,
 		"7":  7,
 		"7N": 7,
 		"8":  8,
@@ -70,14 +61,35 @@ var (
 		"BN": 11,
 		"C":  12,
 		"CN": 12,
-		// TODO: Kaiser bid
 	}
-	orderedBids = []string{"P", "7", "7N", "8", "8N", "9", "9N", "A", "AN", "B", "BN", "C", "CN"}
+	orderedBids = []string{"P", "6", "6N", "7", "7N", "8", "8N", "9", "9N", "A", "AN", "B", "BN", "C", "CN", "K"}
 	humanValues = map[string]string{}
+
+	// rankedBids are the bids in the order of orderedBids; bids are immutable, so the same bid is shared.
+	rankedBids     = allBids()
+	bidFromEncoded = bidsByEncoded()
 )
 
 type bid struct {
 	value string
+	rank  int // The index of the bid in orderedBids.
+	num   int // The numeric value of the bid; 0 for pass and Kaiser bids.
+}
+
+func allBids() []Bid {
+	bids := make([]Bid, len(orderedBids))
+	for i, encoded := range orderedBids {
+		bids[i] = &bid{value: encoded, rank: i, num: bidValueFromEncoded[encoded]}
+	}
+	return bids
+}
+
+func bidsByEncoded() map[string]Bid {
+	bids := make(map[string]Bid, len(rankedBids))
+	for _, b := range rankedBids {
+		bids[b.Encoded()] = b
+	}
+	return bids
 }
 
 var _ Bid = (*bid)(nil) // Ensure interface is implemented.
@@ -107,50 +119,78 @@ func (b *bid) IsPass() bool {
 	return b.Encoded() == pass
 }
 
+func (b *bid) IsKaiser() bool {
+	return b.Encoded() == kaiser
+}
+
 func (b *bid) IsLessThan(other Bid) bool {
-	return bidValue(b.Encoded()) < bidValue(other.Encoded())
+	return b.rank < bidRank(other)
 }
 
 func (b *bid) IsEqualTo(other Bid) bool {
-	return bidValue(b.Encoded()) == bidValue(other.Encoded())
+	return b.rank == bidRank(other)
 }
 
 func (b *bid) Value() (int, error) {
-	v, ok := bidValueFromEncoded[b.Encoded()]
-	if !ok {
+	if b.num == 0 {
 		return 0, fmt.Errorf("unknown bid value for %q", b.Encoded())
 	}
-	return v, nil
+	return b.num, nil
+}
+
+func nextBidValues(rules Rules, bids []Bid, isDealer bool) []Bid {
+	return appendNextBidValues(make([]Bid, 0, len(rankedBids)), rules, bids, isDealer)
 }
 
-func nextBidValues(bids []Bid, isDealer bool) []Bid {
+// appendNextBidValues appends the bids of nextBidValues to dst, returning the extended slice.
+func appendNextBidValues(dst []Bid, rules Rules, bids []Bid, isDealer bool) []Bid {
+	withPass, from := nextBidRange(rules, bids, isDealer)
+	if withPass {
+		dst = append(dst, rankedBids[0])
+	}
+	minBid := rules.MinBid()
+	for _, b := range rankedBids[from:] {
+		if v := b.(*bid).num; v > 0 && v < minBid {
+			continue
+		}
+		dst = append(dst, b)
+	}
+	return dst
+}
+
+// isNextBidValue returns true if the bid is one of nextBidValues, without building them.
+func isNextBidValue(rules Rules, bids []Bid, isDealer bool, b Bid) bool {
+	withPass, from := nextBidRange(rules, bids, isDealer)
+	rank := bidRank(b)
+	if rank < 0 {
+		return false
+	}
+	if withPass && rank == 0 {
+		return true
+	}
+	v := rankedBids[rank].(*bid).num
+	return rank >= from && (v == 0 || v >= rules.MinBid())
+}
+
+// nextBidRange returns the bids that may follow the bids: those of rankedBids from the index, led by a pass if withPass.
+// Pass and Kaiser bids are always allowed; the others must reach the minimum bid from the rules.
+func nextBidRange(rules Rules, bids []Bid, isDealer bool) (withPass bool, from int) {
 	if len(bids) == 0 {
-		return valuesToBids(orderedBids)
+		return false, 0
 	}
 
 	highBid, highIndex := highestBid(bids)
 
-	var encodeds []string
-	if !isDealer {
-		encodeds = []string{pass}
-		encodeds = append(encodeds, orderedBids[highIndex+1:]...)
-	} else {
-		if highBid.IsPass() {
-			encodeds = orderedBids[1:]
-		} else {
-			encodeds = []string{pass}
-			encodeds = append(encodeds, orderedBids[highIndex:]...)
-		}
+	if !isDealer || (rules.DealerMustOverbid() && !highBid.IsPass()) {
+		return true, highIndex + 1
 	}
-	return valuesToBids(encodeds)
-}
-
-func valuesToBids(encodeds []string) []Bid {
-	var available []Bid
-	for _, encoded := range encodeds {
-		available = append(available, bidFromEncoded[encoded])
+	if highBid.IsPass() && rules.AllPass() == StickTheDealer {
+		return false, 1
+	}
+	if highBid.IsPass() {
+		return false, 0
 	}
-	return available
+	return true, highIndex
 }
 
 func highestBid(bids []Bid) (Bid, int) {
@@ -158,7 +198,7 @@ func highestBid(bids []Bid) (Bid, int) {
 	highBid := bids[0]
 
 	for _, bid := range bids {
-		index := bidValue(bid.Encoded())
+		index := bidRank(bid)
 		if index > highIndex {
 			highIndex = index
 			highBid = bid
@@ -167,6 +207,14 @@ func highestBid(bids []Bid) (Bid, int) {
 	return highBid, highIndex
 }
 
+// bidRank returns the index of the bid in orderedBids.
+func bidRank(b Bid) int {
+	if bb, ok := b.(*bid); ok {
+		return bb.rank
+	}
+	return bidValue(b.Encoded())
+}
+
 func bidValue(encoded string) int {
 	for i, b := range orderedBids {
 		if b == encoded {
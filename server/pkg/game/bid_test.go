@@ -48,6 +48,16 @@ func TestNewBidFromEncoded(t *testing.T) {
 			encoded: "7N",
 			wantVal: "7N",
 		},
+		{
+			name:    "six bid",
+			encoded: "6N",
+			wantVal: "6N",
+		},
+		{
+			name:    "kaiser bid",
+			encoded: "K",
+			wantVal: "K",
+		},
 	}
 
 	for _, tc := range testCases {
@@ -117,6 +127,16 @@ func TestBidIsLessThan(t *testing.T) {
 			b2:   "CN", // "CN" is 12 No Trump.
 			want: true,
 		},
+		{
+			b1:   "CN",
+			b2:   "K", // "K" is a Kaiser bid.
+			want: true,
+		},
+		{
+			b1:   "K",
+			b2:   "CN",
+			want: false,
+		},
 	}
 
 	for _, tc := range testCases {
@@ -134,66 +154,140 @@ func TestNextBidValues(t *testing.T) {
 	testCases := []struct {
 		highBid  string
 		isDealer bool
+		allPass  AllPassRule
+		minBid   int
+		overbid  bool
 		want     []string
 	}{
 		{
 			highBid: "",
-			want:    []string{"P", "7", "7N", "8", "8N", "9", "9N", "A", "AN", "B", "BN", "C", "CN"},
+			want:    []string{"P", "7", "7N", "8", "8N", "9", "9N", "A", "AN", "B", "BN", "C", "CN", "K"},
 		},
 		{
 			highBid: "P",
-			want:    []string{"P", "7", "7N", "8", "8N", "9", "9N", "A", "AN", "B", "BN", "C", "CN"},
+			want:    []string{"P", "7", "7N", "8", "8N", "9", "9N", "A", "AN", "B", "BN", "C", "CN", "K"},
 		},
 		{
 			highBid: "7",
-			want:    []string{"P", "7N", "8", "8N", "9", "9N", "A", "AN", "B", "BN", "C", "CN"},
+			want:    []string{"P", "7N", "8", "8N", "9", "9N", "A", "AN", "B", "BN", "C", "CN", "K"},
 		},
 		{
 			highBid: "8",
-			want:    []string{"P", "8N", "9", "9N", "A", "AN", "B", "BN", "C", "CN"},
+			want:    []string{"P", "8N", "9", "9N", "A", "AN", "B", "BN", "C", "CN", "K"},
 		},
 		{
 			highBid: "C",
-			want:    []string{"P", "CN"},
+			want:    []string{"P", "CN", "K"},
 		},
 		{
 			highBid: "CN",
+			want:    []string{"P", "K"},
+		},
+		{
+			highBid: "K",
 			want:    []string{"P"},
 		},
 		{
 			highBid:  "P",
 			isDealer: true,
-			want:     []string{"7", "7N", "8", "8N", "9", "9N", "A", "AN", "B", "BN", "C", "CN"},
+			want:     []string{"7", "7N", "8", "8N", "9", "9N", "A", "AN", "B", "BN", "C", "CN", "K"},
+		},
+		{
+			highBid:  "P",
+			isDealer: true,
+			allPass:  RedealSameDealer,
+			want:     []string{"P", "7", "7N", "8", "8N", "9", "9N", "A", "AN", "B", "BN", "C", "CN", "K"},
+		},
+		{
+			highBid:  "P",
+			isDealer: true,
+			allPass:  RedealNextDealer,
+			want:     []string{"P", "7", "7N", "8", "8N", "9", "9N", "A", "AN", "B", "BN", "C", "CN", "K"},
+		},
+		{
+			highBid:  "7",
+			isDealer: true,
+			allPass:  RedealNextDealer,
+			want:     []string{"P", "7", "7N", "8", "8N", "9", "9N", "A", "AN", "B", "BN", "C", "CN", "K"},
 		},
 		{
 			highBid:  "7",
 			isDealer: true,
-			want:     []string{"P", "7", "7N", "8", "8N", "9", "9N", "A", "AN", "B", "BN", "C", "CN"},
+			want:     []string{"P", "7", "7N", "8", "8N", "9", "9N", "A", "AN", "B", "BN", "C", "CN", "K"},
 		},
 		{
 			highBid:  "8",
 			isDealer: true,
-			want:     []string{"P", "8", "8N", "9", "9N", "A", "AN", "B", "BN", "C", "CN"},
+			want:     []string{"P", "8", "8N", "9", "9N", "A", "AN", "B", "BN", "C", "CN", "K"},
 		},
 		{
 			highBid:  "C",
 			isDealer: true,
-			want:     []string{"P", "C", "CN"},
+			want:     []string{"P", "C", "CN", "K"},
 		},
 		{
 			highBid:  "CN",
 			isDealer: true,
-			want:     []string{"P", "CN"},
+			want:     []string{"P", "CN", "K"},
+		},
+		{
+			highBid:  "K",
+			isDealer: true,
+			want:     []string{"P", "K"},
+		},
+		{
+			highBid: "",
+			minBid:  6,
+			want:    []string{"P", "6", "6N", "7", "7N", "8", "8N", "9", "9N", "A", "AN", "B", "BN", "C", "CN", "K"},
+		},
+		{
+			highBid: "P",
+			minBid:  8,
+			want:    []string{"P", "8", "8N", "9", "9N", "A", "AN", "B", "BN", "C", "CN", "K"},
+		},
+		{
+			highBid:  "P",
+			isDealer: true,
+			minBid:   8,
+			want:     []string{"8", "8N", "9", "9N", "A", "AN", "B", "BN", "C", "CN", "K"},
+		},
+		{
+			highBid:  "6",
+			isDealer: true,
+			minBid:   6,
+			want:     []string{"P", "6", "6N", "7", "7N", "8", "8N", "9", "9N", "A", "AN", "B", "BN", "C", "CN", "K"},
+		},
+		{
+			highBid:  "8",
+			isDealer: true,
+			overbid:  true,
+			want:     []string{"P", "8N", "9", "9N", "A", "AN", "B", "BN", "C", "CN", "K"},
+		},
+		{
+			highBid:  "K",
+			isDealer: true,
+			overbid:  true,
+			want:     []string{"P"},
+		},
+		{
+			highBid:  "P",
+			isDealer: true,
+			overbid:  true,
+			want:     []string{"7", "7N", "8", "8N", "9", "9N", "A", "AN", "B", "BN", "C", "CN", "K"},
 		},
 	}
 
 	for _, tc := range testCases {
-		t.Run(fmt.Sprintf("high=%s isDealer=%t", tc.highBid, tc.isDealer), func(t *testing.T) {
+		t.Run(fmt.Sprintf("high=%s isDealer=%t allPass=%s minBid=%d overbid=%t", tc.highBid, tc.isDealer, tc.allPass, tc.minBid, tc.overbid), func(t *testing.T) {
 			var priorBids []Bid
 			if tc.highBid != "" {
 				priorBids = []Bid{buildBid(t, tc.highBid)}
 			}
-			bids := nextBidValues(priorBids, tc.isDealer)
+			rules := NewRules()
+			rules.SetAllPass(tc.allPass)
+			rules.SetMinBid(tc.minBid)
+			rules.SetDealerMustOverbid(tc.overbid)
+			bids := nextBidValues(rules, priorBids, tc.isDealer)
 
 			var got []string
 			for _, b := range bids {
@@ -203,10 +297,32 @@ func TestNextBidValues(t *testing.T) {
 			if diff := cmp.Diff(tc.want, got, compareBids); diff != "" {
 				t.Errorf("nextBidValues() mismatch (-want +got):\n%s", diff)
 			}
+			// isNextBidValue agrees with nextBidValues on every bid.
+			for _, b := range rankedBids {
+				want := false
+				for _, next := range bids {
+					want = want || next.IsEqualTo(b)
+				}
+				if got := isNextBidValue(rules, priorBids, tc.isDealer, b); got != want {
+					t.Errorf("isNextBidValue(%s)=%t want=%t", b.Encoded(), got, want)
+				}
+			}
 		})
 	}
 }
 
+func TestBidIsKaiser(t *testing.T) {
+	for _, encoded := range orderedBids {
+		bid := buildBid(t, encoded)
+		if got, want := bid.IsKaiser(), encoded == "K"; got != want {
+			t.Errorf("bid %q IsKaiser()=%t want=%t", encoded, got, want)
+		}
+		if bid.IsKaiser() && bid.IsNoTrump() {
+			t.Errorf("bid %q must not be no trump", encoded)
+		}
+	}
+}
+
 func buildBid(t *testing.T, val string) Bid {
 	t.Helper()
 	bid, err := NewBidFromEncoded(val)
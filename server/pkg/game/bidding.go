@@ -1,15 +1,16 @@
 package game
 
 import (
-	"errors"
 	"fmt"
 	"strconv"
 	"strings"
+
+	"github.com/squee1945/threespot/server/pkg/deck"
 )
 
 // BiddingRound round is a collection of bids.
 type BiddingRound interface {
-	// IsDone returns true if the bidding is complete (4 bids).
+	// IsDone returns true if the bidding is complete (one bid from each player).
 	IsDone() bool
 
 	// placeBid makes a bid for the player in playerPos position. Bid validation must be performed before calling this method.
@@ -21,6 +22,9 @@ type BiddingRound interface {
 	// WinningBidAndPos returns the bid and position of the player that won the bidding. Returns error if !IsDone().
 	WinningBidAndPos() (Bid, int, error)
 
+	// AllPassed returns true if the bidding is complete and every player passed.
+	AllPassed() bool
+
 	// LeadPos returns the position of the lead player.
 	LeadPos() int
 
@@ -32,13 +36,23 @@ type BiddingRound interface {
 
 	// Encoded returns the bids encoded into a single string.
 	Encoded() string
+
+	// clone returns a copy of the bidding round that can be changed independently.
+	clone() BiddingRound
+
+	// stored returns the structured form of the bidding round, for storage.
+	stored() storedBidding
 }
 
 type biddingRound struct {
-	// leadPos is the position (0..3) of the leadoff bidder.
+	// leadPos is the position (0..players-1) of the leadoff bidder.
 	leadPos int
+	// players is the number of players, and the number of bids in a complete round.
+	players int
 	// bids are the bids placed. bids[0] is the bid placed by the player in leadPos.
 	bids []Bid
+	// buf holds the bids placed, so placing a bid does not allocate.
+	buf [deck.MaxPlayers]Bid
 }
 
 var _ BiddingRound = (*biddingRound)(nil) // Ensure interface is implemented.
@@ -46,6 +60,7 @@ var _ BiddingRound = (*biddingRound)(nil) // Ensure interface is implemented.
 // NewBiddingRoundFromEncoded returns a set of bigs from the Encoded() form.
 func NewBiddingRoundFromEncoded(encoded string) (BiddingRound, error) {
 	// "{leadPos}|{bid0}|{bid1}|{bid2}|{bid3}"
+	// If there are not four players, the leadPos is followed by the number of players: "{leadPos}/{players}|{bid0}|..."
 	if encoded == "" {
 		encoded = "0|"
 	}
@@ -53,16 +68,28 @@ func NewBiddingRoundFromEncoded(encoded string) (BiddingRound, error) {
 	if len(parts) < 1 {
 		return nil, fmt.Errorf("encoded %q has too few parts", encoded)
 	}
-	if len(parts) > 5 {
-		return nil, fmt.Errorf("encoded %q has too many parts", encoded)
-	}
 
-	leadPos, err := strconv.Atoi(parts[0])
+	header := strings.Split(parts[0], "/")
+	if len(header) > 2 {
+		return nil, fmt.Errorf("encoded part[0] %q must have one or two parts", parts[0])
+	}
+	leadPos, err := strconv.Atoi(header[0])
 	if err != nil {
 		return nil, fmt.Errorf("encoded part[0] %q was not an int: %v", parts[0], err)
 	}
+	players := defaultPlayers
+	if len(header) == 2 {
+		players, err = strconv.Atoi(header[1])
+		if err != nil {
+			return nil, fmt.Errorf("encoded part[0] %q was not an int: %v", parts[0], err)
+		}
+	}
+
+	if len(parts) > players+1 {
+		return nil, fmt.Errorf("encoded %q has too many parts", encoded)
+	}
 
-	brr, err := NewBiddingRound(leadPos)
+	brr, err := newBiddingRoundForPlayers(leadPos, players)
 	if err != nil {
 		return nil, err
 	}
@@ -82,16 +109,51 @@ func NewBiddingRoundFromEncoded(encoded string) (BiddingRound, error) {
 	return br, nil
 }
 
-// NewBiddingRound creates a new bidding round starting with the player in leadPos.
+// storedBidding is the structured form of a bidding round, kept in storage.
+type storedBidding struct {
+	LeadPos int
+	Players int
+	Bids    []string // The bids placed, starting with the bid of the player in LeadPos.
+}
+
+// newBiddingRoundFromStored builds a bidding round from the stored() form.
+func newBiddingRoundFromStored(st storedBidding) (BiddingRound, error) {
+	brr, err := newBiddingRoundForPlayers(st.LeadPos, st.Players)
+	if err != nil {
+		return nil, err
+	}
+	if len(st.Bids) > st.Players {
+		return nil, fmt.Errorf("too many bids in %v", st.Bids)
+	}
+	br := brr.(*biddingRound)
+	for _, encoded := range st.Bids {
+		bid, err := NewBidFromEncoded(encoded)
+		if err != nil {
+			return nil, err
+		}
+		br.bids = append(br.bids, bid)
+	}
+	return br, nil
+}
+
+// NewBiddingRound creates a new bidding round for four players starting with the player in leadPos.
 func NewBiddingRound(leadPos int) (BiddingRound, error) {
-	if leadPos < 0 || leadPos > 3 {
-		return nil, errors.New("leadPos must be on the interval [0,3]")
+	return newBiddingRoundForPlayers(leadPos, defaultPlayers)
+}
+
+// newBiddingRoundForPlayers creates a new bidding round for the given number of players starting with the player in leadPos.
+func newBiddingRoundForPlayers(leadPos, players int) (BiddingRound, error) {
+	if players < deck.MinPlayers || players > deck.MaxPlayers {
+		return nil, fmt.Errorf("players must be on the interval [%d,%d]", deck.MinPlayers, deck.MaxPlayers)
 	}
-	return &biddingRound{leadPos: leadPos}, nil
+	if leadPos < 0 || leadPos >= players {
+		return nil, fmt.Errorf("leadPos must be on the interval [0,%d]", players-1)
+	}
+	return &biddingRound{leadPos: leadPos, players: players}, nil
 }
 
 func (r *biddingRound) IsDone() bool {
-	return r.NumPlaced() == 4
+	return r.NumPlaced() == r.players
 }
 
 func (r *biddingRound) placeBid(playerPos int, bid Bid) error {
@@ -99,15 +161,26 @@ func (r *biddingRound) placeBid(playerPos int, bid Bid) error {
 	if len(r.bids) != ord {
 		return ErrIncorrectBidOrder
 	}
+	if r.bids == nil {
+		r.bids = r.buf[:0:r.players]
+	}
 	r.bids = append(r.bids, bid)
 	return nil
 }
 
+func (r *biddingRound) clone() BiddingRound {
+	c := *r
+	if r.bids != nil {
+		c.bids = append(c.buf[:0:r.players], r.bids...)
+	}
+	return &c
+}
+
 func (r *biddingRound) CurrentTurnPos() (int, error) {
 	if r.IsDone() {
 		return -1, fmt.Errorf("bidding is complete")
 	}
-	return (r.leadPos + len(r.bids)) % 4, nil
+	return (r.leadPos + len(r.bids)) % r.players, nil
 }
 
 func (r *biddingRound) WinningBidAndPos() (Bid, int, error) {
@@ -115,7 +188,7 @@ func (r *biddingRound) WinningBidAndPos() (Bid, int, error) {
 	if !r.IsDone() {
 		return nil, 0, fmt.Errorf("bidding is not done")
 	}
-	for i := 3; i >= 0; i-- {
+	for i := len(r.bids) - 1; i >= 0; i-- {
 		if r.bids[i].IsPass() {
 			continue
 		}
@@ -124,6 +197,18 @@ func (r *biddingRound) WinningBidAndPos() (Bid, int, error) {
 	return nil, 0, fmt.Errorf("no non-pass bids found")
 }
 
+func (r *biddingRound) AllPassed() bool {
+	if !r.IsDone() {
+		return false
+	}
+	for _, bid := range r.bids {
+		if !bid.IsPass() {
+			return false
+		}
+	}
+	return true
+}
+
 func (r *biddingRound) LeadPos() int {
 	return r.leadPos
 }
@@ -136,20 +221,32 @@ func (r *biddingRound) NumPlaced() int {
 	return len(r.bids)
 }
 
+func (r *biddingRound) stored() storedBidding {
+	st := storedBidding{LeadPos: r.leadPos, Players: r.players}
+	for _, bid := range r.bids {
+		st.Bids = append(st.Bids, bid.Encoded())
+	}
+	return st
+}
+
 func (r *biddingRound) Encoded() string {
 	var bes []string
 	for _, bid := range r.bids {
 		bes = append(bes, bid.Encoded())
 	}
-	return strconv.Itoa(r.leadPos) + "|" + strings.Join(bes, "|")
+	header := strconv.Itoa(r.leadPos)
+	if r.players != defaultPlayers {
+		header = fmt.Sprintf("%d/%d", r.leadPos, r.players)
+	}
+	return header + "|" + strings.Join(bes, "|")
 }
 
-// toOrd returns the player order for this bid (0..3), computed from the leadPos.
+// toOrd returns the player order for this bid (0..players-1), computed from the leadPos.
 func (r *biddingRound) toOrd(playerPos int) int {
-	return (playerPos + 4 - r.leadPos) % 4
+	return (playerPos + r.players - r.leadPos) % r.players
 }
 
-// toPos returns the player position for this bid (0..3), computed from the leadPos.
+// toPos returns the player position for this bid (0..players-1), computed from the leadPos.
 func (r *biddingRound) toPos(playerOrd int) int {
-	return (r.leadPos + playerOrd) % 4
+	return (r.leadPos + playerOrd) % r.players
 }
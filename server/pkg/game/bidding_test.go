@@ -62,6 +62,27 @@ func TestNewBiddingRoundFromEncoded(t *testing.T) {
 			encoded: "0|7N|8|8N|8N|9",
 			wantErr: true,
 		},
+		{
+			name:        "six players",
+			encoded:     "5/6|7N|8|8N|8N|9|P",
+			wantLeadPos: 5,
+			wantBids:    []Bid{buildBid(t, "7N"), buildBid(t, "8"), buildBid(t, "8N"), buildBid(t, "8N"), buildBid(t, "9"), buildBid(t, "P")},
+		},
+		{
+			name:    "too many bids for three players",
+			encoded: "0/3|7N|8|8N|9",
+			wantErr: true,
+		},
+		{
+			name:    "lead pos too big for three players",
+			encoded: "3/3|7",
+			wantErr: true,
+		},
+		{
+			name:    "too many players",
+			encoded: "0/7|7",
+			wantErr: true,
+		},
 	}
 
 	for _, tc := range testCases {
@@ -326,6 +347,29 @@ func TestBiddingRoundWinningBidAndPos(t *testing.T) {
 	}
 }
 
+func TestBiddingRoundAllPassed(t *testing.T) {
+	testCases := []struct {
+		encoded string
+		want    bool
+	}{
+		{"0|", false},
+		{"0|P|P|P", false},
+		{"0|P|P|P|7", false},
+		{"2|7|P|P|P", false},
+		{"1|P|P|P|P", true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.encoded, func(t *testing.T) {
+			br := buildBiddingRound(t, tc.encoded)
+
+			if got, want := br.AllPassed(), tc.want; got != want {
+				t.Errorf("AllPassed()=%t want=%t", got, want)
+			}
+		})
+	}
+}
+
 func buildBiddingRound(t *testing.T, encoded string) BiddingRound {
 	br, err := NewBiddingRoundFromEncoded(encoded)
 	if err != nil {
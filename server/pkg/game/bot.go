@@ -0,0 +1,102 @@
+package game
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// maxBotActions is how many actions PlayBots takes at most, about a hand's worth; the rest are left for the next call.
+const maxBotActions = 48
+
+// maxBotTime is how long PlayBots takes bot actions for, so a request with slow bots still returns promptly; the rest
+// are left for the next call.
+const maxBotTime = 2 * time.Second
+
+// PlayBots takes the turns of the bot players until it is a person's turn, or the game is over. Bots approve undo
+// requests and seat swaps asked of them.
+func (g *game) PlayBots(ctx context.Context) (Game, error) {
+	var cur Game = g
+	start := time.Now()
+	for i := 0; i < maxBotActions && time.Since(start) < maxBotTime; i++ {
+		next, err := cur.(*game).playBot(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if next == nil {
+			break
+		}
+		cur = next
+	}
+	return cur, nil
+}
+
+// playBot takes one bot action, returning nil if there is none to take.
+func (g *game) playBot(ctx context.Context) (Game, error) {
+	if swap := g.seatSwap; swap != nil {
+		if bot := g.players[swap.ToPos]; bot != nil && bot.Strategy() != "" {
+			return g.RespondToSeatSwap(ctx, bot, true)
+		}
+		return nil, nil
+	}
+	if g.State() == JoiningState || g.State() == CompletedState {
+		return nil, nil
+	}
+	if undo := g.undoRequest; undo != nil {
+		for pos, bot := range g.players {
+			if pos == undo.Pos || bot == nil || bot.Strategy() == "" || hasApproved(undo, pos) {
+				continue
+			}
+			return g.RespondToUndo(ctx, bot, true)
+		}
+		return nil, nil
+	}
+
+	pos, err := g.PosToPlay()
+	if err != nil {
+		return nil, err
+	}
+	bot := g.players[pos]
+	if bot == nil || bot.Strategy() == "" {
+		return nil, nil
+	}
+	strategy, err := NewStrategy(bot.Strategy())
+	if err != nil {
+		return nil, fmt.Errorf("bot %q: %v", bot.ID(), err)
+	}
+	view, err := g.Engine.SeatView(pos)
+	if err != nil {
+		return nil, err
+	}
+	m, err := ChooseMove(strategy, view)
+	if err != nil {
+		return nil, fmt.Errorf("bot %q choosing %s: %v", bot.ID(), view.State, err)
+	}
+	return g.applyMove(ctx, bot, m)
+}
+
+// applyMove takes the move for the player through the Game's actions, so that it is recorded and saved.
+func (g *game) applyMove(ctx context.Context, player Player, m Move) (Game, error) {
+	switch m.Action {
+	case DealAction:
+		return g.DealCards(ctx, player)
+	case PassAction:
+		return g.PassCard(ctx, player, m.Card)
+	case BidAction:
+		return g.PlaceBid(ctx, player, m.Bid)
+	case TrumpAction:
+		return g.CallTrump(ctx, player, m.Trump)
+	case PlayAction:
+		return g.PlayCard(ctx, player, m.Card)
+	}
+	return nil, ErrNoMove
+}
+
+func hasApproved(undo *UndoRequest, pos int) bool {
+	for _, approved := range undo.Approvals {
+		if approved == pos {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,84 @@
+package game
+
+import (
+	"math/bits"
+
+	"github.com/squee1945/threespot/server/pkg/deck"
+)
+
+// cardSet is a set of cards, one bit per card, so the engine can check hands without looping over cards.
+// The bits are in hand order: by suit (hearts, spades, diamonds, clubs), highest card first.
+type cardSet uint64
+
+// numsPerSuit is the number of card nums in each suit: a card's deck.Card Index is its suit's Index times numsPerSuit,
+// plus the rank of its num, lowest first.
+const numsPerSuit = deck.NumCardIndexes / deck.NumSuits
+
+var (
+	// setSuits are the suits in hand order; each takes len(orderedCards) bits of a card set.
+	setSuits = []deck.Suit{deck.Hearts, deck.Spades, deck.Diamonds, deck.Clubs}
+
+	// setCards are the cards at each bit of a card set.
+	setCards = allSetCards()
+
+	// cardBits are the set of just each card, by deck.Card.Index.
+	cardBits = allCardBits()
+
+	// suitSets are the set of every card of each suit, by deck.Suit.Index; the set for no trump is empty.
+	suitSets = allSuitSets()
+)
+
+func allSetCards() []deck.Card {
+	cards := make([]deck.Card, len(setSuits)*len(orderedCards))
+	for s, suit := range setSuits {
+		for i := range orderedCards {
+			card, err := deck.NewCard(orderedCards[i:i+1], suit)
+			if err != nil {
+				panic(err) // Every num and suit is a card.
+			}
+			cards[s*len(orderedCards)+len(orderedCards)-1-i] = card
+		}
+	}
+	return cards
+}
+
+func allCardBits() []cardSet {
+	bits := make([]cardSet, deck.NumCardIndexes)
+	for i, card := range setCards {
+		bits[card.Index()] = 1 << uint(i)
+	}
+	return bits
+}
+
+func allSuitSets() []cardSet {
+	sets := make([]cardSet, deck.NumSuits+1)
+	for i, suit := range setSuits {
+		sets[suit.Index()] = (1<<uint(len(orderedCards)) - 1) << uint(i*len(orderedCards))
+	}
+	return sets
+}
+
+// cardBit returns the set of just the card.
+func cardBit(card deck.Card) cardSet {
+	return cardBits[card.Index()]
+}
+
+// suitSet returns the set of every card of the suit; it is empty for no trump.
+func suitSet(suit deck.Suit) cardSet {
+	return suitSets[suit.Index()]
+}
+
+// appendCards appends the cards of the set to cards in hand order.
+func (s cardSet) appendCards(cards []deck.Card) []deck.Card {
+	for s != 0 {
+		i := bits.TrailingZeros64(uint64(s))
+		cards = append(cards, setCards[i])
+		s &^= 1 << uint(i)
+	}
+	return cards
+}
+
+// count returns the number of cards in the set.
+func (s cardSet) count() int {
+	return bits.OnesCount64(uint64(s))
+}
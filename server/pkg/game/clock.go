@@ -0,0 +1,60 @@
+package game
+
+import (
+	"context"
+	"time"
+)
+
+func (g *game) TurnDeadline() time.Time {
+	return g.turnDeadline
+}
+
+// startTurnClock restarts the turn deadline from now if the pending actions moved play on, or answered an undo request.
+// The clock is held while an undo request is pending. There is no deadline without a turn limit, or while the game is not being played.
+func (g *game) startTurnClock(now time.Time) {
+	restart := false
+	for _, e := range g.pending {
+		if e.Action != UndoRequestAction && e.Action != UndoApproveAction {
+			restart = true
+		}
+	}
+	if !restart {
+		return
+	}
+	state := g.State()
+	if g.rules.TurnLimit() == 0 || state == JoiningState || state == CompletedState {
+		g.turnDeadline = time.Time{}
+		return
+	}
+	g.turnDeadline = now.Add(g.rules.TurnLimit())
+}
+
+func (g *game) EnforceTurnClock(ctx context.Context, now time.Time) (Game, error) {
+	if g.turnDeadline.IsZero() || now.Before(g.turnDeadline) {
+		return g, nil
+	}
+	// Play is held up until the pending undo request is answered.
+	if g.undoRequest != nil {
+		return g, nil
+	}
+	pos, err := g.PosToPlay()
+	if err != nil {
+		return nil, err
+	}
+	if pos < 0 || g.players[pos] == nil {
+		return g, nil
+	}
+	view, err := g.Engine.SeatView(pos)
+	if err != nil {
+		return nil, err
+	}
+	m, err := ChooseMove(simpleStrategy{}, view)
+	if err == ErrNoMove {
+		return g, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	g.record(TimeoutAction, g.players[pos], pos, "")
+	return g.applyMove(ctx, g.players[pos], m)
+}
@@ -0,0 +1,261 @@
+package game
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/squee1945/threespot/server/pkg/storage"
+)
+
+func TestTurnDeadline(t *testing.T) {
+	ctx := context.Background()
+	gameStore := storage.NewFakeGameStore(nil)
+	playerStore := storage.NewFakePlayerStore()
+	var ps []Player
+	for i := 0; i < 4; i++ {
+		ps = append(ps, buildPlayer(t, playerStore, fmt.Sprintf("PLAYER%d", i)))
+	}
+	rules := NewRules()
+	rules.SetTurnLimit(30 * time.Second)
+	g, err := NewGame(ctx, gameStore, playerStore, "ABC123", ps[0], rules)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for pos := 1; pos < 4; pos++ {
+		if g, err = g.AddPlayer(ctx, ps[pos], pos); err != nil {
+			t.Fatal(err)
+		}
+		if pos < 3 && !g.TurnDeadline().IsZero() {
+			t.Errorf("TurnDeadline()=%v while joining want=zero", g.TurnDeadline())
+		}
+	}
+
+	// The clock starts once the hand is dealt, and restarts with each action.
+	wantDeadline := func(g Game) {
+		t.Helper()
+		if got, want := g.TurnDeadline(), g.(*game).updated.Add(30*time.Second); !got.Equal(want) {
+			t.Errorf("TurnDeadline()=%v want=%v", got, want)
+		}
+	}
+	wantDeadline(g)
+	g = playNextAction(t, ctx, g, ps)
+	wantDeadline(g)
+	deadline := g.TurnDeadline()
+
+	// The clock is held while an undo is requested, then restarts once it is answered.
+	pos, err := g.PosToPlay()
+	if err != nil {
+		t.Fatal(err)
+	}
+	lastPos := (pos + 3) % 4
+	if g, err = g.RequestUndo(ctx, ps[lastPos]); err != nil {
+		t.Fatal(err)
+	}
+	if !g.TurnDeadline().Equal(deadline) {
+		t.Errorf("TurnDeadline()=%v after undo request want=%v", g.TurnDeadline(), deadline)
+	}
+	if g, err = g.RespondToUndo(ctx, ps[pos], false); err != nil {
+		t.Fatal(err)
+	}
+	wantDeadline(g)
+
+	// The deadline is stored with the game.
+	got, err := GetGame(ctx, gameStore, playerStore, g.ID())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !got.TurnDeadline().Equal(g.TurnDeadline()) {
+		t.Errorf("stored TurnDeadline()=%v want=%v", got.TurnDeadline(), g.TurnDeadline())
+	}
+
+	// Once the deadline passes, the player to play passes the bid; replaying the log gives the same game.
+	if g, err = g.EnforceTurnClock(ctx, g.TurnDeadline()); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := g.PosToPlay(); err != nil || got != (pos+1)%4 {
+		t.Errorf("PosToPlay()=%d, %v want=%d", got, err, (pos+1)%4)
+	}
+	wantDeadline(g)
+	replayed, err := ReplayGame(ctx, gameStore, playerStore, g.ID(), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ignore := cmpopts.IgnoreFields(storage.Game{}, "Created", "Updated", "TurnDeadline")
+	if diff := cmp.Diff(legacyStorage(t, g.(*game)), legacyStorage(t, replayed.(*game)), ignore); diff != "" {
+		t.Errorf("replayed game mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestTurnDeadlineWithoutLimit(t *testing.T) {
+	g, ps := startUndoGame(t, context.Background())
+
+	g = playNextAction(t, context.Background(), g, ps)
+
+	if !g.TurnDeadline().IsZero() {
+		t.Errorf("TurnDeadline()=%v want=zero", g.TurnDeadline())
+	}
+}
+
+func TestEnforceTurnClock(t *testing.T) {
+	pids := []string{"ABE", "BOB", "CAL", "DON"}
+	now := time.Date(2020, 3, 1, 12, 0, 0, 0, time.UTC)
+	passed := now.Add(-time.Second)
+	timedRules := storage.Rules{TurnSeconds: 30}
+	testCases := []struct {
+		name string
+		gs   *storage.Game
+		want []Event // The events recorded, other than the cards dealt; ignoring Seq and Created.
+	}{
+		{
+			name: "no limit",
+			gs: &storage.Game{
+				PlayerIDs:      pids,
+				CurrentBidding: "0|8",
+				CurrentHands:   "AH+AS+AD+AC",
+			},
+		},
+		{
+			name: "deadline not passed",
+			gs: &storage.Game{
+				PlayerIDs:      pids,
+				TurnDeadline:   now.Add(time.Second),
+				CurrentBidding: "0|8",
+				CurrentHands:   "AH+AS+AD+AC",
+				Rules:          timedRules,
+			},
+		},
+		{
+			name: "undo pending",
+			gs: &storage.Game{
+				PlayerIDs:      pids,
+				TurnDeadline:   passed,
+				CurrentBidding: "0|8",
+				CurrentHands:   "AH+AS+AD+AC",
+				UndoRequest:    "0|6|",
+				Rules:          timedRules,
+			},
+		},
+		{
+			name: "deal",
+			gs: &storage.Game{
+				PlayerIDs:        pids,
+				TurnDeadline:     passed,
+				Score:            "52-10|0",
+				CurrentDealerPos: 1,
+				Rules:            timedRules,
+			},
+			want: []Event{
+				{Action: TimeoutAction, PlayerID: "BOB", Pos: 1},
+				{Action: DealAction, PlayerID: "BOB", Pos: 1},
+			},
+		},
+		{
+			name: "pass the bid",
+			gs: &storage.Game{
+				PlayerIDs:      pids,
+				TurnDeadline:   passed,
+				CurrentBidding: "0|8",
+				CurrentHands:   "AH+AS+AD+AC",
+				Rules:          timedRules,
+			},
+			want: []Event{
+				{Action: TimeoutAction, PlayerID: "BOB", Pos: 1},
+				{Action: BidAction, PlayerID: "BOB", Pos: 1, Payload: "P"},
+			},
+		},
+		{
+			name: "call longest suit",
+			gs: &storage.Game{
+				PlayerIDs:      pids,
+				TurnDeadline:   passed,
+				CurrentBidding: "0|P|P|P|7",
+				CurrentHands:   "AH+AS+AD+KD|7C|8C",
+				Rules:          timedRules,
+			},
+			want: []Event{
+				{Action: TimeoutAction, PlayerID: "DON", Pos: 3},
+				{Action: TrumpAction, PlayerID: "DON", Pos: 3, Payload: "C"},
+			},
+		},
+		{
+			name: "call first of tied suits",
+			gs: &storage.Game{
+				PlayerIDs:      pids,
+				TurnDeadline:   passed,
+				CurrentBidding: "0|P|P|P|7",
+				CurrentHands:   "AH+AS+AD+7C|AS",
+				Rules:          timedRules,
+			},
+			want: []Event{
+				{Action: TimeoutAction, PlayerID: "DON", Pos: 3},
+				{Action: TrumpAction, PlayerID: "DON", Pos: 3, Payload: "S"},
+			},
+		},
+		{
+			name: "play lowest legal card",
+			gs: &storage.Game{
+				PlayerIDs:      pids,
+				TurnDeadline:   passed,
+				CurrentHands:   "AH|KH|7D+AS|KS+AC|KC+AD|KD",
+				CurrentBidding: "0|P|P|P|7",
+				CurrentTrick:   "3|H|9H",
+				Rules:          timedRules,
+			},
+			want: []Event{
+				{Action: TimeoutAction, PlayerID: "ABE", Pos: 0},
+				{Action: PlayAction, PlayerID: "ABE", Pos: 0, Payload: "KH"},
+			},
+		},
+		{
+			name: "play lowest card",
+			gs: &storage.Game{
+				PlayerIDs:      pids,
+				TurnDeadline:   passed,
+				CurrentHands:   "AH|KH|7D+AS|KS+AC|KC+AD|KD",
+				CurrentBidding: "0|P|P|P|7",
+				CurrentTrick:   "3|H|9C",
+				Rules:          timedRules,
+			},
+			want: []Event{
+				{Action: TimeoutAction, PlayerID: "ABE", Pos: 0},
+				{Action: PlayAction, PlayerID: "ABE", Pos: 0, Payload: "7D"},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			g, gameStore, _ := buildGame(t, tc.gs)
+
+			newG, err := g.EnforceTurnClock(ctx, now)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			events, err := GetEvents(ctx, gameStore, g.ID())
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []Event
+			for _, e := range events {
+				if e.Action != DealtAction {
+					got = append(got, e)
+				}
+			}
+			if diff := cmp.Diff(tc.want, got, cmpopts.IgnoreFields(Event{}, "Seq", "Created"), cmpopts.EquateEmpty()); diff != "" {
+				t.Errorf("events mismatch (-want +got):\n%s", diff)
+			}
+			if len(tc.want) == 0 && newG.Version() != g.Version() {
+				t.Errorf("Version()=%s want=%s", newG.Version(), g.Version())
+			}
+			if len(tc.want) > 0 && !newG.TurnDeadline().After(now) {
+				t.Errorf("TurnDeadline()=%v want after %v", newG.TurnDeadline(), now)
+			}
+		})
+	}
+}
@@ -0,0 +1,106 @@
+package game
+
+import (
+	"fmt"
+	"math/rand"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/squee1945/threespot/server/pkg/deck"
+)
+
+// Deal versions are how DealHands shuffles the deck from a seed. Hands are recorded with the version they were dealt
+// with, so a recorded seed deals the same hand again after the current version changes.
+const (
+	// DealMathRand shuffles with a math/rand source seeded with the seed. Hands recorded without a version were dealt
+	// this way.
+	DealMathRand = 0
+	// DealSplitMix shuffles with a SplitMix64 source; seeding a math/rand source for every hand takes longer than
+	// playing the hand.
+	DealSplitMix = 1
+
+	// CurrentDealVersion is the version new hands are dealt with.
+	CurrentDealVersion = DealSplitMix
+)
+
+// DealHands deals a hand to each player from a deck shuffled with the seed, as the deal version does.
+// Dealing again with the same seed, version and number of players reproduces the hands exactly.
+func DealHands(players int, seed int64, version int) (Hands, error) {
+	var src rand.Source
+	switch version {
+	case DealMathRand:
+		src = rand.NewSource(seed)
+	case DealSplitMix:
+		src = &splitMixSource{state: uint64(seed)}
+	default:
+		return nil, fmt.Errorf("unknown deal version %d", version)
+	}
+	d, err := deck.NewDeckForPlayers(players, src)
+	if err != nil {
+		return nil, err
+	}
+	d.Shuffle()
+	return NewHands(d.Deal())
+}
+
+// splitMixSource is the SplitMix64 random source of DealSplitMix.
+type splitMixSource struct {
+	state uint64
+}
+
+var _ rand.Source64 = (*splitMixSource)(nil) // Ensure interface is implemented.
+
+func (s *splitMixSource) Seed(seed int64) {
+	s.state = uint64(seed)
+}
+
+func (s *splitMixSource) Uint64() uint64 {
+	s.state += 0x9e3779b97f4a7c15
+	z := s.state
+	z = (z ^ z>>30) * 0xbf58476d1ce4e5b9
+	z = (z ^ z>>27) * 0x94d049bb133111eb
+	return z ^ z>>31
+}
+
+func (s *splitMixSource) Int63() int64 {
+	return int64(s.Uint64() >> 1)
+}
+
+// nextSeed returns the seed to shuffle the next hand with, from the engine's seed source if it has one.
+func (e *Engine) nextSeed() int64 {
+	if e.seeds != nil {
+		return e.seeds.Int63()
+	}
+	return time.Now().UnixNano()
+}
+
+// encodeDealt encodes the payload of a DealtAction event: "hands/seed/version".
+func encodeDealt(hands Hands, seed int64, version int) string {
+	return hands.Encoded() + "/" + strconv.FormatInt(seed, 10) + "/" + strconv.Itoa(version)
+}
+
+// decodeDealt decodes the payload of a DealtAction event into the hands, seed and deal version. Older events have the
+// hands without a seed, or a seed without a version (DealMathRand).
+func decodeDealt(payload string) (Hands, int64, int, error) {
+	parts := strings.SplitN(payload, "/", 3)
+	hands, err := NewHandsFromEncoded(parts[0])
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	if len(parts) == 1 {
+		return hands, 0, DealMathRand, nil
+	}
+	seed, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return nil, 0, 0, fmt.Errorf("parsing seed %q: %v", parts[1], err)
+	}
+	if len(parts) == 2 {
+		return hands, seed, DealMathRand, nil
+	}
+	version, err := strconv.Atoi(parts[2])
+	if err != nil {
+		return nil, 0, 0, fmt.Errorf("parsing deal version %q: %v", parts[2], err)
+	}
+	return hands, seed, version, nil
+}
@@ -0,0 +1,227 @@
+package game
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/squee1945/threespot/server/pkg/storage"
+)
+
+func TestDealHands(t *testing.T) {
+	for _, players := range []int{3, 4, 6} {
+		t.Run(fmt.Sprintf("%d players", players), func(t *testing.T) {
+			first, err := DealHands(players, 42, CurrentDealVersion)
+			if err != nil {
+				t.Fatal(err)
+			}
+			second, err := DealHands(players, 42, CurrentDealVersion)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got, want := second.Encoded(), first.Encoded(); got != want {
+				t.Errorf("DealHands()=%q want=%q", got, want)
+			}
+			other, err := DealHands(players, 43, CurrentDealVersion)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if other.Encoded() == first.Encoded() {
+				t.Errorf("different seeds dealt the same hands %q", first.Encoded())
+			}
+		})
+	}
+}
+
+func TestDealHandsVersions(t *testing.T) {
+	testCases := []struct {
+		name    string
+		players int
+		version int
+		want    string
+	}{
+		{
+			// The hands user-012 dealt from the seed, before deal versions.
+			name:    "math/rand",
+			players: 4,
+			version: DealMathRand,
+			want:    "KS|JC|AS|JD|TS|JH|8D|KH+7D|8C|QC|QD|AC|3S|QS|9S+JS|5H|TH|9C|KC|KD|9D|9H+AD|QH|TC|8S|AH|7C|8H|TD",
+		},
+		{
+			name:    "math/rand three players",
+			players: 3,
+			version: DealMathRand,
+			want:    "3S|TC|9C|QS|JH|KS|TD|TS+QH|AC|AH|KC|KD|9D|5H|TH+QC|AS|AD|JC|QD|JS|KH|JD",
+		},
+		{
+			name:    "SplitMix64",
+			players: 4,
+			version: DealSplitMix,
+			want:    "KS|3S|9S|9C|TH|9D|JS|8D+KH|QS|8C|AD|TD|KC|QH|7D+9H|QD|5H|AH|JC|KD|AC|JH+JD|TS|8S|TC|7C|QC|8H|AS",
+		},
+		{
+			name:    "SplitMix64 three players",
+			players: 3,
+			version: DealSplitMix,
+			want:    "9C|KH|AC|AD|QC|AH|KS|9D+JD|JS|TC|QS|JH|TS|5H|QH+KD|JC|TH|3S|QD|KC|TD|AS",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			hands, err := DealHands(tc.players, 42, tc.version)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got := hands.Encoded(); got != tc.want {
+				t.Errorf("DealHands(%d, 42, %d)=%q want=%q", tc.players, tc.version, got, tc.want)
+			}
+		})
+	}
+
+	if _, err := DealHands(4, 42, CurrentDealVersion+1); err == nil {
+		t.Errorf("DealHands() with unknown version got nil error")
+	}
+}
+
+func TestDecodeDealt(t *testing.T) {
+	testCases := []struct {
+		name        string
+		payload     string
+		wantSeed    int64
+		wantVersion int
+		wantErr     bool
+	}{
+		{
+			name:        "with seed and version",
+			payload:     "AH+AS+AD+AC/42/1",
+			wantSeed:    42,
+			wantVersion: DealSplitMix,
+		},
+		{
+			name:        "with seed",
+			payload:     "AH+AS+AD+AC/42",
+			wantSeed:    42,
+			wantVersion: DealMathRand,
+		},
+		{
+			name:    "without seed",
+			payload: "AH+AS+AD+AC",
+		},
+		{
+			name:    "bad seed",
+			payload: "AH+AS+AD+AC/X",
+			wantErr: true,
+		},
+		{
+			name:    "bad version",
+			payload: "AH+AS+AD+AC/42/X",
+			wantErr: true,
+		},
+		{
+			name:    "bad hands",
+			payload: "AH+AS/42",
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			hands, seed, version, err := decodeDealt(tc.payload)
+			if tc.wantErr && err == nil {
+				t.Fatal("missing expected error")
+			}
+			if !tc.wantErr && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tc.wantErr {
+				return
+			}
+			if got, want := seed, tc.wantSeed; got != want {
+				t.Errorf("seed=%d want=%d", got, want)
+			}
+			if got, want := version, tc.wantVersion; got != want {
+				t.Errorf("version=%d want=%d", got, want)
+			}
+			if got, want := hands.Encoded(), "AH+AS+AD+AC"; got != want {
+				t.Errorf("hands=%q want=%q", got, want)
+			}
+		})
+	}
+}
+
+func TestStartHandRecordsSeed(t *testing.T) {
+	ctx := context.Background()
+	gameStore := storage.NewFakeGameStore(nil)
+	playerStore := storage.NewFakePlayerStore()
+
+	var ps []Player
+	for i := 0; i < 4; i++ {
+		ps = append(ps, buildPlayer(t, playerStore, fmt.Sprintf("PLAYER%d", i)))
+	}
+	rules := NewRules()
+	rules.SetAllPass(RedealNextDealer)
+	g, err := NewGame(ctx, gameStore, playerStore, "ABC123", ps[0], rules)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantSeed := rand.NewSource(7).Int63()
+	g.(*game).seeds = rand.NewSource(7)
+	for pos := 1; pos < 4; pos++ {
+		if g, err = g.AddPlayer(ctx, ps[pos], pos); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// The hand is re-dealt exactly from the stored seed.
+	gs, err := gameStore.Get(ctx, g.ID())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := gs.CurrentSeed, wantSeed; got != want {
+		t.Fatalf("CurrentSeed=%d want=%d", got, want)
+	}
+	if got, want := gs.CurrentDealVersion, CurrentDealVersion; got != want {
+		t.Fatalf("CurrentDealVersion=%d want=%d", got, want)
+	}
+	redealt, err := DealHands(4, gs.CurrentSeed, gs.CurrentDealVersion)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stored, err := GetGame(ctx, gameStore, playerStore, g.ID())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for pos := 0; pos < 4; pos++ {
+		got, err := stored.(*game).currentHands.Hand(pos)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want, err := redealt.Hand(pos)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diff := cmp.Diff(encodeCards(want.Cards()), encodeCards(got.Cards())); diff != "" {
+			t.Errorf("hand %d mismatch (-want +got):\n%s", pos, diff)
+		}
+	}
+
+	// Throwing the hand in records the seed with the hand.
+	for g.State() == BiddingState && len(g.Score().Hands()) == 0 {
+		pos, err := g.PosToPlay()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if g, err = g.PlaceBid(ctx, ps[pos], buildBid(t, "P")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got, want := g.Score().Hands()[0].Seed, wantSeed; got != want {
+		t.Errorf("Hands()[0].Seed=%d want=%d", got, want)
+	}
+	if got, want := g.Score().Hands()[0].DealVersion, CurrentDealVersion; got != want {
+		t.Errorf("Hands()[0].DealVersion=%d want=%d", got, want)
+	}
+}
@@ -0,0 +1,356 @@
+package game
+
+import (
+	"fmt"
+	"math/bits"
+	"sort"
+	"strings"
+
+	"github.com/squee1945/threespot/server/pkg/deck"
+)
+
+// Solution is the outcome of the rest of a hand played double dummy: every player sees every hand and plays their
+// best for their team.
+type Solution struct {
+	// Pos is the position of the player to play.
+	Pos int
+	// Points are the points each team takes in the rest of the hand, the current trick included, scored as the
+	// tally scores tricks.
+	Points []int
+	// Plays are the cards the player may play with the points each team takes after each, best for the player first.
+	Plays []SolvedPlay
+}
+
+// SolvedPlay is a card the player to play may play, and the points each team takes after it.
+type SolvedPlay struct {
+	Card   deck.Card
+	Points []int
+}
+
+// Best returns the card the player to play does best with.
+func (s *Solution) Best() deck.Card {
+	return s.Plays[0].Card
+}
+
+// Solver solves hands double dummy by alpha-beta search. It remembers the positions it has solved, so solving each
+// card of a hand in turn (e.g., to review the hand after it is played) costs little more than solving the first.
+// A solver is not safe for concurrent use.
+type Solver struct {
+	table map[ddKey]ddBound
+}
+
+// NewSolver returns a solver with nothing solved yet.
+func NewSolver() *Solver {
+	return &Solver{table: make(map[ddKey]ddBound)}
+}
+
+// ddRanks are the card numbers in the order the solver indexes them, lowest first.
+const ddRanks = orderedCards
+
+// ddInfinity is beyond any points a team can take.
+const ddInfinity = 1 << 10
+
+var (
+	// ddSuits are the suits in the order the solver indexes them; each takes len(ddRanks) bits of a card set.
+	ddSuits = trumpSuits
+
+	ddFiveOfHearts  = ddIndex(deck.FiveOfHearts)
+	ddThreeOfSpades = ddIndex(deck.ThreeOfSpades)
+)
+
+// ddKey is the position at the start of a trick: the cards each player holds, who leads and what is trump.
+type ddKey struct {
+	hands [defaultPlayers]uint64
+	lead  int
+	trump int
+}
+
+// ddBound bounds the points team 0 takes in the rest of the hand from a position.
+type ddBound struct {
+	lower, upper int
+}
+
+// ddSearch is a position in the hand being searched; cards are bits indexed by ddIndex.
+type ddSearch struct {
+	table map[ddKey]ddBound
+	trump int // The index of the trump suit in ddSuits, -1 for no trump.
+	hands [defaultPlayers]uint64
+	lead  int
+	trick [defaultPlayers]int // The cards played to the trick, lead first.
+	n     int                 // The number of cards played to the trick.
+}
+
+// ddIndex returns the bit of the card in a card set.
+func ddIndex(card deck.Card) int {
+	for i, suit := range ddSuits {
+		if card.Suit() == suit {
+			return i*len(ddRanks) + strings.Index(ddRanks, card.Num())
+		}
+	}
+	return -1
+}
+
+// ddCard returns the card at the bit in a card set.
+func ddCard(i int) deck.Card {
+	card, err := deck.NewCard(ddRanks[i%len(ddRanks):i%len(ddRanks)+1], ddSuits[i/len(ddRanks)])
+	if err != nil {
+		panic(err) // Only cards with an index are indexed.
+	}
+	return card
+}
+
+// ddPoints returns the points the card adds to the trick it is in.
+func ddPoints(i int) int {
+	switch i {
+	case ddFiveOfHearts:
+		return 5
+	case ddThreeOfSpades:
+		return -3
+	}
+	return 0
+}
+
+// Solve returns the outcome of the rest of the hand from the position of the hands and trick, with the trick giving
+// the trump and the lead position, and the cards played to it so far. The hands are the cards the players still hold;
+// the solver is for the four player partnership game.
+func (s *Solver) Solve(hands Hands, trick Trick) (*Solution, error) {
+	if trick.IsDone() {
+		return nil, fmt.Errorf("trick is complete")
+	}
+	d := &ddSearch{table: s.table, trump: -1, lead: trick.LeadPos()}
+	for i, suit := range ddSuits {
+		if trick.Trump() == suit {
+			d.trump = i
+		}
+	}
+
+	var seen uint64
+	counts := make([]int, defaultPlayers)
+	for pos := 0; pos < defaultPlayers; pos++ {
+		hand, err := hands.Hand(pos)
+		if err != nil {
+			return nil, err
+		}
+		counts[pos] = len(hand.Cards())
+		for _, card := range hand.Cards() {
+			i := ddIndex(card)
+			if i < 0 || seen&(1<<uint(i)) != 0 {
+				return nil, fmt.Errorf("card %s not valid or held twice", card.Encoded())
+			}
+			seen |= 1 << uint(i)
+			d.hands[pos] |= 1 << uint(i)
+		}
+	}
+	if _, err := hands.Hand(defaultPlayers); err == nil {
+		return nil, fmt.Errorf("solver is for %d players", defaultPlayers)
+	}
+	for _, card := range trick.Cards() {
+		i := ddIndex(card)
+		if i < 0 || seen&(1<<uint(i)) != 0 {
+			return nil, fmt.Errorf("card %s not valid or held twice", card.Encoded())
+		}
+		seen |= 1 << uint(i)
+		d.trick[d.n] = i
+		d.n++
+	}
+
+	pos, err := trick.CurrentTurnPos()
+	if err != nil {
+		return nil, err
+	}
+	if counts[pos] == 0 {
+		return nil, fmt.Errorf("no cards left to play")
+	}
+	for ord := 0; ord < defaultPlayers; ord++ {
+		// The players that played to the trick hold a card fewer than those still to play.
+		want := counts[pos]
+		if ord < d.n {
+			want--
+		}
+		if counts[(d.lead+ord)%defaultPlayers] != want {
+			return nil, fmt.Errorf("hands %q do not fit trick %q", hands.Encoded(), trick.Encoded())
+		}
+	}
+
+	// The points taken by the two teams add up to the same total whatever is played.
+	total := counts[pos]
+	for _, i := range []int{ddFiveOfHearts, ddThreeOfSpades} {
+		if seen&(1<<uint(i)) != 0 {
+			total += ddPoints(i)
+		}
+	}
+	team := TeamOf(pos, defaultPlayers)
+	teamPoints := func(v int) []int {
+		return []int{v, total - v}
+	}
+
+	solution := &Solution{Pos: pos}
+	for _, i := range d.legal(pos) {
+		v := d.playAndSearch(pos, i, -ddInfinity, ddInfinity)
+		solution.Plays = append(solution.Plays, SolvedPlay{Card: ddCard(i), Points: teamPoints(v)})
+	}
+	sort.SliceStable(solution.Plays, func(i, j int) bool {
+		return solution.Plays[i].Points[team] > solution.Plays[j].Points[team]
+	})
+	solution.Points = solution.Plays[0].Points
+	return solution, nil
+}
+
+// legal returns the cards the player may play, highest of each suit first.
+func (d *ddSearch) legal(pos int) []int {
+	cards := d.hands[pos]
+	if d.n > 0 {
+		if suited := cards & ddSuitMask(d.trick[0]/len(ddRanks)); suited != 0 {
+			cards = suited
+		}
+	}
+	var out []int
+	for cards != 0 {
+		i := 63 - bits.LeadingZeros64(cards)
+		out = append(out, i)
+		cards &^= 1 << uint(i)
+	}
+	return out
+}
+
+// ddSuitMask returns the card set of every card of the suit.
+func ddSuitMask(suit int) uint64 {
+	return (1<<uint(len(ddRanks)) - 1) << uint(suit*len(ddRanks))
+}
+
+// search returns the points team 0 takes in the rest of the hand with best play, if that is within (alpha, beta);
+// otherwise a bound on them on the side of the window it falls.
+func (d *ddSearch) search(alpha, beta int) int {
+	if d.n > 0 {
+		return d.searchMoves(alpha, beta)
+	}
+	if d.hands[d.lead] == 0 {
+		return 0
+	}
+
+	key := ddKey{hands: d.hands, lead: d.lead, trump: d.trump}
+	bound, ok := d.table[key]
+	if !ok {
+		bound = ddBound{lower: -ddInfinity, upper: ddInfinity}
+	}
+	if bound.lower >= beta || bound.lower == bound.upper {
+		return bound.lower
+	}
+	if bound.upper <= alpha {
+		return bound.upper
+	}
+	if bound.lower > alpha {
+		alpha = bound.lower
+	}
+	if bound.upper < beta {
+		beta = bound.upper
+	}
+
+	v := d.searchMoves(alpha, beta)
+	switch {
+	case v <= alpha:
+		bound.upper = v
+	case v >= beta:
+		bound.lower = v
+	default:
+		bound.lower, bound.upper = v, v
+	}
+	d.table[key] = bound
+	return v
+}
+
+// searchMoves searches each card the player to play may play, skipping those that do the same as a card tried.
+func (d *ddSearch) searchMoves(alpha, beta int) int {
+	pos := (d.lead + d.n) % defaultPlayers
+	maximize := TeamOf(pos, defaultPlayers) == 0
+
+	// Cards of a suit with nothing left between them but cards already played in earlier tricks do the same.
+	var live uint64
+	for _, hand := range d.hands {
+		live |= hand
+	}
+	for _, i := range d.trick[:d.n] {
+		live |= 1 << uint(i)
+	}
+
+	best := ddInfinity
+	if maximize {
+		best = -ddInfinity
+	}
+	prev := -1
+	for _, i := range d.legal(pos) {
+		if prev >= 0 && prev/len(ddRanks) == i/len(ddRanks) && ddPoints(i) == 0 && ddPoints(prev) == 0 {
+			between := (uint64(1)<<uint(prev) - 1) &^ (uint64(1)<<uint(i+1) - 1)
+			if live&between == 0 {
+				prev = i
+				continue
+			}
+		}
+		prev = i
+
+		v := d.playAndSearch(pos, i, alpha, beta)
+		if maximize {
+			if v > best {
+				best = v
+			}
+			if best > alpha {
+				alpha = best
+			}
+		} else {
+			if v < best {
+				best = v
+			}
+			if best < beta {
+				beta = best
+			}
+		}
+		if alpha >= beta {
+			break
+		}
+	}
+	return best
+}
+
+// playAndSearch plays the card for the player and searches on from there, then takes it back.
+func (d *ddSearch) playAndSearch(pos, i, alpha, beta int) int {
+	d.hands[pos] &^= 1 << uint(i)
+	d.trick[d.n] = i
+	d.n++
+
+	var v int
+	if d.n < defaultPlayers {
+		v = d.search(alpha, beta)
+	} else {
+		winner, points := d.trickResult()
+		gain := 0
+		if TeamOf(winner, defaultPlayers) == 0 {
+			gain = points
+		}
+		lead, trick := d.lead, d.trick
+		d.lead, d.n = winner, 0
+		v = gain + d.search(alpha-gain, beta-gain)
+		d.lead, d.n, d.trick = lead, defaultPlayers, trick
+	}
+
+	d.n--
+	d.hands[pos] |= 1 << uint(i)
+	return v
+}
+
+// trickResult returns the position of the player winning the complete trick, and its points.
+func (d *ddSearch) trickResult() (int, int) {
+	high := 0
+	points := 1 + ddPoints(d.trick[0])
+	for ord := 1; ord < defaultPlayers; ord++ {
+		i := d.trick[ord]
+		points += ddPoints(i)
+		suit, highSuit := i/len(ddRanks), d.trick[high]/len(ddRanks)
+		switch {
+		case suit == highSuit && i > d.trick[high]:
+			high = ord
+		case suit == d.trump && highSuit != d.trump:
+			high = ord
+		}
+	}
+	return (d.lead + high) % defaultPlayers, points
+}
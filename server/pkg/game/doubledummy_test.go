@@ -0,0 +1,279 @@
+package game
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/squee1945/threespot/server/pkg/deck"
+)
+
+// bruteForce returns the points each team takes in the rest of the hand with best play, trying every legal card with
+// the game's own tricks and tally.
+func bruteForce(t *testing.T, hs Hands, tr Trick) []int {
+	t.Helper()
+	if tr.IsDone() {
+		tally := NewTally()
+		if err := tally.addTrick(tr); err != nil {
+			t.Fatal(err)
+		}
+		points := append([]int(nil), tally.Points()...)
+		winner, err := tr.WinningPos()
+		if err != nil {
+			t.Fatal(err)
+		}
+		next, err := newTrickForPlayers(tr.Trump(), winner, defaultPlayers)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if hand, _ := hs.Hand(winner); hand.IsEmpty() {
+			return points
+		}
+		rest := bruteForce(t, hs, next)
+		return []int{points[0] + rest[0], points[1] + rest[1]}
+	}
+
+	pos, err := tr.CurrentTurnPos()
+	if err != nil {
+		t.Fatal(err)
+	}
+	team := TeamOf(pos, defaultPlayers)
+	hand, err := hs.Hand(pos)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var best []int
+	for _, card := range append([]deck.Card(nil), hand.Cards()...) {
+		if !(&Engine{currentTrick: tr}).followsSuit(hand, card) {
+			continue
+		}
+		next := hs.clone()
+		h, _ := next.Hand(pos)
+		if err := h.removeCard(card); err != nil {
+			t.Fatal(err)
+		}
+		played := tr.clone()
+		if err := played.playCard(pos, card); err != nil {
+			t.Fatal(err)
+		}
+		if points := bruteForce(t, next, played); best == nil || points[team] > best[team] {
+			best = points
+		}
+	}
+	return best
+}
+
+// randomEnding returns the last cards of a random deal, with a random trump and lead and cards played to the trick.
+func randomEnding(t *testing.T, seed int64, cards int) (Hands, Trick) {
+	t.Helper()
+	rnd := rand.New(rand.NewSource(seed))
+	d, err := deck.NewDeckForPlayers(defaultPlayers, rnd)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var sets [][]deck.Card
+	for _, set := range d.Deal() {
+		sets = append(sets, set[:cards])
+	}
+	hs, err := NewHands(sets)
+	if err != nil {
+		t.Fatal(err)
+	}
+	trumps := append([]deck.Suit{deck.NoTrump}, trumpSuits...)
+	tr, err := NewTrick(trumps[rnd.Intn(len(trumps))], rnd.Intn(defaultPlayers))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for played := rnd.Intn(defaultPlayers); played > 0; played-- {
+		pos, err := tr.CurrentTurnPos()
+		if err != nil {
+			t.Fatal(err)
+		}
+		hand, err := hs.Hand(pos)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var legal []deck.Card
+		for _, card := range hand.Cards() {
+			if (&Engine{currentTrick: tr}).followsSuit(hand, card) {
+				legal = append(legal, card)
+			}
+		}
+		card := legal[rnd.Intn(len(legal))]
+		if err := hand.removeCard(card); err != nil {
+			t.Fatal(err)
+		}
+		if err := tr.playCard(pos, card); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return hs, tr
+}
+
+func TestSolverMatchesBruteForce(t *testing.T) {
+	for cards := 1; cards <= 4; cards++ {
+		for seed := int64(0); seed < 25; seed++ {
+			t.Run(fmt.Sprintf("%d/%d", cards, seed), func(t *testing.T) {
+				hs, tr := randomEnding(t, seed, cards)
+
+				got, err := NewSolver().Solve(hs, tr)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				if want := bruteForce(t, hs, tr); !cmp.Equal(got.Points, want) {
+					t.Errorf("Solve(%s, %s) Points=%v want=%v", hs.Encoded(), tr.Encoded(), got.Points, want)
+				}
+				for _, play := range got.Plays {
+					next := hs.clone()
+					hand, _ := next.Hand(got.Pos)
+					if err := hand.removeCard(play.Card); err != nil {
+						t.Fatal(err)
+					}
+					played := tr.clone()
+					if err := played.playCard(got.Pos, play.Card); err != nil {
+						t.Fatal(err)
+					}
+					if want := bruteForce(t, next, played); !cmp.Equal(play.Points, want) {
+						t.Errorf("Solve(%s, %s) %s Points=%v want=%v", hs.Encoded(), tr.Encoded(), play.Card.Encoded(), play.Points, want)
+					}
+				}
+				if !cmp.Equal(got.Plays[0].Points, got.Points) || !got.Best().IsSameAs(got.Plays[0].Card) {
+					t.Errorf("Best()=%s %v want the card of Points %v", got.Best().Encoded(), got.Plays[0].Points, got.Points)
+				}
+			})
+		}
+	}
+}
+
+func TestSolverPoints(t *testing.T) {
+	testCases := []struct {
+		name       string
+		hands      string
+		trick      string
+		wantPoints []int
+		wantBest   string
+	}{
+		{
+			name:       "five of hearts",
+			hands:      "AH+5H+9H+KH",
+			trick:      "0|S",
+			wantPoints: []int{6, 0},
+			wantBest:   "AH",
+		},
+		{
+			name:       "three of spades",
+			hands:      "+3S+8S+",
+			trick:      "3|H|9S|AS",
+			wantPoints: []int{-2, 0},
+			wantBest:   "3S",
+		},
+		{
+			name:       "trump the five of hearts",
+			hands:      "8C++KH+9C",
+			trick:      "1|C|5H",
+			wantPoints: []int{0, 6},
+			wantBest:   "KH",
+		},
+		{
+			name:       "take the five of hearts",
+			hands:      "AH|8H+9D+TD+JD",
+			trick:      "1|N|5H|9H|KH",
+			wantPoints: []int{7, 0},
+			wantBest:   "AH",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			hs, err := NewHandsFromEncoded(tc.hands)
+			if err != nil {
+				t.Fatal(err)
+			}
+			tr, err := NewTrickFromEncoded(tc.trick)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := NewSolver().Solve(hs, tr)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !cmp.Equal(got.Points, tc.wantPoints) {
+				t.Errorf("Points=%v want=%v", got.Points, tc.wantPoints)
+			}
+			if got.Best().Encoded() != tc.wantBest {
+				t.Errorf("Best()=%s want=%s", got.Best().Encoded(), tc.wantBest)
+			}
+		})
+	}
+}
+
+func TestSolverErrors(t *testing.T) {
+	testCases := []struct {
+		name  string
+		hands string
+		trick string
+	}{
+		{name: "complete trick", hands: "+++", trick: "0|H|AH|KH|QH|JH"},
+		{name: "uneven hands", hands: "AH|KH+QH+JH+TH", trick: "0|H"},
+		{name: "not fitting trick", hands: "AH+QH+JH+TH", trick: "0|H|KH"},
+		{name: "card twice", hands: "AH+KH+JH+TH", trick: "1|H|AH"},
+		{name: "no cards", hands: "+++", trick: "0|H"},
+		{name: "not four players", hands: "AH+KH+QH", trick: "0/3|H"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			hs, err := NewHandsFromEncoded(tc.hands)
+			if err != nil {
+				t.Fatal(err)
+			}
+			tr, err := NewTrickFromEncoded(tc.trick)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if _, err := NewSolver().Solve(hs, tr); err == nil {
+				t.Errorf("Solve(%s, %s) got nil error", tc.hands, tc.trick)
+			}
+		})
+	}
+}
+
+func TestSolverFullDeal(t *testing.T) {
+	for seed := int64(0); seed < 5; seed++ {
+		hs, tr := randomEnding(t, seed, deck.CardsPerHand)
+		solver := NewSolver()
+
+		start := time.Now()
+		got, err := solver.Solve(hs, tr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if elapsed := time.Since(start); elapsed > time.Second {
+			t.Errorf("Solve(%s, %s) took %v", hs.Encoded(), tr.Encoded(), elapsed)
+		}
+
+		// Playing the best card leaves the same outcome.
+		hand, _ := hs.Hand(got.Pos)
+		if err := hand.removeCard(got.Best()); err != nil {
+			t.Fatal(err)
+		}
+		if err := tr.playCard(got.Pos, got.Best()); err != nil {
+			t.Fatal(err)
+		}
+		if tr.IsDone() {
+			continue
+		}
+		next, err := solver.Solve(hs, tr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !cmp.Equal(next.Points, got.Points) {
+			t.Errorf("Points after Best()=%v want=%v", next.Points, got.Points)
+		}
+	}
+}
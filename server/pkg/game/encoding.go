@@ -0,0 +1,219 @@
+package game
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/squee1945/threespot/server/pkg/deck"
+	"github.com/squee1945/threespot/server/pkg/storage"
+)
+
+// stateVersion is the version of storedState written to storage.Game.State.
+// Version 0 is the legacy form, where each part of the state is in its own Encoded() string.
+const stateVersion = 1
+
+// storedState is the state of play of a game, JSON encoded into storage.Game.State.
+// Fields may be added; any other change needs a new stateVersion, with the older versions still decoded.
+type storedState struct {
+	Score        storedScore
+	Bidding      *storedBidding
+	Hands        [][]string // The cards held by each player, by position.
+	CurrentTrick *storedTrick
+	LastTrick    *storedTrick
+	Tricks       []storedTrick // The tricks played in the current hand; empty for games saved before they were kept.
+	Tally        *storedTally
+	Passing      *storedPassing
+	UndoRequest  *UndoRequest
+	SeatSwap     *SeatSwap
+}
+
+// encodeState returns the JSON encoded storedState of the game.
+func encodeState(g *game) (string, error) {
+	st := storedState{
+		Score:       g.score.stored(),
+		UndoRequest: g.undoRequest,
+		SeatSwap:    g.seatSwap,
+	}
+	if g.currentBidding != nil {
+		b := g.currentBidding.stored()
+		st.Bidding = &b
+	}
+	for pos := 0; pos < g.rules.Players(); pos++ {
+		hand, err := g.currentHands.Hand(pos)
+		if err != nil {
+			return "", err
+		}
+		st.Hands = append(st.Hands, encodeCards(hand.Cards()))
+	}
+	if g.currentTrick != nil {
+		t := g.currentTrick.stored()
+		st.CurrentTrick = &t
+	}
+	if g.lastTrick != nil {
+		t := g.lastTrick.stored()
+		st.LastTrick = &t
+	}
+	for _, trick := range g.tricks {
+		st.Tricks = append(st.Tricks, trick.stored())
+	}
+	if g.currentTally != nil {
+		t := g.currentTally.stored()
+		st.Tally = &t
+	}
+	if g.passedCards != nil {
+		p := g.passedCards.stored()
+		st.Passing = &p
+	}
+	b, err := json.Marshal(st)
+	if err != nil {
+		return "", fmt.Errorf("encoding game state: %v", err)
+	}
+	return string(b), nil
+}
+
+// decodeState sets the state of play of the engine from the encodeState() form, returning the decoded state for the
+// pending requests it holds.
+func decodeState(encoded string, e *Engine) (*storedState, error) {
+	var st storedState
+	if err := json.Unmarshal([]byte(encoded), &st); err != nil {
+		return nil, fmt.Errorf("decoding game state: %v", err)
+	}
+	players := e.rules.Players()
+
+	var err error
+	if e.score, err = newScoreFromStored(st.Score); err != nil {
+		return nil, err
+	}
+
+	e.currentBidding, err = newBiddingRoundForPlayers(0, players)
+	if err != nil {
+		return nil, err
+	}
+	if st.Bidding != nil {
+		if e.currentBidding, err = newBiddingRoundFromStored(*st.Bidding); err != nil {
+			return nil, err
+		}
+	}
+
+	e.currentHands = newEmptyHands(players)
+	if len(st.Hands) > 0 {
+		var cardSets [][]deck.Card
+		for _, encodeds := range st.Hands {
+			cards, err := decodeCards(encodeds)
+			if err != nil {
+				return nil, err
+			}
+			cardSets = append(cardSets, cards)
+		}
+		if e.currentHands, err = NewHands(cardSets); err != nil {
+			return nil, err
+		}
+	}
+
+	if st.CurrentTrick != nil {
+		if e.currentTrick, err = newTrickFromStored(*st.CurrentTrick); err != nil {
+			return nil, err
+		}
+	}
+	if st.LastTrick != nil {
+		if e.lastTrick, err = newTrickFromStored(*st.LastTrick); err != nil {
+			return nil, err
+		}
+	}
+	for _, stored := range st.Tricks {
+		trick, err := newTrickFromStored(stored)
+		if err != nil {
+			return nil, err
+		}
+		e.tricks = append(e.tricks, trick)
+	}
+
+	e.currentTally = newTallyForTeams(NumTeams(players))
+	if st.Tally != nil {
+		if e.currentTally, err = newTallyFromStored(*st.Tally); err != nil {
+			return nil, err
+		}
+	}
+
+	e.passedCards, err = newPassingRoundForRules(e.rules, 0, 0)
+	if err != nil {
+		return nil, err
+	}
+	if st.Passing != nil {
+		if e.passedCards, err = newPassingRoundFromStored(*st.Passing); err != nil {
+			return nil, err
+		}
+	}
+	return &st, nil
+}
+
+// decodeLegacyState sets the state of play of the engine from the version 0 encoded strings of gs, returning the
+// pending undo request.
+func decodeLegacyState(gs *storage.Game, e *Engine) (*UndoRequest, error) {
+	var err error
+	if e.currentBidding, err = NewBiddingRoundFromEncoded(gs.CurrentBidding); err != nil {
+		return nil, err
+	}
+
+	e.currentHands = newEmptyHands(e.rules.Players())
+	if gs.CurrentHands != "" {
+		if e.currentHands, err = NewHandsFromEncoded(gs.CurrentHands); err != nil {
+			return nil, err
+		}
+	}
+
+	if gs.CurrentTrick != "" {
+		if e.currentTrick, err = NewTrickFromEncoded(gs.CurrentTrick); err != nil {
+			return nil, err
+		}
+	}
+
+	if gs.LastTrick != "" {
+		if e.lastTrick, err = NewTrickFromEncoded(gs.LastTrick); err != nil {
+			return nil, err
+		}
+	}
+
+	if e.score, err = NewScoreFromEncoded(gs.Score); err != nil {
+		return nil, err
+	}
+	if len(e.score.Scores()) == 0 {
+		// No hands have been scored yet; start the score sheet with the target and teams from the rules.
+		e.score = newScoreForRules(e.rules)
+	}
+
+	e.currentTally = newTallyForTeams(NumTeams(e.rules.Players()))
+	if gs.CurrentTally != "" {
+		if e.currentTally, err = NewTallyFromEncoded(gs.CurrentTally); err != nil {
+			return nil, err
+		}
+	}
+
+	if e.passedCards, err = NewPassingRoundFromEncoded(gs.PassedCards); err != nil {
+		return nil, err
+	}
+
+	return decodeUndoRequest(gs.UndoRequest)
+}
+
+// encodeCards returns the Encoded() form of each card.
+func encodeCards(cards []deck.Card) []string {
+	var encodeds []string
+	for _, card := range cards {
+		encodeds = append(encodeds, card.Encoded())
+	}
+	return encodeds
+}
+
+// decodeCards returns the cards from their Encoded() forms.
+func decodeCards(encodeds []string) ([]deck.Card, error) {
+	var cards []deck.Card
+	for _, encoded := range encodeds {
+		card, err := deck.NewCardFromEncoded(encoded)
+		if err != nil {
+			return nil, err
+		}
+		cards = append(cards, card)
+	}
+	return cards, nil
+}
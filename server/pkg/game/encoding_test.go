@@ -0,0 +1,128 @@
+package game
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/squee1945/threespot/server/pkg/storage"
+)
+
+// legacyGame is a game part way through a hand, in the version 0 storage form.
+func legacyGame() *storage.Game {
+	return &storage.Game{
+		PlayerIDs:        []string{"ABE", "BOB", "CAL", "DON"},
+		Score:            "52-10|-4**1|0|misdeal##0|2|3|P,7,P,P|0|7|H|5,3|10,-4|0|1",
+		CurrentDealerPos: 3,
+		CurrentBidding:   "0|7|P|P|P",
+		CurrentHands:     "AH|KH+AS|KS|QS+AC|KC|QC+AD|KD|QD",
+		CurrentSeed:      42,
+		CurrentTrick:     "0|H|QH",
+		LastTrick:        "1|H|JD|JS|JC|JH",
+		CurrentTally:     "5|4|1|4|1;0|1",
+		PassedCards:      "0|",
+		UndoRequest:      "0|12|1",
+	}
+}
+
+func TestStateRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	g, gameStore, playerStore := buildGame(t, legacyGame())
+
+	gs, err := storageFromGame(g.(*game))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gs.StateVersion != stateVersion {
+		t.Errorf("StateVersion=%d want=%d", gs.StateVersion, stateVersion)
+	}
+	if gs.Score != "" || gs.CurrentBidding != "" || gs.CurrentHands != "" || gs.CurrentTrick != "" || gs.LastTrick != "" || gs.CurrentTally != "" || gs.PassedCards != "" || gs.UndoRequest != "" {
+		t.Errorf("legacy fields not cleared: %+v", gs)
+	}
+
+	decoded, err := gameFromStorage(ctx, gameStore, playerStore, g.ID(), gs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(legacyStorage(t, g.(*game)), legacyStorage(t, decoded), ignoreDates); diff != "" {
+		t.Errorf("decoded game mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestStateKeepsAnyScoreNote(t *testing.T) {
+	ctx := context.Background()
+	g, gameStore, playerStore := buildGame(t, legacyGame())
+	note := ScoreNote{Team: 0, Index: 0, Note: "6-2 | **not** a ## misdeal~~"}
+	g.(*game).score.(*score).notes = append(g.(*game).score.(*score).notes, note)
+
+	gs, err := storageFromGame(g.(*game))
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := gameFromStorage(ctx, gameStore, playerStore, g.ID(), gs)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []ScoreNote{{Team: 1, Index: 0, Note: "misdeal"}, note}
+	if diff := cmp.Diff(want, decoded.Score().Notes()); diff != "" {
+		t.Errorf("Notes() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestLegacyGameRewrittenOnSave(t *testing.T) {
+	ctx := context.Background()
+	g, gameStore, playerStore := buildGame(t, legacyGame())
+	g.(*game).undoRequest = nil // Playing a card is refused while an undo is pending.
+	player := getPlayer(t, playerStore, "BOB")
+	card := buildCard(t, "AS")
+
+	played, err := g.PlayCard(ctx, player, card)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	gs, err := gameStore.Get(ctx, g.ID())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gs.StateVersion != stateVersion {
+		t.Errorf("StateVersion=%d want=%d", gs.StateVersion, stateVersion)
+	}
+	reloaded, err := GetGame(ctx, gameStore, playerStore, g.ID())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(legacyStorage(t, played.(*game)), legacyStorage(t, reloaded.(*game)), ignoreDates); diff != "" {
+		t.Errorf("reloaded game mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestDecodeStateErrors(t *testing.T) {
+	testCases := []struct {
+		name    string
+		version int
+		state   string
+	}{
+		{name: "unknown version", version: stateVersion + 1, state: "{}"},
+		{name: "not json", version: stateVersion, state: "52-10|4"},
+		{name: "one team", version: stateVersion, state: `{"Score":{"Teams":1,"ToWin":52,"Winner":-1}}`},
+		{name: "note out of range", version: stateVersion, state: `{"Score":{"Teams":2,"ToWin":52,"Winner":-1,"Notes":[{"Team":0,"Index":0,"Note":"x"}]}}`},
+		{name: "duplicate card in trick", version: stateVersion, state: `{"Score":{"Teams":2,"ToWin":52,"Winner":-1},"CurrentTrick":{"LeadPos":0,"Players":4,"Trump":"H","Cards":["AH","AH"]}}`},
+		{name: "invalid card in hand", version: stateVersion, state: `{"Score":{"Teams":2,"ToWin":52,"Winner":-1},"Hands":[["ZZ"],[],[],[]]}`},
+		{name: "tally teams", version: stateVersion, state: `{"Score":{"Teams":2,"ToWin":52,"Winner":-1},"Tally":{"Points":[0],"Tricks":[0],"FiveTeam":-1,"ThreeTeam":-1}}`},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			gameStore := storage.NewFakeGameStore(nil)
+			playerStore := storage.NewFakePlayerStore("ABE", "BOB", "CAL", "DON")
+			gs := &storage.Game{PlayerIDs: []string{"ABE", "BOB", "CAL", "DON"}, StateVersion: tc.version, State: tc.state}
+
+			if _, err := gameFromStorage(ctx, gameStore, playerStore, "ABC123", gs); err == nil {
+				t.Error("missing error")
+			}
+		})
+	}
+}
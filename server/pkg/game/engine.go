@@ -0,0 +1,681 @@
+package game
+
+import (
+	"math/rand"
+
+	"github.com/squee1945/threespot/server/pkg/deck"
+)
+
+// Engine is the state of play of a game, without storage: actions are applied in memory, by player position.
+// Game stores an Engine after each action; an Engine can also be used on its own to simulate play.
+type Engine struct {
+	rules        Rules
+	complete     bool
+	currentPhase phase // The phase of play; see updatePhase.
+
+	score Score // The score of the game.
+
+	passedCards        PassingRound // Passed cards for current round.
+	currentDealerPos   int          // The position of the current dealer.
+	currentBidding     BiddingRound // The bids for the current hand.
+	currentHands       Hands        // Cards held by each player.
+	currentSeed        int64        // The seed the current hand was shuffled with; 0 if unknown.
+	currentDealVersion int          // The deal version the current hand was shuffled with (see DealHands).
+	currentTrick       Trick        // Cards played for current trick.
+	lastTrick          Trick        // Last trick played.
+	tricks             []Trick      // The tricks played in the current hand, oldest first.
+	trickStore         []trick      // Tricks to start, allocated together; not shared with clones.
+	currentTally       Tally        // The running tally for the current hand.
+
+	handsDealt int         // The number of hands dealt by this engine.
+	queued     []Dealt     // Hands to deal, oldest first, instead of shuffling.
+	seeds      rand.Source // The source of shuffle seeds; nil seeds from the current time.
+}
+
+// Dealt is a hand as dealt.
+type Dealt struct {
+	// DealerPos is the position of the dealer.
+	DealerPos int
+	// Hands are the cards dealt to each player.
+	Hands Hands
+	// Seed is the seed the hands were shuffled with (see DealHands), or 0 if unknown.
+	Seed int64
+	// DealVersion is the deal version the hands were shuffled with.
+	DealVersion int
+}
+
+// NewEngine starts a game with the rules, with dealerPos dealing the first hand.
+// Hands are shuffled with seeds from the source; a nil source seeds from the current time.
+func NewEngine(rules Rules, dealerPos int, seeds rand.Source) (*Engine, error) {
+	if err := rules.Validate(); err != nil {
+		return nil, err
+	}
+	players := rules.Players()
+	if dealerPos < 0 || dealerPos >= players {
+		return nil, ErrInvalidPosition
+	}
+	e := &Engine{
+		rules:        rules,
+		score:        newScoreForRules(rules),
+		currentHands: newEmptyHands(players),
+		seeds:        seeds,
+		// startHand moves the deal to the left, so start from the player to the right of the dealer.
+		currentDealerPos: (dealerPos + players - 1) % players,
+	}
+	if err := e.startHand(); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// Clone returns a copy of the engine that can be played independently. The copy shares the seed source.
+func (e *Engine) Clone() *Engine {
+	c := *e
+	if e.score != nil {
+		c.score = e.score.clone()
+	}
+	if e.passedCards != nil {
+		c.passedCards = e.passedCards.clone()
+	}
+	if e.currentBidding != nil {
+		c.currentBidding = e.currentBidding.clone()
+	}
+	if e.currentHands != nil {
+		c.currentHands = e.currentHands.clone()
+	}
+	if e.currentTrick != nil {
+		c.currentTrick = e.currentTrick.clone()
+	}
+	if e.lastTrick != nil {
+		c.lastTrick = e.lastTrick.clone()
+	}
+	c.tricks = append([]Trick(nil), e.tricks...) // Completed tricks do not change.
+	c.trickStore = nil
+	if e.currentTally != nil {
+		c.currentTally = e.currentTally.clone()
+	}
+	c.queued = append([]Dealt(nil), e.queued...)
+	return &c
+}
+
+// QueueDeals arranges for the next hands to be dealt as given, rather than shuffled.
+func (e *Engine) QueueDeals(deals ...Dealt) {
+	e.queued = append(e.queued, deals...)
+}
+
+func (e *Engine) Rules() Rules {
+	return e.rules
+}
+
+// phase is the state of play of an Engine, as a number; the engine compares phases, not GameStates, during play.
+type phase int
+
+const (
+	dealingPhase phase = iota
+	passingPhase
+	biddingPhase
+	callingPhase
+	playingPhase
+	completedPhase
+)
+
+// phaseStates are the GameStates of the phases, by phase.
+var phaseStates = [...]GameState{
+	dealingPhase:   DealingState,
+	passingPhase:   PassingState,
+	biddingPhase:   BiddingState,
+	callingPhase:   CallingState,
+	playingPhase:   PlayingState,
+	completedPhase: CompletedState,
+}
+
+// State returns the state of play. An Engine is never JoiningState; the players are always seated.
+func (e *Engine) State() GameState {
+	return phaseStates[e.phase()]
+}
+
+// phase returns the phase of play.
+func (e *Engine) phase() phase {
+	return e.currentPhase
+}
+
+// updatePhase sets the phase of play from the hands, passing, bidding and trick. It must be called after they are
+// changed: startHand and the actions call it as they return, and it is called once an engine is built from its parts.
+func (e *Engine) updatePhase() {
+	e.currentPhase = e.nextPhase()
+}
+
+// nextPhase returns the phase of play of the hands, passing, bidding and trick.
+func (e *Engine) nextPhase() phase {
+	if e.complete {
+		return completedPhase
+	}
+	if !e.currentHands.isDealt(e.rules.Players()) {
+		return dealingPhase
+	}
+	if e.passesBeforeBidding() && !e.passedCards.IsDone() {
+		return passingPhase
+	}
+	if !e.currentBidding.IsDone() {
+		return biddingPhase
+	}
+	if e.passesAfterAuction() && !e.passedCards.IsDone() {
+		return passingPhase
+	}
+	if e.currentTrick == nil {
+		return callingPhase
+	}
+	return playingPhase
+}
+
+func (e *Engine) PassedCards() PassingRound {
+	return e.passedCards
+}
+
+func (e *Engine) CurrentBidding() BiddingRound {
+	return e.currentBidding
+}
+
+func (e *Engine) CurrentTrick() Trick {
+	return e.currentTrick
+}
+
+func (e *Engine) LastTrick() Trick {
+	return e.lastTrick
+}
+
+// Tricks returns the tricks played in the current hand, oldest first.
+func (e *Engine) Tricks() []Trick {
+	return e.tricks
+}
+
+func (e *Engine) DealerPos() int {
+	return e.currentDealerPos
+}
+
+func (e *Engine) PosToPlay() (int, error) {
+	return e.posToPlay(e.phase())
+}
+
+// posToPlay returns the position of the player to play in the phase, which must be the engine's phase().
+func (e *Engine) posToPlay(p phase) (int, error) {
+	switch p {
+	case dealingPhase:
+		return e.DealerPos(), nil
+	case passingPhase:
+		return e.passedCards.CurrentTurnPos()
+	case biddingPhase:
+		return e.currentBidding.CurrentTurnPos()
+	case callingPhase:
+		_, pos, err := e.currentBidding.WinningBidAndPos()
+		if err != nil {
+			return 0, err
+		}
+		return pos, nil
+	case playingPhase:
+		pos, err := e.currentTrick.CurrentTurnPos()
+		if err != nil {
+			return 0, err
+		}
+		return pos, nil
+	}
+	return -1, nil
+}
+
+func (e *Engine) Tally() Tally {
+	return e.currentTally
+}
+
+func (e *Engine) Score() Score {
+	return e.score
+}
+
+// Hand returns the cards held by the player in pos.
+func (e *Engine) Hand(pos int) (Hand, error) {
+	return e.currentHands.Hand(pos)
+}
+
+func (e *Engine) HandCounts() []int {
+	var def, counts []int
+	for pos := 0; pos < e.rules.Players(); pos++ {
+		def = append(def, deck.CardsPerHand)
+	}
+	for pos := 0; pos < e.rules.Players(); pos++ {
+		hand, err := e.currentHands.Hand(pos)
+		if err != nil {
+			return def
+		}
+		counts = append(counts, hand.numCards())
+	}
+	return counts
+}
+
+// AvailableBids returns the bids the player in pos may place.
+func (e *Engine) AvailableBids(pos int) ([]Bid, error) {
+	if err := e.checkBidTurn(pos); err != nil {
+		return nil, err
+	}
+	return nextBidValues(e.rules, e.currentBidding.Bids(), pos == e.currentDealerPos), nil
+}
+
+// checkBidTurn returns an error if it is not the turn of the player in pos to bid.
+func (e *Engine) checkBidTurn(pos int) error {
+	if e.phase() != biddingPhase {
+		return ErrNotBidding
+	}
+	currentTurnPos, err := e.currentBidding.CurrentTurnPos()
+	if err != nil {
+		return err
+	}
+	if pos != currentTurnPos {
+		return ErrIncorrectBidOrder
+	}
+	return nil
+}
+
+// Deal deals the next hand; pos must be the dealer.
+func (e *Engine) Deal(pos int) error {
+	defer e.updatePhase()
+
+	if e.phase() != dealingPhase {
+		return ErrNotDealing
+	}
+	if pos != e.DealerPos() {
+		return ErrIncorrectDealer
+	}
+	return e.startHand()
+}
+
+// Pass passes a card from the hand of the player in pos.
+func (e *Engine) Pass(pos int, card deck.Card) error {
+	defer e.updatePhase()
+
+	// Rule check.
+	if !e.rules.PassCard() {
+		return ErrPassingNotAllowed
+	}
+
+	if e.phase() != passingPhase {
+		return ErrNotPassing
+	}
+
+	// Does this player have this card to pass?
+	playerHand, err := e.currentHands.Hand(pos)
+	if err != nil {
+		return err
+	}
+	if !playerHand.Contains(card) {
+		return ErrMissingCard
+	}
+
+	// Pass the card - remove from this player's hand, add to the passed cards.
+	if err := e.passedCards.passCard(pos, card); err != nil {
+		return err
+	}
+	if err := playerHand.removeCard(card); err != nil {
+		return err
+	}
+
+	// Last card passed, exchange the cards, move to bidding (or calling, if passing after the auction).
+	if e.passedCards.IsDone() {
+		for _, fromPos := range e.passedCards.Passers() {
+			cards, err := e.passedCards.FromPlayer(fromPos)
+			if err != nil {
+				return err
+			}
+			toHand, err := e.currentHands.Hand(e.passedCards.ToPos(fromPos))
+			if err != nil {
+				return err
+			}
+			for _, card := range cards {
+				if err := toHand.addCard(card); err != nil {
+					return err
+				}
+			}
+		}
+		if e.passesAfterAuction() {
+			if err := e.skipCallingForNoTrump(); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Misdeal claims a misdeal for the hand of the player in pos; the same dealer deals again.
+func (e *Engine) Misdeal(pos int) error {
+	defer e.updatePhase()
+
+	// Rule check.
+	if e.rules.Misdeal() == NoMisdeals {
+		return ErrMisdealNotAllowed
+	}
+
+	note, err := e.checkMisdeal(pos)
+	if err != nil {
+		return err
+	}
+	return e.redeal(TeamOf(pos, e.rules.Players()), note, true)
+}
+
+// checkMisdeal returns the score note for a misdeal claimed by the player at pos, or an error if the claim is not allowed now.
+func (e *Engine) checkMisdeal(pos int) (string, error) {
+	switch e.phase() {
+	case dealingPhase:
+		// The next hand has not been dealt, so there is nothing to claim.
+		return "", ErrInvalidMisdeal
+	case passingPhase, biddingPhase:
+	default:
+		return "", ErrMisdealTooLate
+	}
+
+	// The claim is judged on the hand as dealt, so it must be made before passing or bidding.
+	if e.passesBeforeBidding() && e.passedCards.NumPassedBy(pos) > 0 {
+		return "", ErrMisdealTooLate
+	}
+	for _, bidPos := range e.bidderPositions() {
+		if bidPos == pos {
+			return "", ErrMisdealTooLate
+		}
+	}
+
+	playerHand, err := e.currentHands.Hand(pos)
+	if err != nil {
+		return "", err
+	}
+	note, ok := misdealNote(e.rules.Misdeal(), playerHand)
+	if !ok {
+		return "", ErrInvalidMisdeal
+	}
+	return note, nil
+}
+
+// Bid places a bid for the player in pos.
+func (e *Engine) Bid(pos int, bid Bid) error {
+	defer e.updatePhase()
+
+	if err := e.checkBidTurn(pos); err != nil {
+		return err
+	}
+	// Is bid in available bids?
+	if !isNextBidValue(e.rules, e.currentBidding.Bids(), pos == e.currentDealerPos, bid) {
+		return ErrInvalidBid
+	}
+
+	if err := e.currentBidding.placeBid(pos, bid); err != nil {
+		return err
+	}
+
+	// If everyone passed, throw the hand in and deal again.
+	if e.currentBidding.AllPassed() {
+		sameDealer := e.rules.AllPass() == RedealSameDealer
+		note := "all passed, next dealer deals"
+		if sameDealer {
+			note = "all passed, same dealer deals"
+		}
+		return e.redeal(TeamOf(e.currentDealerPos, e.rules.Players()), note, sameDealer)
+	}
+
+	// If we have all the bids, start playing.
+	if e.currentBidding.IsDone() {
+		// The bid winner and their partner exchange cards before trump is called.
+		if e.passesAfterAuction() {
+			_, pos, err := e.currentBidding.WinningBidAndPos()
+			if err != nil {
+				return err
+			}
+			e.passedCards, err = newPartnerExchange(pos, e.rules.PassCount(), e.rules.Players())
+			return err
+		}
+		return e.skipCallingForNoTrump()
+	}
+	return nil
+}
+
+// skipCallingForNoTrump starts playing if the winning bid is no trump, skipping past trump selection.
+func (e *Engine) skipCallingForNoTrump() error {
+	bid, pos, err := e.currentBidding.WinningBidAndPos()
+	if err != nil {
+		return err
+	}
+	if !bid.IsNoTrump() {
+		return nil
+	}
+	return e.startTrick(deck.NoTrump, pos)
+}
+
+// Call calls trump for the player in pos, who must have won the bidding.
+func (e *Engine) Call(pos int, trump deck.Suit) error {
+	defer e.updatePhase()
+
+	if e.phase() != callingPhase {
+		return ErrNotCalling
+	}
+
+	// Is this the right player to set trump?
+	_, winningPos, err := e.currentBidding.WinningBidAndPos()
+	if err != nil {
+		return err
+	}
+	if pos != winningPos {
+		return ErrIncorrectCaller
+	}
+
+	return e.startTrick(trump, winningPos)
+}
+
+// Play plays a card from the hand of the player in pos into the current trick.
+func (e *Engine) Play(pos int, card deck.Card) error {
+	defer e.updatePhase()
+
+	if e.phase() != playingPhase {
+		return ErrNotPlaying
+	}
+
+	playerHand, err := e.currentHands.Hand(pos)
+	if err != nil {
+		return err
+	}
+
+	// Does the player have the card to play?
+	if !playerHand.Contains(card) {
+		return ErrMissingCard
+	}
+
+	// Is the card a valid card to play (i.e., does it follow suit)?
+	if !e.followsSuit(playerHand, card) {
+		return ErrNotFollowingSuit
+	}
+
+	// Remove the card from the player hand.
+	if err := playerHand.removeCard(card); err != nil {
+		return err
+	}
+
+	// Add the card to the current trick
+	if err := e.currentTrick.playCard(pos, card); err != nil {
+		return err
+	}
+
+	if !e.currentTrick.IsDone() {
+		return nil
+	}
+
+	// The last card; compute the results. Who won the trick?
+	winningPos, err := e.currentTrick.WinningPos()
+	if err != nil {
+		return err
+	}
+
+	// Add the trick to the tally.
+	if e.currentTally == nil {
+		e.currentTally = newTallyForTeams(NumTeams(e.rules.Players()))
+	}
+	if err := e.currentTally.addTrick(e.currentTrick); err != nil {
+		return err
+	}
+
+	// Store the last trick for player reference; the next trick is a new one, so it is not copied.
+	e.lastTrick = e.currentTrick
+	e.tricks = append(e.tricks, e.lastTrick)
+
+	// If all cards are played, update the score.
+	someHand, err := e.currentHands.Hand(0)
+	if err != nil {
+		return err
+	}
+	if !someHand.IsEmpty() {
+		return e.startTrick(e.currentTrick.Trump(), winningPos)
+	}
+
+	if e.score == nil {
+		e.score = newScoreForRules(e.rules)
+	}
+	hasWinner, err := e.score.addTally(e.rules, e.currentBidding, e.currentTally)
+	if err != nil {
+		return err
+	}
+	hand, err := e.handRecord(e.currentTrick.Trump())
+	if err != nil {
+		return err
+	}
+	if err := e.score.addHand(hand); err != nil {
+		return err
+	}
+
+	if hasWinner {
+		e.complete = true
+	}
+	e.currentTrick = nil
+	// This will fall-through to DealingState.
+	return nil
+}
+
+func (e *Engine) startHand() error {
+	players := e.rules.Players()
+	if len(e.queued) > 0 {
+		dealt := e.queued[0]
+		e.queued = e.queued[1:]
+		e.currentHands = dealt.Hands.clone()
+		e.currentSeed = dealt.Seed
+		e.currentDealVersion = dealt.DealVersion
+		e.currentDealerPos = dealt.DealerPos
+	} else {
+		seed := e.nextSeed()
+		hands, err := DealHands(players, seed, CurrentDealVersion)
+		if err != nil {
+			return err
+		}
+		e.currentHands = hands
+		e.currentSeed = seed
+		e.currentDealVersion = CurrentDealVersion
+		e.currentDealerPos = (e.currentDealerPos + 1) % players
+	}
+	e.handsDealt++
+
+	leadBidder := (e.currentDealerPos + 1) % players // to the left of the dealer
+
+	handNum := 0
+	if e.score != nil {
+		handNum = len(e.score.Scores())
+	}
+	passingRound, err := newPassingRoundForRules(e.rules, leadBidder, handNum)
+	if err != nil {
+		return err
+	}
+	e.passedCards = passingRound
+
+	biddingRound, err := newBiddingRoundForPlayers(leadBidder, players)
+	if err != nil {
+		return err
+	}
+	e.currentBidding = biddingRound
+
+	e.currentTrick = nil
+	e.tricks = make([]Trick, 0, deck.CardsPerHand)
+	e.currentTally = newTallyForTeams(NumTeams(players))
+	e.updatePhase()
+	return nil
+}
+
+// redeal throws in the current hand, attaching the note to the team's score, and deals a new one.
+func (e *Engine) redeal(team int, note string, sameDealer bool) error {
+	if e.score == nil {
+		e.score = newScoreForRules(e.rules)
+	}
+	if err := e.score.addRedeal(team, note); err != nil {
+		return err
+	}
+	hand, err := e.handRecord(nil)
+	if err != nil {
+		return err
+	}
+	if err := e.score.addHand(hand); err != nil {
+		return err
+	}
+	if sameDealer {
+		// startHand moves the deal to the left; step back so the same dealer deals again.
+		e.currentDealerPos = (e.currentDealerPos + e.rules.Players() - 1) % e.rules.Players()
+	}
+	return e.startHand()
+}
+
+// handRecord builds the breakdown of the current hand, played with the trump suit; a nil trump means the hand was thrown in.
+func (e *Engine) handRecord(trump deck.Suit) (HandRecord, error) {
+	hand := HandRecord{
+		DealerPos:         e.currentDealerPos,
+		BidLeadPos:        e.currentBidding.LeadPos(),
+		Bids:              append([]Bid(nil), e.currentBidding.Bids()...),
+		BidderPos:         -1,
+		Tricks:            append([]int(nil), e.currentTally.Tricks()...),
+		Points:            append([]int(nil), e.currentTally.Points()...),
+		FiveOfHeartsTeam:  e.currentTally.FiveOfHeartsTeam(),
+		ThreeOfSpadesTeam: e.currentTally.ThreeOfSpadesTeam(),
+		Seed:              e.currentSeed,
+		DealVersion:       e.currentDealVersion,
+	}
+	if trump == nil {
+		return hand, nil
+	}
+	bid, pos, err := e.currentBidding.WinningBidAndPos()
+	if err != nil {
+		return HandRecord{}, err
+	}
+	hand.BidderPos = pos
+	hand.WinningBid = bid
+	hand.Trump = trump
+	return hand, nil
+}
+
+func (e *Engine) startTrick(trump deck.Suit, leadPos int) error {
+	// The tricks of a hand are allocated together, as a hand is played out.
+	if len(e.trickStore) == 0 {
+		e.trickStore = make([]trick, deck.CardsPerHand)
+	}
+	t := &e.trickStore[0]
+	if err := t.reset(trump, leadPos, e.rules.Players()); err != nil {
+		return err
+	}
+	e.trickStore = e.trickStore[1:]
+	e.currentTrick = t
+	return nil
+}
+
+// bidderPositions returns the positions of the players that have placed a bid in the current bidding round.
+func (e *Engine) bidderPositions() []int {
+	var positions []int
+	for i := range e.currentBidding.Bids() {
+		positions = append(positions, (e.currentBidding.LeadPos()+i)%e.rules.Players())
+	}
+	return positions
+}
+
+// passesBeforeBidding returns true if the rules have every player pass cards before bidding.
+func (e *Engine) passesBeforeBidding() bool {
+	return e.rules != nil && e.rules.PassCard() && e.rules.PassTiming() == PassBeforeBidding
+}
+
+// passesAfterAuction returns true if the rules have the bid winner and their partner exchange cards after bidding.
+func (e *Engine) passesAfterAuction() bool {
+	return e.rules != nil && e.rules.PassCard() && e.rules.PassTiming() == PassAfterAuction
+}
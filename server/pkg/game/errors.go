@@ -0,0 +1,78 @@
+package game
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrorCode is a stable, machine-readable kind of game error. Clients should branch on the code, not the message.
+type ErrorCode string
+
+const (
+	CodeNotFound             ErrorCode = "NOT_FOUND"
+	CodeNotInGame            ErrorCode = "NOT_IN_GAME"
+	CodeInvalidPosition      ErrorCode = "INVALID_POSITION"
+	CodePositionFilled       ErrorCode = "POSITION_FILLED"
+	CodeAlreadyJoined        ErrorCode = "ALREADY_JOINED"
+	CodeNotYourTurn          ErrorCode = "NOT_YOUR_TURN"
+	CodeNotDealing           ErrorCode = "NOT_DEALING"
+	CodeNotPassing           ErrorCode = "NOT_PASSING"
+	CodeNotBidding           ErrorCode = "NOT_BIDDING"
+	CodeNotCalling           ErrorCode = "NOT_CALLING"
+	CodeNotPlaying           ErrorCode = "NOT_PLAYING"
+	CodeInvalidBid           ErrorCode = "INVALID_BID"
+	CodeCardNotInHand        ErrorCode = "CARD_NOT_IN_HAND"
+	CodeMustFollowSuit       ErrorCode = "MUST_FOLLOW_SUIT"
+	CodeAlreadyPassed        ErrorCode = "ALREADY_PASSED"
+	CodePassingNotAllowed    ErrorCode = "PASSING_NOT_ALLOWED"
+	CodeMisdealNotAllowed    ErrorCode = "MISDEAL_NOT_ALLOWED"
+	CodeMisdealTooLate       ErrorCode = "MISDEAL_TOO_LATE"
+	CodeInvalidMisdeal       ErrorCode = "INVALID_MISDEAL"
+	CodeConflict             ErrorCode = "GAME_CONFLICT"
+	CodeUnknownPreset        ErrorCode = "UNKNOWN_PRESET"
+	CodeInvalidRules         ErrorCode = "INVALID_RULES"
+	CodeNothingToUndo        ErrorCode = "NOTHING_TO_UNDO"
+	CodeUndoTooLate          ErrorCode = "UNDO_TOO_LATE"
+	CodeUndoPending          ErrorCode = "UNDO_PENDING"
+	CodeNoUndoRequest        ErrorCode = "NO_UNDO_REQUEST"
+	CodeUndoRequester        ErrorCode = "UNDO_REQUESTER"
+	CodeAlreadyApproved      ErrorCode = "ALREADY_APPROVED_UNDO"
+	CodeNotJoining           ErrorCode = "NOT_JOINING"
+	CodeNotInProgress        ErrorCode = "NOT_IN_PROGRESS"
+	CodeNotOrganizer         ErrorCode = "NOT_ORGANIZER"
+	CodeOrganizerCannotLeave ErrorCode = "ORGANIZER_CANNOT_LEAVE"
+	CodeSeatSwapPending      ErrorCode = "SEAT_SWAP_PENDING"
+	CodeNoSeatSwap           ErrorCode = "NO_SEAT_SWAP"
+	CodeUnknownStrategy      ErrorCode = "UNKNOWN_STRATEGY"
+	CodeNoMove               ErrorCode = "NO_MOVE"
+)
+
+// Error is a game error with a stable code. The Err* values are all Errors.
+type Error struct {
+	// Code is the kind of error.
+	Code ErrorCode
+	msg  string
+}
+
+// newError returns an error of the kind code, with the human-readable message.
+func newError(code ErrorCode, msg string) error {
+	return &Error{Code: code, msg: msg}
+}
+
+func (e *Error) Error() string {
+	return e.msg
+}
+
+// CodeOf returns the code of the first game Error in err's chain, or the empty string if there is none.
+func CodeOf(err error) ErrorCode {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Code
+	}
+	return ""
+}
+
+// invalidRules returns an error with the CodeInvalidRules code.
+func invalidRules(format string, args ...interface{}) error {
+	return newError(CodeInvalidRules, fmt.Sprintf(format, args...))
+}
@@ -0,0 +1,44 @@
+package game
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestCodeOf(t *testing.T) {
+	invalidRules := NewRules()
+	invalidRules.SetPlayers(7)
+
+	testCases := []struct {
+		name string
+		err  error
+		want ErrorCode
+	}{
+		{name: "nil", err: nil, want: ""},
+		{name: "not a game error", err: errors.New("boom"), want: ""},
+		{name: "must follow suit", err: ErrNotFollowingSuit, want: CodeMustFollowSuit},
+		{name: "card not in hand", err: ErrMissingCard, want: CodeCardNotInHand},
+		{name: "out of turn", err: ErrIncorrectPlayOrder, want: CodeNotYourTurn},
+		{name: "wrapped", err: fmt.Errorf("playing card: %w", ErrNotPlaying), want: CodeNotPlaying},
+		{name: "invalid rules", err: invalidRules.Validate(), want: CodeInvalidRules},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := CodeOf(tc.err); got != tc.want {
+				t.Errorf("CodeOf(%v)=%q want=%q", tc.err, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestPlayerPosNotInGame(t *testing.T) {
+	g, _, playerStore := buildGame(t, legacyGame())
+
+	_, err := g.PlayerPos(getPlayer(t, playerStore, "NOTINGAME"))
+
+	if err != ErrNotInGame {
+		t.Errorf("PlayerPos() error=%v want=%v", err, ErrNotInGame)
+	}
+}
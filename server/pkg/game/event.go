@@ -0,0 +1,228 @@
+package game
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/squee1945/threespot/server/pkg/deck"
+	"github.com/squee1945/threespot/server/pkg/storage"
+)
+
+// Action is the kind of an Event.
+type Action string
+
+func (a Action) String() string {
+	return string(a)
+}
+
+var (
+	CreateAction  Action = "CREATE"  // The organizer created the game.
+	JoinAction    Action = "JOIN"    // A player took a seat.
+	DealAction    Action = "DEAL"    // The dealer dealt the next hand.
+	DealtAction   Action = "DEALT"   // Cards were dealt; generated by the game, with the encoded hands and shuffle seed as payload.
+	PassAction    Action = "PASS"    // A player passed a card; the payload is the card.
+	MisdealAction Action = "MISDEAL" // A player claimed a misdeal.
+	BidAction     Action = "BID"     // A player placed a bid; the payload is the bid.
+	TrumpAction   Action = "TRUMP"   // The bid winner called trump; the payload is the suit.
+	PlayAction    Action = "PLAY"    // A player played a card; the payload is the card.
+	TimeoutAction Action = "TIMEOUT" // A player ran out of time; generated by the game, and followed by the action taken for them.
+
+	LeaveAction           Action = "LEAVE"        // A player left their seat before the game started.
+	MoveAction            Action = "MOVE"         // A player moved to an empty seat before the game started; the payload is the new position.
+	SeatSwapRequestAction Action = "SWAP_REQUEST" // A player asked to swap seats; the payload is the position asked for.
+	SeatSwapApproveAction Action = "SWAP_APPROVE" // The player asked swapped seats; the payload is the position of the requester.
+	SeatSwapRejectAction  Action = "SWAP_REJECT"  // The player asked refused to swap seats; the payload is the position of the requester.
+	ReplaceAction         Action = "REPLACE"      // The organizer handed the seat at Pos to a substitute; the payload is the substitute's player ID.
+
+	UndoRequestAction Action = "UNDO_REQUEST" // A player asked to take back their last action; the payload is the Seq of its event.
+	UndoApproveAction Action = "UNDO_APPROVE" // A player approved the undo request; the payload is the Seq of the event to take back.
+	UndoRejectAction  Action = "UNDO_REJECT"  // A player rejected the undo request; the payload is the Seq of the event to take back.
+	UndoAction        Action = "UNDO"         // The action, and those after it, were taken back; generated by the game, with the Seq of its event as payload.
+)
+
+// Event is an accepted action, as recorded in the game's append-only log.
+type Event struct {
+	// Seq is the position of the event in the log, starting at 1.
+	Seq int
+	// Action is the action taken.
+	Action Action
+	// PlayerID is the ID of the acting player; empty for events generated by the game.
+	PlayerID string
+	// Pos is the seat of the acting player, the dealer for DealtAction, or the seat handed over for ReplaceAction.
+	Pos int
+	// Payload is the encoded details of the action (e.g., "7N" for a bid, "5H" for a card).
+	Payload string
+	// Created is when the action was accepted.
+	Created time.Time
+}
+
+// GetEvents fetches the action log of the game, oldest first.
+func GetEvents(ctx context.Context, gameStore storage.GameStore, id string) ([]Event, error) {
+	ess, err := gameStore.Events(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("fetching events from storage: %v", err)
+	}
+	var events []Event
+	for _, es := range ess {
+		events = append(events, eventFromStorage(es))
+	}
+	return events, nil
+}
+
+// ReplayGame rebuilds the game by replaying its action log from the start, without modifying the stored game.
+// If upTo is positive, only the events up to and including that Seq are replayed (along with the cards dealt by the last of them).
+func ReplayGame(ctx context.Context, gameStore storage.GameStore, playerStore storage.PlayerStore, id string, upTo int) (Game, error) {
+	gs, err := gameStore.Get(ctx, id)
+	if err != nil {
+		if err == storage.ErrNotFound {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("fetching game from storage: %v", err)
+	}
+	events, err := GetEvents(ctx, gameStore, id)
+	if err != nil {
+		return nil, err
+	}
+	if upTo > 0 {
+		end := 0
+		for end < len(events) && (events[end].Seq <= upTo || events[end].Action == DealtAction) {
+			end++
+		}
+		events = events[:end]
+	}
+	return replay(ctx, storage.NewFakeGameStore(nil), playerStore, id, rulesFromStorage(gs.Rules), events)
+}
+
+// replay applies the events to a new game in the gameStore. Cards are dealt from the DealtAction events, rather than shuffled.
+// Actions that were taken back are skipped.
+func replay(ctx context.Context, gameStore storage.GameStore, playerStore storage.PlayerStore, id string, rules Rules, events []Event) (Game, error) {
+	if len(events) == 0 || events[0].Action != CreateAction {
+		return nil, fmt.Errorf("game %q has no create event to replay from", id)
+	}
+
+	organizer, err := GetPlayer(ctx, playerStore, events[0].PlayerID)
+	if err != nil {
+		return nil, fmt.Errorf("looking up organizer %q: %v", events[0].PlayerID, err)
+	}
+	created, err := NewGame(ctx, gameStore, playerStore, id, organizer, rules)
+	if err != nil {
+		return nil, fmt.Errorf("replaying event %d (%s): %v", events[0].Seq, events[0].Action, err)
+	}
+	undone := undoneSeqs(events)
+	for _, e := range events {
+		if e.Action != DealtAction || undone[e.Seq] {
+			continue
+		}
+		hands, seed, version, err := decodeDealt(e.Payload)
+		if err != nil {
+			return nil, fmt.Errorf("event %d: dealing recorded hands: %v", e.Seq, err)
+		}
+		created.(*game).QueueDeals(Dealt{DealerPos: e.Pos, Hands: hands, Seed: seed, DealVersion: version})
+	}
+
+	g := created
+	for _, e := range events[1:] {
+		if e.Action == DealtAction || e.Action == TimeoutAction || e.Action.isUndo() || undone[e.Seq] {
+			continue
+		}
+		player, err := GetPlayer(ctx, playerStore, e.PlayerID)
+		if err != nil {
+			return nil, fmt.Errorf("event %d: looking up player %q: %v", e.Seq, e.PlayerID, err)
+		}
+
+		switch e.Action {
+		case JoinAction:
+			g, err = g.AddPlayer(ctx, player, e.Pos)
+		case LeaveAction:
+			g, err = g.LeaveGame(ctx, player)
+		case MoveAction, SeatSwapRequestAction:
+			var pos int
+			if pos, err = strconv.Atoi(e.Payload); err == nil {
+				g, err = g.ChangeSeat(ctx, player, pos)
+			}
+		case SeatSwapApproveAction, SeatSwapRejectAction:
+			g, err = g.RespondToSeatSwap(ctx, player, e.Action == SeatSwapApproveAction)
+		case ReplaceAction:
+			var substitute Player
+			if substitute, err = GetPlayer(ctx, playerStore, e.Payload); err == nil {
+				g, err = g.ReplacePlayer(ctx, player, e.Pos, substitute)
+			}
+		case DealAction:
+			g, err = g.DealCards(ctx, player)
+		case PassAction:
+			var card deck.Card
+			if card, err = deck.NewCardFromEncoded(e.Payload); err == nil {
+				g, err = g.PassCard(ctx, player, card)
+			}
+		case MisdealAction:
+			g, err = g.ClaimMisdeal(ctx, player)
+		case BidAction:
+			var bid Bid
+			if bid, err = NewBidFromEncoded(e.Payload); err == nil {
+				g, err = g.PlaceBid(ctx, player, bid)
+			}
+		case TrumpAction:
+			var trump deck.Suit
+			if trump, err = deck.NewSuitFromEncoded(e.Payload); err == nil {
+				g, err = g.CallTrump(ctx, player, trump)
+			}
+		case PlayAction:
+			var card deck.Card
+			if card, err = deck.NewCardFromEncoded(e.Payload); err == nil {
+				g, err = g.PlayCard(ctx, player, card)
+			}
+		default:
+			err = fmt.Errorf("unknown action %q", e.Action)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("replaying event %d (%s): %v", e.Seq, e.Action, err)
+		}
+	}
+	return g, nil
+}
+
+// record adds an accepted action to the events that will be stored with the next save.
+// Any action other than an undo response withdraws a pending undo request, as it is no longer the last action.
+func (g *game) record(action Action, player Player, pos int, payload string) {
+	if !action.isUndo() {
+		g.undoRequest = nil
+	}
+	g.pending = append(g.pending, newEvent(action, player, pos, payload))
+}
+
+// newEvent builds an event for the action taken now; player is nil for events generated by the game.
+func newEvent(action Action, player Player, pos int, payload string) Event {
+	playerID := ""
+	if player != nil {
+		playerID = player.ID()
+	}
+	return Event{Action: action, PlayerID: playerID, Pos: pos, Payload: payload, Created: time.Now().UTC()}
+}
+
+func storageFromEvents(events []Event) []*storage.GameEvent {
+	var ess []*storage.GameEvent
+	for _, e := range events {
+		ess = append(ess, &storage.GameEvent{
+			Seq:      e.Seq,
+			Action:   string(e.Action),
+			PlayerID: e.PlayerID,
+			Pos:      e.Pos,
+			Payload:  e.Payload,
+			Created:  e.Created,
+		})
+	}
+	return ess
+}
+
+func eventFromStorage(es *storage.GameEvent) Event {
+	return Event{
+		Seq:      es.Seq,
+		Action:   Action(es.Action),
+		PlayerID: es.PlayerID,
+		Pos:      es.Pos,
+		Payload:  es.Payload,
+		Created:  es.Created,
+	}
+}
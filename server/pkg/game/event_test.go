@@ -0,0 +1,135 @@
+package game
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/squee1945/threespot/server/pkg/storage"
+)
+
+func TestReplayGame(t *testing.T) {
+	ctx := context.Background()
+	gameStore := storage.NewFakeGameStore(nil)
+	playerStore := storage.NewFakePlayerStore()
+	rules := NewRules()
+	rules.SetPassCard(true)
+
+	var ps []Player
+	for i := 0; i < 4; i++ {
+		ps = append(ps, buildPlayer(t, playerStore, fmt.Sprintf("PLAYER%d", i)))
+	}
+	g, err := NewGame(ctx, gameStore, playerStore, "ABC123", ps[0], rules)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for pos := 1; pos < 4; pos++ {
+		if g, err = g.AddPlayer(ctx, ps[pos], pos); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Play two full hands, and into the third.
+	for hands := 0; hands < 3; {
+		if g.State() == DealingState {
+			hands++
+		}
+		g = playNextAction(t, ctx, g, ps)
+	}
+
+	events, err := GetEvents(ctx, gameStore, g.ID())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := events[0].Action, CreateAction; got != want {
+		t.Errorf("events[0].Action=%v want=%v", got, want)
+	}
+	if got, want := events[4].Action, DealtAction; got != want {
+		t.Errorf("events[4].Action=%v want=%v", got, want)
+	}
+	for i, e := range events {
+		if got, want := e.Seq, i+1; got != want {
+			t.Errorf("events[%d].Seq=%d want=%d", i, got, want)
+		}
+	}
+
+	replayed, err := ReplayGame(ctx, gameStore, playerStore, g.ID(), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ignoreTimes := cmpopts.IgnoreFields(storage.Game{}, "Created", "Updated")
+	if diff := cmp.Diff(legacyStorage(t, g.(*game)), legacyStorage(t, replayed.(*game)), ignoreTimes); diff != "" {
+		t.Errorf("replayed game mismatch (-want +got):\n%s", diff)
+	}
+
+	// Replaying the first joins deals the first hand.
+	partial, err := ReplayGame(ctx, gameStore, playerStore, g.ID(), 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := partial.State(), PassingState; got != want {
+		t.Errorf("State()=%v want=%v", got, want)
+	}
+	dealt, _, _, err := decodeDealt(events[4].Payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for pos, player := range ps {
+		got, err := partial.PlayerHand(player)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want, err := dealt.Hand(pos)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// Cards() sorts the hand, so that the encodings can be compared.
+		want.Cards()
+		got.Cards()
+		if got, want := got.Encoded(), want.Encoded(); got != want {
+			t.Errorf("hand %d=%q want=%q", pos, got, want)
+		}
+	}
+}
+
+func TestReplayGameNotFound(t *testing.T) {
+	ctx := context.Background()
+	if _, err := ReplayGame(ctx, storage.NewFakeGameStore(nil), storage.NewFakePlayerStore(), "ABC123", 0); err != ErrNotFound {
+		t.Errorf("ReplayGame()=%v want=%v", err, ErrNotFound)
+	}
+}
+
+// playNextAction takes the first legal action for the player whose turn it is.
+func playNextAction(t *testing.T, ctx context.Context, g Game, ps []Player) Game {
+	t.Helper()
+	pos, err := g.PosToPlay()
+	if err != nil {
+		t.Fatal(err)
+	}
+	player := ps[pos]
+	actions, err := g.LegalActions(player)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	switch {
+	case actions.Deal:
+		g, err = g.DealCards(ctx, player)
+	case len(actions.Pass) > 0:
+		g, err = g.PassCard(ctx, player, actions.Pass[0])
+	case len(actions.Bids) > 0:
+		g, err = g.PlaceBid(ctx, player, actions.Bids[len(actions.Bids)/2])
+	case len(actions.Trumps) > 0:
+		g, err = g.CallTrump(ctx, player, buildSuit(t, "S"))
+	case len(actions.Play) > 0:
+		g, err = g.PlayCard(ctx, player, actions.Play[0])
+	default:
+		t.Fatalf("no legal action in state %v", g.State())
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	return g
+}
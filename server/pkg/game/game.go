@@ -30,38 +30,79 @@ type Game interface {
 	CurrentTrick() Trick
 	LastTrick() Trick
 	AvailableBids(Player) ([]Bid, error)
+	// LegalCards returns the cards the player may play into the current trick.
+	LegalCards(Player) ([]deck.Card, error)
+	// LegalActions returns what the player may do now; it is empty if the player has nothing to do.
+	LegalActions(Player) (LegalActions, error)
 
 	AddPlayer(ctx context.Context, player Player, pos int) (Game, error)
 	DealCards(ctx context.Context, player Player) (Game, error)
 	PassCard(ctx context.Context, player Player, card deck.Card) (Game, error)
+	ClaimMisdeal(ctx context.Context, player Player) (Game, error)
 	PlaceBid(ctx context.Context, player Player, bid Bid) (Game, error)
 	CallTrump(ctx context.Context, player Player, trump deck.Suit) (Game, error)
 	PlayCard(ctx context.Context, player Player, card deck.Card) (Game, error)
 	UpdateVersion(ctx context.Context) (Game, error)
 
+	// TurnDeadline returns when the player to play runs out of time; it is zero if there is no turn limit.
+	TurnDeadline() time.Time
+	// EnforceTurnClock acts for the player to play if they ran out of time by now, as the SimpleStrategy would
+	// (dealing, passing or playing their lowest card, passing the bid or bidding the lowest, calling their longest suit).
+	EnforceTurnClock(ctx context.Context, now time.Time) (Game, error)
+	// PlayBots takes the turns of bot players until it is a person's turn; call it after each action.
+	PlayBots(ctx context.Context) (Game, error)
+
+	// UndoRequest returns the pending request to take back an action, or nil if there is none.
+	UndoRequest() *UndoRequest
+	// RequestUndo asks the other players to let the player take back their last pass, bid, trump call or card, along
+	// with the moves of bots and of players who ran out of time since.
+	RequestUndo(ctx context.Context, player Player) (Game, error)
+	// RespondToUndo approves or rejects the pending undo request; once every other player approves, the action is taken back.
+	RespondToUndo(ctx context.Context, player Player, approve bool) (Game, error)
+
+	// IsOrganizer returns true if the player created the game.
+	IsOrganizer(Player) bool
+	// SeatSwap returns the pending request to swap seats, or nil if there is none.
+	SeatSwap() *SeatSwap
+	// LeaveGame vacates the player's seat before the game starts; the organizer cannot leave.
+	LeaveGame(ctx context.Context, player Player) (Game, error)
+	// ChangeSeat moves the player to the position before the game starts. If the seat is taken, the player there is
+	// asked to swap seats, and must consent with RespondToSeatSwap.
+	ChangeSeat(ctx context.Context, player Player, pos int) (Game, error)
+	// RespondToSeatSwap approves or rejects the request to swap seats with the player.
+	RespondToSeatSwap(ctx context.Context, player Player, approve bool) (Game, error)
+	// ReplacePlayer hands the seat at the position to the substitute in a game in progress, who takes over its hand.
+	// Only the organizer can replace a player.
+	ReplacePlayer(ctx context.Context, organizer Player, pos int, substitute Player) (Game, error)
+
 	Rules() Rules
 }
 
 var (
-	ErrNotFound             = errors.New("Not found")
-	ErrInvalidPosition      = errors.New("Invalid position")
-	ErrPlayerPositionFilled = errors.New("Player position is already filled")
-	ErrPlayerAlreadyAdded   = errors.New("Player is already added")
-	ErrIncorrectBidOrder    = errors.New("Bidding out of order")
-	ErrIncorrectPassOrder   = errors.New("Passing out of order")
-	ErrIncorrectCaller      = errors.New("Player cannot call trump")
-	ErrIncorrectPlayOrder   = errors.New("Playing out of order")
-	ErrIncorrectDealer      = errors.New("You're not the dealer")
-	ErrInvalidBid           = errors.New("Invalid bid")
-	ErrNotDealing           = errors.New("Not currently dealing")
-	ErrNotPassing           = errors.New("Not currently passing")
-	ErrNotBidding           = errors.New("Not currently bidding")
-	ErrNotCalling           = errors.New("Not currently calling trump")
-	ErrNotPlaying           = errors.New("Not currently playing cards")
-	ErrMissingCard          = errors.New("Player does not have this card")
-	ErrNotFollowingSuit     = errors.New("Must follow lead suit")
-	ErrAlreadyPassed        = errors.New("Player has already passed a card")
-	ErrPassingNotAllowed    = errors.New("Passing not allowed by game rules")
+	ErrNotFound             = newError(CodeNotFound, "Not found")
+	ErrNotInGame            = newError(CodeNotInGame, "Player is not in the game")
+	ErrInvalidPosition      = newError(CodeInvalidPosition, "Invalid position")
+	ErrPlayerPositionFilled = newError(CodePositionFilled, "Player position is already filled")
+	ErrPlayerAlreadyAdded   = newError(CodeAlreadyJoined, "Player is already added")
+	ErrIncorrectBidOrder    = newError(CodeNotYourTurn, "Bidding out of order")
+	ErrIncorrectPassOrder   = newError(CodeNotYourTurn, "Passing out of order")
+	ErrIncorrectCaller      = newError(CodeNotYourTurn, "Player cannot call trump")
+	ErrIncorrectPlayOrder   = newError(CodeNotYourTurn, "Playing out of order")
+	ErrIncorrectDealer      = newError(CodeNotYourTurn, "You're not the dealer")
+	ErrInvalidBid           = newError(CodeInvalidBid, "Invalid bid")
+	ErrNotDealing           = newError(CodeNotDealing, "Not currently dealing")
+	ErrNotPassing           = newError(CodeNotPassing, "Not currently passing")
+	ErrNotBidding           = newError(CodeNotBidding, "Not currently bidding")
+	ErrNotCalling           = newError(CodeNotCalling, "Not currently calling trump")
+	ErrNotPlaying           = newError(CodeNotPlaying, "Not currently playing cards")
+	ErrMissingCard          = newError(CodeCardNotInHand, "Player does not have this card")
+	ErrNotFollowingSuit     = newError(CodeMustFollowSuit, "Must follow lead suit")
+	ErrAlreadyPassed        = newError(CodeAlreadyPassed, "Player has already passed a card")
+	ErrPassingNotAllowed    = newError(CodePassingNotAllowed, "Passing not allowed by game rules")
+	ErrMisdealNotAllowed    = newError(CodeMisdealNotAllowed, "Misdeal claims not allowed by game rules")
+	ErrMisdealTooLate       = newError(CodeMisdealTooLate, "Too late to claim a misdeal")
+	ErrInvalidMisdeal       = newError(CodeInvalidMisdeal, "Hand does not qualify for a misdeal")
+	ErrConflict             = newError(CodeConflict, "Game was changed by another player")
 )
 
 type GameState string
@@ -81,36 +122,33 @@ var (
 )
 
 type game struct {
+	*Engine // The state of play.
+
 	gameStore   storage.GameStore
 	playerStore storage.PlayerStore
 
-	id       string
-	players  []Player // Position 0/2 are a team, 1/3 are a team; organizer is position 0.
-	created  time.Time
-	updated  time.Time
-	complete bool
+	id      string
+	players []Player // Partners sit across the table (e.g., 0/2 and 1/3 with four players); nil for empty seats.
+
+	organizerID string // The player that created the game; they may have changed seats, or handed theirs over.
 
-	score Score // The score of the game.
+	created time.Time
+	updated time.Time
 
-	passedCards      PassingRound // Passed cards for current round.
-	currentDealerPos int          // The position of the current dealer.
-	currentBidding   BiddingRound // The bids for the current hand.
-	currentHands     Hands        // Cards held by each player, parallel with the PlayerIDs above.
-	currentTrick     Trick        // Cards played for current trick.
-	lastTrick        Trick        // Last trick played.
-	currentTally     Tally        // The running tally for the current hand.
+	turnDeadline time.Time // When the player to play runs out of time; zero if there is no turn limit.
 
-	rules Rules
+	undoRequest *UndoRequest // The pending request to take back an action; nil if none.
+	seatSwap    *SeatSwap    // The pending request to swap seats; nil if none.
+
+	pending []Event // Accepted actions, stored with the next save.
 }
 
 var _ Game = (*game)(nil) // Ensure interface is implemented.
 
 // NewGame creates a new game, storing it in the GameStore.
 func NewGame(ctx context.Context, gameStore storage.GameStore, playerStore storage.PlayerStore, id string, organizer Player, rules Rules) (Game, error) {
-	sr := storage.Rules{
-		PassCard: rules.PassCard(),
-	}
-	gs, err := gameStore.Create(ctx, id, organizer.ID(), sr)
+	created := newEvent(CreateAction, organizer, 0, "")
+	gs, err := gameStore.Create(ctx, id, organizer.ID(), storageFromRules(rules), storageFromEvents([]Event{created})...)
 	if err != nil {
 		return nil, err
 	}
@@ -162,26 +200,10 @@ func (g *game) Version() string {
 }
 
 func (g *game) State() GameState {
-	if g.complete {
-		return CompletedState
-	}
-	if g.playerCount() < 4 {
+	if !g.complete && g.playerCount() < g.rules.Players() {
 		return JoiningState
 	}
-	handCounts := g.HandCounts()
-	if handCounts[0] == 0 && handCounts[1] == 0 && handCounts[2] == 0 && handCounts[3] == 0 {
-		return DealingState
-	}
-	if g.rules != nil && g.rules.PassCard() && !g.passedCards.IsDone() {
-		return PassingState
-	}
-	if !g.currentBidding.IsDone() {
-		return BiddingState
-	}
-	if g.currentTrick == nil {
-		return CallingState
-	}
-	return PlayingState
+	return g.Engine.State()
 }
 
 func (g *game) Players() []Player {
@@ -190,85 +212,51 @@ func (g *game) Players() []Player {
 
 func (g *game) PlayerPos(player Player) (int, error) {
 	for pos, p := range g.Players() {
-		if p.ID() == player.ID() {
+		if p != nil && p.ID() == player.ID() {
 			return pos, nil
 		}
 	}
-	return -1, errors.New("unknown player")
-}
-
-func (g *game) PassedCards() PassingRound {
-	return g.passedCards
-}
-
-func (g *game) CurrentBidding() BiddingRound {
-	return g.currentBidding
-}
-
-func (g *game) CurrentTrick() Trick {
-	return g.currentTrick
-}
-
-func (g *game) LastTrick() Trick {
-	return g.lastTrick
-}
-
-func (g *game) DealerPos() int {
-	return g.currentDealerPos
+	return -1, ErrNotInGame
 }
 
 func (g *game) PosToPlay() (int, error) {
-	switch g.State() {
-	case DealingState:
-		return g.DealerPos(), nil
-	case PassingState:
-		return g.passedCards.CurrentTurnPos()
-	case BiddingState:
-		return g.currentBidding.CurrentTurnPos()
-	case CallingState:
-		_, pos, err := g.currentBidding.WinningBidAndPos()
-		if err != nil {
-			return 0, err
-		}
-		return pos, nil
-	case PlayingState:
-		pos, err := g.currentTrick.CurrentTurnPos()
-		if err != nil {
-			return 0, err
-		}
-		return pos, nil
+	if g.State() == JoiningState {
+		return -1, nil
 	}
-	return -1, nil
-}
-
-func (g *game) Tally() Tally {
-	return g.currentTally
-}
-
-func (g *game) Score() Score {
-	return g.score
+	return g.Engine.PosToPlay()
 }
 
 func (g *game) AvailableBids(player Player) ([]Bid, error) {
 	if g.State() != BiddingState {
 		return nil, ErrNotBidding
 	}
-	if g.currentBidding.IsDone() {
-		return nil, ErrNotBidding
-	}
 	pos, err := g.PlayerPos(player)
 	if err != nil {
 		return nil, err
 	}
-	// Is it this player's turn to bid?
-	currentTurnPos, err := g.currentBidding.CurrentTurnPos()
+	return g.Engine.AvailableBids(pos)
+}
+
+func (g *game) LegalCards(player Player) ([]deck.Card, error) {
+	if g.State() != PlayingState {
+		return nil, ErrNotPlaying
+	}
+	pos, err := g.PlayerPos(player)
 	if err != nil {
 		return nil, err
 	}
-	if pos != currentTurnPos {
-		return nil, ErrIncorrectBidOrder
+	return g.Engine.LegalCards(pos)
+}
+
+func (g *game) LegalActions(player Player) (LegalActions, error) {
+	pos, err := g.PlayerPos(player)
+	if err != nil {
+		return LegalActions{}, err
+	}
+	if g.State() == JoiningState {
+		return LegalActions{}, nil
 	}
-	return nextBidValues(g.currentBidding.Bids(), pos == g.currentDealerPos), nil
+	return g.Engine.LegalActions(pos)
 }
 
 func (g *game) PlayerHand(player Player) (Hand, error) {
@@ -279,25 +267,12 @@ func (g *game) PlayerHand(player Player) (Hand, error) {
 	return g.currentHands.Hand(pos)
 }
 
-func (g *game) HandCounts() []int {
-	def := []int{8, 8, 8, 8}
-	var counts []int
-	for pos := 0; pos < 4; pos++ {
-		hand, err := g.currentHands.Hand(pos)
-		if err != nil {
-			return def
-		}
-		counts = append(counts, len(hand.Cards()))
-	}
-	return counts
-}
-
-func (g *game) Rules() Rules {
-	return g.rules
-}
-
 func (g *game) AddPlayer(ctx context.Context, player Player, pos int) (Game, error) {
-	gs, err := g.gameStore.AddPlayer(ctx, g.id, player.ID(), pos)
+	if pos < 0 || pos >= len(g.players) {
+		return nil, ErrInvalidPosition
+	}
+	joined := newEvent(JoinAction, player, pos, "")
+	gs, err := g.gameStore.AddPlayer(ctx, g.id, player.ID(), pos, storageFromEvents([]Event{joined})...)
 	if err != nil {
 		if err == storage.ErrPlayerPositionFilled {
 			return nil, ErrPlayerPositionFilled
@@ -311,12 +286,17 @@ func (g *game) AddPlayer(ctx context.Context, player Player, pos int) (Game, err
 	if err != nil {
 		return nil, err
 	}
+	newG.queued = g.queued
+	newG.seeds = g.seeds
 
-	if newG.playerCount() == 4 {
-		newG.currentDealerPos = rand.Int() % 4 // Assign a random dealer.
+	if newG.playerCount() == newG.rules.Players() {
+		newG.seatSwap = nil                                       // The game is starting; seats are final.
+		newG.currentDealerPos = rand.Int() % newG.rules.Players() // Assign a random dealer.
+		handsDealt := newG.handsDealt
 		if err := newG.startHand(); err != nil {
 			return nil, fmt.Errorf("starting hand: %v", err)
 		}
+		newG.recordDeal(handsDealt)
 		newG, err = newG.save(ctx)
 		if err != nil {
 			return nil, fmt.Errorf("saving game: %v", err)
@@ -329,19 +309,13 @@ func (g *game) DealCards(ctx context.Context, player Player) (Game, error) {
 	if g.State() != DealingState {
 		return nil, ErrNotDealing
 	}
-
 	pos, err := g.PlayerPos(player)
 	if err != nil {
 		return nil, err
 	}
-	if pos != g.DealerPos() {
-		return nil, ErrIncorrectDealer
-	}
-
-	if err := g.startHand(); err != nil {
-		return nil, err
-	}
-	return g.save(ctx)
+	return g.apply(ctx, DealAction, player, pos, "", func() error {
+		return g.Deal(pos)
+	})
 }
 
 func (g *game) PassCard(ctx context.Context, player Player, card deck.Card) (Game, error) {
@@ -349,263 +323,95 @@ func (g *game) PassCard(ctx context.Context, player Player, card deck.Card) (Gam
 	if !g.Rules().PassCard() {
 		return nil, ErrPassingNotAllowed
 	}
-
 	if g.State() != PassingState {
 		return nil, ErrNotPassing
 	}
-
-	// Does this player have this card to pass?
 	pos, err := g.PlayerPos(player)
 	if err != nil {
 		return nil, err
 	}
-	playerHand, err := g.currentHands.Hand(pos)
-	if err != nil {
-		return nil, err
-	}
-	if !playerHand.Contains(card) {
-		return nil, ErrMissingCard
-	}
+	return g.apply(ctx, PassAction, player, pos, card.Encoded(), func() error {
+		return g.Pass(pos, card)
+	})
+}
 
-	// Pass the card - remove from this player's hand, add to the passed cards.
-	if err := g.passedCards.passCard(pos, card); err != nil {
-		return nil, err
+func (g *game) ClaimMisdeal(ctx context.Context, player Player) (Game, error) {
+	// Rule check.
+	if g.Rules().Misdeal() == NoMisdeals {
+		return nil, ErrMisdealNotAllowed
 	}
-	if err := playerHand.removeCard(card); err != nil {
-		return nil, err
+	// Nothing has been dealt until every seat is filled.
+	if g.State() == JoiningState {
+		return nil, ErrInvalidMisdeal
 	}
-
-	// Last card passed, exchange the cards, move to bidding.
-	if g.passedCards.IsDone() {
-		for i, card := range g.passedCards.Cards() {
-			pos := (g.passedCards.LeadPos() + i + 4) % 4
-			// Get the partner hand.
-			partnerPos := (pos + 2 + 4) % 4
-			partnerHand, err := g.currentHands.Hand(partnerPos)
-			if err != nil {
-				return nil, err
-			}
-			if err := partnerHand.addCard(card); err != nil {
-				return nil, err
-			}
-		}
+	pos, err := g.PlayerPos(player)
+	if err != nil {
+		return nil, err
 	}
-
-	return g.save(ctx)
+	return g.apply(ctx, MisdealAction, player, pos, "", func() error {
+		return g.Misdeal(pos)
+	})
 }
 
 func (g *game) PlaceBid(ctx context.Context, player Player, bid Bid) (Game, error) {
 	if g.State() != BiddingState {
 		return nil, ErrNotBidding
 	}
-
-	// Is bid in available bids?
-	found := false
-	available, err := g.AvailableBids(player)
-	if err != nil {
-		return nil, err
-	}
-	for _, ab := range available {
-		if ab.IsEqualTo(bid) {
-			found = true
-			break
-		}
-	}
-	if !found {
-		return nil, ErrInvalidBid
-	}
-
 	pos, err := g.PlayerPos(player)
 	if err != nil {
 		return nil, err
 	}
-	if err := g.currentBidding.placeBid(pos, bid); err != nil {
-		return nil, err
-	}
-
-	// If we have all the bids, start playing.
-	if g.currentBidding.IsDone() {
-		bid, pos, err := g.currentBidding.WinningBidAndPos()
-		if err != nil {
-			return nil, err
-		}
-		// If no-trump, we skip past trump selection.
-		if bid.IsNoTrump() {
-			if err := g.startTrick(deck.NoTrump, pos); err != nil {
-				return nil, err
-			}
-		}
-	}
-
-	return g.save(ctx)
+	return g.apply(ctx, BidAction, player, pos, bid.Encoded(), func() error {
+		return g.Bid(pos, bid)
+	})
 }
 
 func (g *game) CallTrump(ctx context.Context, player Player, trump deck.Suit) (Game, error) {
 	if g.State() != CallingState {
 		return nil, ErrNotCalling
 	}
-
-	// Is this the right player to set trump?
-	_, winningPos, err := g.currentBidding.WinningBidAndPos()
-	if err != nil {
-		return nil, err
-	}
 	pos, err := g.PlayerPos(player)
 	if err != nil {
 		return nil, err
 	}
-	if pos != winningPos {
-		return nil, ErrIncorrectCaller
-	}
-
-	if err := g.startTrick(trump, winningPos); err != nil {
-		return nil, err
-	}
-
-	return g.save(ctx)
+	return g.apply(ctx, TrumpAction, player, pos, trump.Encoded(), func() error {
+		return g.Call(pos, trump)
+	})
 }
 
 func (g *game) PlayCard(ctx context.Context, player Player, card deck.Card) (Game, error) {
 	if g.State() != PlayingState {
 		return nil, ErrNotPlaying
 	}
-
 	pos, err := g.PlayerPos(player)
 	if err != nil {
 		return nil, err
 	}
-
-	playerHand, err := g.currentHands.Hand(pos)
-	if err != nil {
-		return nil, err
-	}
-
-	// Does the player have the card to play?
-	if !playerHand.Contains(card) {
-		return nil, ErrMissingCard
-	}
-
-	// Is the card a valid card to play (i.e., does it follow suit)?
-	if g.currentTrick.NumPlayed() > 0 {
-		leadSuit, err := g.currentTrick.LeadSuit()
-		if err != nil {
-			return nil, err
-		}
-		if card.Suit() != leadSuit && playerHand.ContainsSuit(leadSuit, card) {
-			return nil, ErrNotFollowingSuit
-		}
-
-	}
-
-	// Remove the card from the player hand.
-	if err := playerHand.removeCard(card); err != nil {
-		return nil, err
-	}
-
-	// Add the card to the current trick
-	if err := g.currentTrick.playCard(pos, card); err != nil {
-		return nil, err
-	}
-
-	// If the last card, compute the results
-	if g.currentTrick.IsDone() {
-
-		// Who won the trick?
-		winningPos, err := g.currentTrick.WinningPos()
-		if err != nil {
-			return nil, err
-		}
-
-		// Add the trick to the tally.
-		if g.currentTally == nil {
-			g.currentTally = NewTally()
-		}
-		if err := g.currentTally.addTrick(g.currentTrick); err != nil {
-			return nil, err
-		}
-
-		// Store the last trick for player reference.
-		if g.currentTrick != nil {
-			lastTrick, err := NewTrickFromEncoded(g.currentTrick.Encoded())
-			if err != nil {
-				return nil, err
-			}
-			g.lastTrick = lastTrick
-		}
-
-		// If all cards are played, update the score.
-		someHand, err := g.currentHands.Hand(0)
-		if err != nil {
-			return nil, err
-		}
-		if someHand.IsEmpty() {
-			if g.score == nil {
-				g.score = NewScore()
-			}
-			hasWinner, err := g.score.addTally(g.currentBidding, g.currentTally)
-			if err != nil {
-				return nil, err
-			}
-
-			if hasWinner {
-				g.complete = true
-			}
-			g.currentTrick = nil
-			// This will fall-through to DealingState.
-
-		} else {
-			if err := g.startTrick(g.currentTrick.Trump(), winningPos); err != nil {
-				return nil, err
-			}
-		}
-	}
-
-	return g.save(ctx)
+	return g.apply(ctx, PlayAction, player, pos, card.Encoded(), func() error {
+		return g.Play(pos, card)
+	})
 }
 
 func (g *game) UpdateVersion(ctx context.Context) (Game, error) {
 	return g.save(ctx)
 }
 
-func (g *game) startHand() error {
-	deck, err := deck.NewDeck()
-	if err != nil {
-		return err
-	}
-	deck.Shuffle()
-
-	g.currentHands, err = NewHands(deck.Deal())
-	if err != nil {
-		return err
-	}
-	g.currentDealerPos = (g.currentDealerPos + 1) % 4
-
-	leadBidder := (g.currentDealerPos + 1) % 4 // to the left of the dealer
-
-	passingRound, err := NewPassingRound(leadBidder)
-	if err != nil {
-		return err
-	}
-	g.passedCards = passingRound
-
-	biddingRound, err := NewBiddingRound(leadBidder)
-	if err != nil {
-		return err
+// apply takes the player's action on the engine, then records it (and any hand it dealt) and saves the game.
+func (g *game) apply(ctx context.Context, action Action, player Player, pos int, payload string, take func() error) (Game, error) {
+	handsDealt := g.handsDealt
+	if err := take(); err != nil {
+		return nil, err
 	}
-	g.currentBidding = biddingRound
-
-	g.currentTrick = nil
-	g.currentTally = NewTally()
-	return nil
+	g.record(action, player, pos, payload)
+	g.recordDeal(handsDealt)
+	return g.save(ctx)
 }
 
-func (g *game) startTrick(trump deck.Suit, leadPos int) error {
-	trick, err := NewTrick(trump, leadPos)
-	if err != nil {
-		return err
+// recordDeal records the hand dealt by the engine, if it has dealt one since it had dealt handsDealt hands.
+func (g *game) recordDeal(handsDealt int) {
+	if g.handsDealt != handsDealt {
+		g.record(DealtAction, nil, g.currentDealerPos, encodeDealt(g.currentHands, g.currentSeed, g.currentDealVersion))
 	}
-	g.currentTrick = trick
-	return nil
 }
 
 func (g *game) playerCount() int {
@@ -618,16 +424,29 @@ func (g *game) playerCount() int {
 	return c
 }
 
+// save stores the game, returning ErrConflict if the stored game was changed after this one was read.
 func (g *game) save(ctx context.Context) (*game, error) {
-	g.updated = time.Now().UTC()
-	gs := storageFromGame(g)
-	if err := g.gameStore.Set(ctx, g.id, gs); err != nil {
+	lastUpdated, lastDeadline := g.updated, g.turnDeadline
+	// Storage keeps times to the microsecond; truncate so that the version matches once reloaded.
+	g.updated = time.Now().UTC().Truncate(time.Microsecond)
+	g.startTurnClock(g.updated)
+	gs, err := storageFromGame(g)
+	if err != nil {
+		g.updated, g.turnDeadline = lastUpdated, lastDeadline
+		return nil, err
+	}
+	if err := g.gameStore.Set(ctx, g.id, gs, lastUpdated, storageFromEvents(g.pending)...); err != nil {
+		g.updated, g.turnDeadline = lastUpdated, lastDeadline
+		if err == storage.ErrConflict {
+			return nil, ErrConflict
+		}
 		return nil, fmt.Errorf("saving game: %v", err)
 	}
+	g.pending = nil
 	return g, nil
 }
 
-func storageFromGame(g *game) *storage.Game {
+func storageFromGame(g *game) (*storage.Game, error) {
 	var playerIDs []string
 	for _, player := range g.players {
 		if player == nil {
@@ -637,49 +456,41 @@ func storageFromGame(g *game) *storage.Game {
 		playerIDs = append(playerIDs, player.ID())
 	}
 
-	var currentTrick, lastTrick string
-	if g.currentTrick != nil {
-		currentTrick = g.currentTrick.Encoded()
-	}
-	if g.lastTrick != nil {
-		lastTrick = g.lastTrick.Encoded()
-	}
-
-	// Convert rules into storage version.
-	sr := storage.Rules{}
-	if g.rules != nil && g.rules.PassCard() {
-		sr.PassCard = g.rules.PassCard()
+	state, err := encodeState(g)
+	if err != nil {
+		return nil, err
 	}
 
 	return &storage.Game{
-		PlayerIDs:        playerIDs,
-		Created:          g.created,
-		Updated:          g.updated,
-		Complete:         g.complete,
-		Score:            g.score.Encoded(),
-		CurrentDealerPos: g.currentDealerPos,
-		CurrentBidding:   g.currentBidding.Encoded(),
-		CurrentHands:     g.currentHands.Encoded(),
-		CurrentTrick:     currentTrick,
-		LastTrick:        lastTrick,
-		CurrentTally:     g.currentTally.Encoded(),
-		PassedCards:      g.passedCards.Encoded(),
-		Rules:            sr,
-	}
+		PlayerIDs:          playerIDs,
+		OrganizerID:        g.organizerID,
+		Created:            g.created,
+		Updated:            g.updated,
+		TurnDeadline:       g.turnDeadline,
+		Complete:           g.complete,
+		StateVersion:       stateVersion,
+		State:              state,
+		CurrentDealerPos:   g.currentDealerPos,
+		CurrentSeed:        g.currentSeed,
+		CurrentDealVersion: g.currentDealVersion,
+		Rules:              storageFromRules(g.rules),
+	}, nil
 }
 
 func gameFromStorage(ctx context.Context, gameStore storage.GameStore, playerStore storage.PlayerStore, id string, gs *storage.Game) (*game, error) {
 	if gs == nil {
 		return nil, errors.New("nil game")
 	}
+	rules := rulesFromStorage(gs.Rules)
+
 	// Multi-get the players
 	pss, err := playerStore.GetMulti(ctx, gs.PlayerIDs)
 	if err != nil {
 		return nil, fmt.Errorf("fetching player info from storage: %v", err)
 	}
-	players := make([]Player, 4)
+	players := make([]Player, rules.Players())
 	for i, ps := range pss {
-		if ps == nil {
+		if ps == nil || i >= len(players) {
 			continue
 		}
 		player, err := playerFromStorage(playerStore, gs.PlayerIDs[i], ps)
@@ -689,64 +500,48 @@ func gameFromStorage(ctx context.Context, gameStore storage.GameStore, playerSto
 		players[i] = player
 	}
 
-	bidding, err := NewBiddingRoundFromEncoded(gs.CurrentBidding)
-	if err != nil {
-		return nil, err
-	}
-
-	hands, err := NewHandsFromEncoded(gs.CurrentHands)
-	if err != nil {
-		return nil, err
-	}
-
-	var trick Trick
-	if gs.CurrentTrick != "" {
-		trick, err = NewTrickFromEncoded(gs.CurrentTrick)
-		if err != nil {
-			return nil, err
+	engine := &Engine{
+		rules:              rules,
+		complete:           gs.Complete,
+		currentDealerPos:   gs.CurrentDealerPos,
+		currentSeed:        gs.CurrentSeed,
+		currentDealVersion: gs.CurrentDealVersion,
+	}
+	var undoRequest *UndoRequest
+	var seatSwap *SeatSwap
+	switch gs.StateVersion {
+	case 0:
+		// Games saved before the state was versioned; the next save rewrites them in the current version.
+		undoRequest, err = decodeLegacyState(gs, engine)
+	case stateVersion:
+		var st *storedState
+		if st, err = decodeState(gs.State, engine); err == nil {
+			undoRequest, seatSwap = st.UndoRequest, st.SeatSwap
 		}
+	default:
+		return nil, fmt.Errorf("unknown game state version %d", gs.StateVersion)
 	}
-
-	var lastTrick Trick
-	if gs.LastTrick != "" {
-		lastTrick, err = NewTrickFromEncoded(gs.LastTrick)
-		if err != nil {
-			return nil, err
-		}
-	}
-
-	score, err := NewScoreFromEncoded(gs.Score)
-	if err != nil {
-		return nil, err
-	}
-
-	tally, err := NewTallyFromEncoded(gs.CurrentTally)
-	if err != nil {
-		return nil, err
-	}
-
-	passedCards, err := NewPassingRoundFromEncoded(gs.PassedCards)
 	if err != nil {
 		return nil, err
 	}
+	engine.updatePhase()
 
 	g := &game{
-		gameStore:        gameStore,
-		playerStore:      playerStore,
-		id:               id,
-		created:          gs.Created,
-		updated:          gs.Updated,
-		players:          players,
-		complete:         gs.Complete,
-		score:            score,
-		currentDealerPos: gs.CurrentDealerPos,
-		currentBidding:   bidding,
-		currentHands:     hands,
-		currentTrick:     trick,
-		lastTrick:        lastTrick,
-		currentTally:     tally,
-		passedCards:      passedCards,
-		rules:            rulesFromStorage(gs.Rules),
+		Engine:       engine,
+		gameStore:    gameStore,
+		playerStore:  playerStore,
+		id:           id,
+		created:      gs.Created,
+		updated:      gs.Updated,
+		turnDeadline: gs.TurnDeadline,
+		players:      players,
+		organizerID:  gs.OrganizerID,
+		undoRequest:  undoRequest,
+		seatSwap:     seatSwap,
+	}
+	if g.organizerID == "" && len(gs.PlayerIDs) > 0 {
+		// Games created before the organizer was stored; the organizer had the first seat, and could not leave it.
+		g.organizerID = gs.PlayerIDs[0]
 	}
 	return g, nil
 }
@@ -2,6 +2,7 @@ package game
 
 import (
 	"context"
+	"fmt"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
@@ -15,7 +16,7 @@ var (
 	})
 
 	ignoreDates = cmpopts.IgnoreFields(storage.Game{}, "Created", "Updated")
-	ignoreHands = cmpopts.IgnoreFields(storage.Game{}, "CurrentHands")
+	ignoreHands = cmpopts.IgnoreFields(storage.Game{}, "CurrentHands", "CurrentSeed", "CurrentDealVersion")
 )
 
 func TestNewGame(t *testing.T) {
@@ -46,6 +47,32 @@ func TestNewGame(t *testing.T) {
 	}
 }
 
+func TestNewGameAppliesRules(t *testing.T) {
+	ctx := context.Background()
+	gameStore := storage.NewFakeGameStore(nil)
+	playerStore := storage.NewFakePlayerStore()
+	id := "ABC123"
+	organizer := buildPlayer(t, playerStore, "PLAYERID")
+	rules := NewRules()
+	rules.SetToWin(64)
+	rules.SetNoTrumpRaisesToWin(false)
+
+	if _, err := NewGame(ctx, gameStore, playerStore, id, organizer, rules); err != nil {
+		t.Fatal(err)
+	}
+	g, err := GetGame(ctx, gameStore, playerStore, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got, want := g.Score().ToWin(), 64; got != want {
+		t.Errorf("Score().ToWin()=%d want=%d", got, want)
+	}
+	if got, want := g.Rules().NoTrumpRaisesToWin(), false; got != want {
+		t.Errorf("Rules().NoTrumpRaisesToWin()=%t want=%t", got, want)
+	}
+}
+
 func TestGetGame(t *testing.T) {
 	ctx := context.Background()
 	gameStore := storage.NewFakeGameStore(nil)
@@ -306,7 +333,7 @@ func TestAvailableBids(t *testing.T) {
 				CurrentHands:   "AH+AS+AD+AC",
 			},
 			pid:  "CAL",
-			want: orderedBids, // all bids
+			want: []string{"P", "7", "7N", "8", "8N", "9", "9N", "A", "AN", "B", "BN", "C", "CN", "K"}, // all bids
 		},
 		{
 			name: "some bidder",
@@ -316,7 +343,7 @@ func TestAvailableBids(t *testing.T) {
 				CurrentHands:   "AH+AS+AD+AC",
 			},
 			pid:  "DON",
-			want: orderedBids, // all bids
+			want: []string{"P", "7", "7N", "8", "8N", "9", "9N", "A", "AN", "B", "BN", "C", "CN", "K"}, // all bids
 		},
 		{
 			name: "bidding complete",
@@ -347,7 +374,30 @@ func TestAvailableBids(t *testing.T) {
 				CurrentHands:     "AH+AS+AD+AC",
 			},
 			pid:  "BOB",
-			want: []string{"P", "C", "CN"},
+			want: []string{"P", "C", "CN", "K"},
+		},
+		{
+			name: "dealer must overbid",
+			gs: &storage.Game{
+				PlayerIDs:        []string{"ABE", "BOB", "CAL", "DON"},
+				CurrentBidding:   "2|C|P|P",
+				CurrentDealerPos: 1,
+				CurrentHands:     "AH+AS+AD+AC",
+				Rules:            storage.Rules{DealerMustOverbid: true},
+			},
+			pid:  "BOB",
+			want: []string{"P", "CN", "K"},
+		},
+		{
+			name: "minimum bid",
+			gs: &storage.Game{
+				PlayerIDs:      []string{"ABE", "BOB", "CAL", "DON"},
+				CurrentBidding: "2",
+				CurrentHands:   "AH+AS+AD+AC",
+				Rules:          storage.Rules{MinBid: 6},
+			},
+			pid:  "CAL",
+			want: []string{"P", "6", "6N", "7", "7N", "8", "8N", "9", "9N", "A", "AN", "B", "BN", "C", "CN", "K"},
 		},
 		{
 			name: "incorrect order",
@@ -496,6 +546,25 @@ func TestAddPlayer(t *testing.T) {
 			pos:     1,
 			wantErr: ErrPlayerAlreadyAdded,
 		},
+		{
+			name: "invalid position",
+			gs: &storage.Game{
+				PlayerIDs: []string{"ABE", "", "", ""},
+			},
+			pid:     "BOB",
+			pos:     4,
+			wantErr: ErrInvalidPosition,
+		},
+		{
+			name: "all players, three player game",
+			gs: &storage.Game{
+				PlayerIDs: []string{"ABE", "", "CAL"},
+				Rules:     storage.Rules{Players: 3},
+			},
+			pid:  "BOB",
+			pos:  1,
+			want: []string{"ABE", "BOB", "CAL"},
+		},
 	}
 
 	for _, tc := range testCases {
@@ -570,7 +639,7 @@ func TestDealCards(t *testing.T) {
 				Score:            "52-",
 				CurrentDealerPos: 2,
 				CurrentBidding:   "3|",
-				CurrentTally:     "0|0|0",
+				CurrentTally:     "0|0|0|0|0",
 				PassedCards:      "3|",
 			},
 			wantState: BiddingState,
@@ -598,7 +667,7 @@ func TestDealCards(t *testing.T) {
 			if got, want := gotGame.State(), tc.wantState; got != want {
 				t.Errorf("State()=%s want=%s", got, want)
 			}
-			gotGameStorage := storageFromGame(gotGame.(*game))
+			gotGameStorage := legacyStorage(t, gotGame.(*game))
 			opts := []cmp.Option{ignoreDates, ignoreHands}
 			if diff := cmp.Diff(tc.want, gotGameStorage, opts...); diff != "" {
 				t.Errorf("game storage mismatch (-want +got):\n%s", diff)
@@ -653,6 +722,18 @@ func TestPassCard(t *testing.T) {
 			card:    "5H",
 			wantErr: ErrMissingCard,
 		},
+		{
+			name: "player already passed",
+			gs: &storage.Game{
+				PlayerIDs:    pids,
+				CurrentHands: "AH|KH+AS|8S+AD+AC",
+				PassedCards:  "0|8H",
+				Rules:        storage.Rules{PassCard: true},
+			},
+			pid:     "ABE",
+			card:    "KH",
+			wantErr: ErrAlreadyPassed,
+		},
 		{
 			name: "valid card",
 			gs: &storage.Game{
@@ -669,7 +750,7 @@ func TestPassCard(t *testing.T) {
 				PassedCards:    "0|8H|8S",
 				CurrentBidding: "0|",
 				CurrentHands:   "AH+AS+8S|AD+AC|8C",
-				CurrentTally:   "0|0|0",
+				CurrentTally:   "0|0|0|0|0",
 				Rules:          storage.Rules{PassCard: true},
 			},
 			wantState: PassingState,
@@ -692,11 +773,113 @@ func TestPassCard(t *testing.T) {
 				PassedCards:      "0|8H|8S|8D|8C",
 				CurrentHands:     "AH|8D+AS|8C+8H|AD+8S|AC", // Partners get the passed cards.
 				CurrentBidding:   "0|",
-				CurrentTally:     "0|0|0",
+				CurrentTally:     "0|0|0|0|0",
 				Rules:            storage.Rules{PassCard: true},
 			},
 			wantState: BiddingState,
 		},
+		{
+			name: "first of two cards, same player passes again",
+			gs: &storage.Game{
+				PlayerIDs:    pids,
+				CurrentHands: "9H|TH|JH+AS|KS+AD|KD+AC|KC",
+				PassedCards:  "0/2/1/1|",
+				Rules:        storage.Rules{PassCard: true, PassCount: 2, PassDirection: "left"},
+			},
+			pid:  "ABE",
+			card: "9H",
+			want: &storage.Game{
+				PlayerIDs:      pids,
+				Score:          "52-",
+				PassedCards:    "0/2/1/1|9H",
+				CurrentBidding: "0|",
+				CurrentHands:   "JH|TH+AS|KS+AD|KD+AC|KC",
+				CurrentTally:   "0|0|0|0|0",
+				Rules:          storage.Rules{PassCard: true, PassCount: 2, PassDirection: "left"},
+			},
+			wantState: PassingState,
+		},
+		{
+			name: "last passed card to the left, move to bidding state",
+			gs: &storage.Game{
+				PlayerIDs:        pids,
+				CurrentHands:     "AH+AS+AD+AC|8C",
+				CurrentDealerPos: 3,
+				PassedCards:      "0/1/1/1|8H|8S|8D",
+				Rules:            storage.Rules{PassCard: true, PassDirection: "left"},
+			},
+			pid:  "DON",
+			card: "8C",
+			want: &storage.Game{
+				PlayerIDs:        pids,
+				Score:            "52-",
+				CurrentDealerPos: 3,
+				PassedCards:      "0/1/1/1|8H|8S|8D|8C",
+				CurrentHands:     "AH|8C+8H|AS+8S|AD+8D|AC", // The player to the left gets the passed cards.
+				CurrentBidding:   "0|",
+				CurrentTally:     "0|0|0|0|0",
+				Rules:            storage.Rules{PassCard: true, PassDirection: "left"},
+			},
+			wantState: BiddingState,
+		},
+		{
+			name: "passing after the auction, not the winner's turn",
+			gs: &storage.Game{
+				PlayerIDs:      pids,
+				CurrentHands:   "AH+AS+AD+AC|8C",
+				CurrentBidding: "0|P|8|P|P",
+				PassedCards:    "1/1/2/2|",
+				Rules:          storage.Rules{PassCard: true, PassTiming: "after"},
+			},
+			pid:     "DON",
+			card:    "8C",
+			wantErr: ErrIncorrectPassOrder,
+		},
+		{
+			name: "passing after the auction, exchange and move to calling state",
+			gs: &storage.Game{
+				PlayerIDs:      pids,
+				CurrentHands:   "AH+AS+AD+AC|8C",
+				CurrentBidding: "0|P|8|P|P",
+				PassedCards:    "1/1/2/2|8S",
+				Rules:          storage.Rules{PassCard: true, PassTiming: "after"},
+			},
+			pid:  "DON",
+			card: "8C",
+			want: &storage.Game{
+				PlayerIDs:      pids,
+				Score:          "52-",
+				PassedCards:    "1/1/2/2|8S|8C",
+				CurrentHands:   "AH+AS|8C+AD+8S|AC", // Only the bid winner and partner exchange.
+				CurrentBidding: "0|P|8|P|P",
+				CurrentTally:   "0|0|0|0|0",
+				Rules:          storage.Rules{PassCard: true, PassTiming: "after"},
+			},
+			wantState: CallingState,
+		},
+		{
+			name: "passing after a no trump auction, exchange and move to playing state",
+			gs: &storage.Game{
+				PlayerIDs:      pids,
+				CurrentHands:   "AH+AS+AD+AC|8C",
+				CurrentBidding: "0|P|8N|P|P",
+				PassedCards:    "1/1/2/2|8S",
+				Rules:          storage.Rules{PassCard: true, PassTiming: "after"},
+			},
+			pid:  "DON",
+			card: "8C",
+			want: &storage.Game{
+				PlayerIDs:      pids,
+				Score:          "52-",
+				PassedCards:    "1/1/2/2|8S|8C",
+				CurrentHands:   "AH+AS|8C+AD+8S|AC",
+				CurrentBidding: "0|P|8N|P|P",
+				CurrentTrick:   "1|N",
+				CurrentTally:   "0|0|0|0|0",
+				Rules:          storage.Rules{PassCard: true, PassTiming: "after"},
+			},
+			wantState: PlayingState,
+		},
 	}
 
 	for _, tc := range testCases {
@@ -721,7 +904,7 @@ func TestPassCard(t *testing.T) {
 			if got, want := gotGame.State(), tc.wantState; got != want {
 				t.Errorf("State()=%s want=%s", got, want)
 			}
-			gotGameStorage := storageFromGame(gotGame.(*game))
+			gotGameStorage := legacyStorage(t, gotGame.(*game))
 			if diff := cmp.Diff(tc.want, gotGameStorage, ignoreDates); diff != "" {
 				t.Errorf("game storage mismatch (-want +got):\n%s", diff)
 			}
@@ -729,6 +912,171 @@ func TestPassCard(t *testing.T) {
 	}
 }
 
+func TestClaimMisdeal(t *testing.T) {
+	pids := []string{"ABE", "BOB", "CAL", "DON"}
+	weakHand := "7H|8H|9H|7S|8S|9S|7D|8D"
+	strongHand := "AH|KH|QH|AS|KS|QS|AD|KD"
+	testCases := []struct {
+		name      string
+		gs        *storage.Game
+		pid       string
+		want      *storage.Game
+		wantState GameState
+		wantErr   error
+	}{
+		{
+			name: "rules do not allow misdeals",
+			gs: &storage.Game{
+				PlayerIDs:      pids,
+				CurrentBidding: "0|",
+				CurrentHands:   weakHand + "+AS+AD+AC",
+			},
+			pid:     "ABE",
+			wantErr: ErrMisdealNotAllowed,
+		},
+		{
+			name: "game not started",
+			gs: &storage.Game{
+				PlayerIDs: []string{"ABE", "", "", ""},
+				Rules:     storage.Rules{Misdeal: "noface"},
+			},
+			pid:     "ABE",
+			wantErr: ErrInvalidMisdeal,
+		},
+		{
+			name: "nothing dealt",
+			gs: &storage.Game{
+				PlayerIDs:      pids,
+				CurrentBidding: "0|",
+				Rules:          storage.Rules{Misdeal: "noface"},
+			},
+			pid:     "ABE",
+			wantErr: ErrInvalidMisdeal,
+		},
+		{
+			name: "hand does not qualify",
+			gs: &storage.Game{
+				PlayerIDs:      pids,
+				CurrentBidding: "0|",
+				CurrentHands:   strongHand + "+AS+AD+AC",
+				Rules:          storage.Rules{Misdeal: "noface"},
+			},
+			pid:     "ABE",
+			wantErr: ErrInvalidMisdeal,
+		},
+		{
+			name: "already bid",
+			gs: &storage.Game{
+				PlayerIDs:      pids,
+				CurrentBidding: "0|P",
+				CurrentHands:   weakHand + "+AS+AD+AC",
+				Rules:          storage.Rules{Misdeal: "noface"},
+			},
+			pid:     "ABE",
+			wantErr: ErrMisdealTooLate,
+		},
+		{
+			name: "already passed a card",
+			gs: &storage.Game{
+				PlayerIDs:      pids,
+				CurrentBidding: "0|",
+				CurrentHands:   weakHand + "+AS+AD+AC",
+				PassedCards:    "0|JH",
+				Rules:          storage.Rules{PassCard: true, Misdeal: "noface"},
+			},
+			pid:     "ABE",
+			wantErr: ErrMisdealTooLate,
+		},
+		{
+			name: "playing",
+			gs: &storage.Game{
+				PlayerIDs:      pids,
+				CurrentBidding: "0|P|P|P|7",
+				CurrentTrick:   "3|H",
+				CurrentHands:   weakHand + "+AS+AD+AC",
+				Rules:          storage.Rules{Misdeal: "noface"},
+			},
+			pid:     "ABE",
+			wantErr: ErrMisdealTooLate,
+		},
+		{
+			name: "valid claim while bidding, same dealer deals again",
+			gs: &storage.Game{
+				PlayerIDs:        pids,
+				CurrentDealerPos: 2,
+				CurrentBidding:   "3|P",
+				CurrentHands:     weakHand + "+AS+AD+AC",
+				Rules:            storage.Rules{Misdeal: "noaces"},
+			},
+			pid: "ABE",
+			want: &storage.Game{
+				PlayerIDs:        pids,
+				Score:            "52-0|0**0|0|misdeal, no aces or special cards##0|2|3|P|-1|||0,0|0,0|-1|-1",
+				CurrentDealerPos: 2,
+				CurrentBidding:   "3|",
+				CurrentTally:     "0|0|0|0|0",
+				PassedCards:      "3|",
+				Rules:            storage.Rules{Misdeal: "noaces"},
+			},
+			wantState: BiddingState,
+		},
+		{
+			name: "valid claim while passing",
+			gs: &storage.Game{
+				PlayerIDs:        pids,
+				CurrentDealerPos: 0,
+				CurrentBidding:   "1|",
+				CurrentHands:     "AS+" + weakHand + "+AD+AC",
+				PassedCards:      "1|",
+				Rules:            storage.Rules{PassCard: true, Misdeal: "noface"},
+			},
+			pid: "BOB",
+			want: &storage.Game{
+				PlayerIDs:        pids,
+				Score:            "52-0|0**1|0|misdeal, no face cards##0|0|1||-1|||0,0|0,0|-1|-1",
+				CurrentDealerPos: 0,
+				CurrentBidding:   "1|",
+				CurrentTally:     "0|0|0|0|0",
+				PassedCards:      "1|",
+				Rules:            storage.Rules{PassCard: true, Misdeal: "noface"},
+			},
+			wantState: PassingState,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			g, _, playerStore := buildGame(t, tc.gs)
+			player := getPlayer(t, playerStore, tc.pid)
+
+			gotGame, err := g.ClaimMisdeal(ctx, player)
+
+			if tc.wantErr != nil && tc.wantErr != err {
+				t.Fatalf("incorrect error got=%v want=%v", err, tc.wantErr)
+			}
+			if tc.wantErr == nil && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tc.wantErr != nil {
+				return
+			}
+
+			if got, want := gotGame.State(), tc.wantState; got != want {
+				t.Errorf("State()=%s want=%s", got, want)
+			}
+			if diff := cmp.Diff([]int{8, 8, 8, 8}, gotGame.HandCounts()); diff != "" {
+				t.Errorf("HandCounts() mismatch (-want +got):\n%s", diff)
+			}
+			gotGameStorage := legacyStorage(t, gotGame.(*game))
+			opts := []cmp.Option{ignoreDates, ignoreHands}
+			if diff := cmp.Diff(tc.want, gotGameStorage, opts...); diff != "" {
+				t.Errorf("game storage mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
 func TestPlaceBid(t *testing.T) {
 	pids := []string{"ABE", "BOB", "CAL", "DON"}
 	testCases := []struct {
@@ -775,7 +1123,7 @@ func TestPlaceBid(t *testing.T) {
 				PlayerIDs:      pids,
 				Score:          "52-",
 				CurrentBidding: "0|8|8N",
-				CurrentTally:   "0|0|0",
+				CurrentTally:   "0|0|0|0|0",
 				CurrentHands:   "AH+AS+AD+AC",
 				PassedCards:    "0|",
 			},
@@ -796,7 +1144,7 @@ func TestPlaceBid(t *testing.T) {
 				Score:            "52-",
 				CurrentDealerPos: 3,
 				CurrentBidding:   "0|8|P|P|8",
-				CurrentTally:     "0|0|0",
+				CurrentTally:     "0|0|0|0|0",
 				CurrentHands:     "AH+AS+AD+AC",
 				PassedCards:      "0|"},
 			wantState: CallingState,
@@ -815,12 +1163,33 @@ func TestPlaceBid(t *testing.T) {
 				Score:          "52-",
 				CurrentBidding: "0|P|8N|P|P",
 				CurrentTrick:   "1|N",
-				CurrentTally:   "0|0|0",
+				CurrentTally:   "0|0|0|0|0",
 				CurrentHands:   "AH+AS+AD+AC",
 				PassedCards:    "0|",
 			},
 			wantState: PlayingState,
 		},
+		{
+			name: "passing after the auction, move to passing state",
+			gs: &storage.Game{
+				PlayerIDs:      pids,
+				CurrentBidding: "0|P|8N|P",
+				CurrentHands:   "AH+AS+AD+AC",
+				Rules:          storage.Rules{PassCard: true, PassCount: 2, PassTiming: "after"},
+			},
+			pid: "DON",
+			bid: "P",
+			want: &storage.Game{
+				PlayerIDs:      pids,
+				Score:          "52-",
+				CurrentBidding: "0|P|8N|P|P",
+				CurrentTally:   "0|0|0|0|0",
+				CurrentHands:   "AH+AS+AD+AC",
+				PassedCards:    "1/2/2/2|", // The winner and partner exchange two cards.
+				Rules:          storage.Rules{PassCard: true, PassCount: 2, PassTiming: "after"},
+			},
+			wantState: PassingState,
+		},
 	}
 
 	for _, tc := range testCases {
@@ -845,7 +1214,7 @@ func TestPlaceBid(t *testing.T) {
 			if got, want := gotGame.State(), tc.wantState; got != want {
 				t.Errorf("State()=%s want=%s", got, want)
 			}
-			gotGameStorage := storageFromGame(gotGame.(*game))
+			gotGameStorage := legacyStorage(t, gotGame.(*game))
 			if diff := cmp.Diff(tc.want, gotGameStorage, ignoreDates); diff != "" {
 				t.Errorf("game storage mismatch (-want +got):\n%s", diff)
 			}
@@ -853,6 +1222,92 @@ func TestPlaceBid(t *testing.T) {
 	}
 }
 
+func TestPlaceBidAllPass(t *testing.T) {
+	pids := []string{"ABE", "BOB", "CAL", "DON"}
+	testCases := []struct {
+		name      string
+		allPass   AllPassRule
+		want      *storage.Game
+		wantState GameState
+		wantErr   error
+	}{
+		{
+			name:    "stick the dealer",
+			allPass: StickTheDealer,
+			wantErr: ErrInvalidBid,
+		},
+		{
+			name:    "redeal by the same dealer",
+			allPass: RedealSameDealer,
+			want: &storage.Game{
+				PlayerIDs:        pids,
+				Score:            "52-0|0**1|0|all passed, same dealer deals##0|3|0|P,P,P,P|-1|||0,0|0,0|-1|-1",
+				CurrentDealerPos: 3,
+				CurrentBidding:   "0|",
+				CurrentTally:     "0|0|0|0|0",
+				PassedCards:      "0|",
+				Rules:            storage.Rules{AllPass: "same"},
+			},
+			wantState: BiddingState,
+		},
+		{
+			name:    "redeal by the next dealer",
+			allPass: RedealNextDealer,
+			want: &storage.Game{
+				PlayerIDs:        pids,
+				Score:            "52-0|0**1|0|all passed, next dealer deals##0|3|0|P,P,P,P|-1|||0,0|0,0|-1|-1",
+				CurrentDealerPos: 0,
+				CurrentBidding:   "1|",
+				CurrentTally:     "0|0|0|0|0",
+				PassedCards:      "1|",
+				Rules:            storage.Rules{AllPass: "next"},
+			},
+			wantState: BiddingState,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			rules := NewRules()
+			rules.SetAllPass(tc.allPass)
+			gs := &storage.Game{
+				PlayerIDs:        pids,
+				CurrentDealerPos: 3,
+				CurrentBidding:   "0|P|P|P",
+				CurrentHands:     "AH+AS+AD+AC",
+				Rules:            storageFromRules(rules),
+			}
+			g, _, playerStore := buildGame(t, gs)
+			player := getPlayer(t, playerStore, "DON")
+
+			gotGame, err := g.PlaceBid(ctx, player, buildBid(t, "P"))
+
+			if tc.wantErr != nil && tc.wantErr != err {
+				t.Fatalf("incorrect error got=%v want=%v", err, tc.wantErr)
+			}
+			if tc.wantErr == nil && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tc.wantErr != nil {
+				return
+			}
+
+			if got, want := gotGame.State(), tc.wantState; got != want {
+				t.Errorf("State()=%s want=%s", got, want)
+			}
+			if diff := cmp.Diff([]int{8, 8, 8, 8}, gotGame.HandCounts()); diff != "" {
+				t.Errorf("HandCounts() mismatch (-want +got):\n%s", diff)
+			}
+			gotGameStorage := legacyStorage(t, gotGame.(*game))
+			opts := []cmp.Option{ignoreDates, ignoreHands}
+			if diff := cmp.Diff(tc.want, gotGameStorage, opts...); diff != "" {
+				t.Errorf("game storage mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
 func TestCallTrump(t *testing.T) {
 	pids := []string{"ABE", "BOB", "CAL", "DON"}
 	testCases := []struct {
@@ -912,7 +1367,7 @@ func TestCallTrump(t *testing.T) {
 				Score:          "52-",
 				CurrentBidding: "0|P|P|P|7",
 				CurrentTrick:   "3|S",
-				CurrentTally:   "0|0|0",
+				CurrentTally:   "0|0|0|0|0",
 				CurrentHands:   "AH+AS+AD+AC",
 				PassedCards:    "0|",
 			},
@@ -942,7 +1397,7 @@ func TestCallTrump(t *testing.T) {
 			if got, want := gotGame.State(), tc.wantState; got != want {
 				t.Errorf("State()=%s want=%s", got, want)
 			}
-			gotGameStorage := storageFromGame(gotGame.(*game))
+			gotGameStorage := legacyStorage(t, gotGame.(*game))
 			if diff := cmp.Diff(tc.want, gotGameStorage, ignoreDates); diff != "" {
 				t.Errorf("game storage mismatch (-want +got):\n%s", diff)
 			}
@@ -1037,7 +1492,7 @@ func TestPlayCard(t *testing.T) {
 				CurrentHands:   "AH|KH+AS+AC|KC+KD", // KS played
 				CurrentBidding: "0|P|P|P|7",
 				CurrentTrick:   "3|H|AD|7D|KS",
-				CurrentTally:   "0|0|0",
+				CurrentTally:   "0|0|0|0|0",
 				PassedCards:    "0|",
 			},
 			wantState: PlayingState,
@@ -1057,8 +1512,8 @@ func TestPlayCard(t *testing.T) {
 				Score:          "52-",
 				CurrentHands:   "AH|KH+AS+AC+KD", // KC played
 				CurrentBidding: "0|P|P|P|7",
-				CurrentTrick:   "3|H",   // Lead-off position (3) won last trick; hearts still trump.
-				CurrentTally:   "1|0|1", // One card played; team 1/3 got the point.
+				CurrentTrick:   "3|H",       // Lead-off position (3) won last trick; hearts still trump.
+				CurrentTally:   "1|0|1|0|1", // One card played; team 1/3 got the point.
 				LastTrick:      "3|H|AD|7D|KS|KC",
 				PassedCards:    "0|",
 			},
@@ -1072,7 +1527,7 @@ func TestPlayCard(t *testing.T) {
 				CurrentDealerPos: 3,
 				CurrentBidding:   "0|P|P|P|7",
 				CurrentTrick:     "3|H|AD|AH|AS",
-				CurrentTally:     "7|9|0",
+				CurrentTally:     "7|9|0|7|0",
 			},
 			pid:  "CAL",
 			card: "AC",
@@ -1082,8 +1537,8 @@ func TestPlayCard(t *testing.T) {
 				CurrentDealerPos: 3,
 				CurrentBidding:   "0|P|P|P|7",
 				CurrentTrick:     "", // New hand.
-				CurrentTally:     "8|10|0",
-				Score:            "52-10|-7**1|0|missed 7 bid", // Score added from tally.
+				CurrentTally:     "8|10|0|8|0",
+				Score:            "52-10|-7**1|0|missed 7 bid##0|3|0|P,P,P,7|3|7|H|8,0|10,0|-1|-1", // Score added from tally.
 				LastTrick:        "3|H|AD|AH|AS|AC",
 				PassedCards:      "0|",
 			},
@@ -1098,7 +1553,7 @@ func TestPlayCard(t *testing.T) {
 				CurrentDealerPos: 3,
 				CurrentBidding:   "0|P|P|P|7",
 				CurrentTrick:     "3|H|AD|AH|AS",
-				CurrentTally:     "7|9|0",
+				CurrentTally:     "7|9|0|7|0",
 				PassedCards:      "0|7C|8C|9C|TC",
 				Rules:            storage.Rules{PassCard: true},
 			},
@@ -1110,8 +1565,8 @@ func TestPlayCard(t *testing.T) {
 				CurrentDealerPos: 3,
 				CurrentBidding:   "0|P|P|P|7",
 				CurrentTrick:     "", // New hand.
-				CurrentTally:     "8|10|0",
-				Score:            "52-10|-7**1|0|missed 7 bid", // Score added from tally.
+				CurrentTally:     "8|10|0|8|0",
+				Score:            "52-10|-7**1|0|missed 7 bid##0|3|0|P,P,P,7|3|7|H|8,0|10,0|-1|-1", // Score added from tally.
 				LastTrick:        "3|H|AD|AH|AS|AC",
 				PassedCards:      "0|7C|8C|9C|TC",
 				Rules:            storage.Rules{PassCard: true},
@@ -1127,7 +1582,7 @@ func TestPlayCard(t *testing.T) {
 				CurrentDealerPos: 3,
 				CurrentBidding:   "0|P|P|7|P",
 				CurrentTrick:     "3|H|AD|AH|AS",
-				CurrentTally:     "7|9|0",
+				CurrentTally:     "7|9|0|7|0",
 				Score:            "52-50|0", // Score is close to completion.
 			},
 			pid:  "CAL",
@@ -1135,12 +1590,12 @@ func TestPlayCard(t *testing.T) {
 			want: &storage.Game{
 				Complete:         true,
 				PlayerIDs:        pids,
-				CurrentHands:     "+++",                             // Hands are empty.
-				CurrentDealerPos: 3,                                 // Dealer position does not update.
-				CurrentBidding:   "0|P|P|7|P",                       // Bidding does not clear.
-				CurrentTrick:     "",                                // Trick resets.
-				CurrentTally:     "8|10|0",                          // Tally does not clear.
-				Score:            "52||0-50|0||60|0**0|1|bid out 7", // Score added from tally.
+				CurrentHands:     "+++",                                                                 // Hands are empty.
+				CurrentDealerPos: 3,                                                                     // Dealer position does not update.
+				CurrentBidding:   "0|P|P|7|P",                                                           // Bidding does not clear.
+				CurrentTrick:     "",                                                                    // Trick resets.
+				CurrentTally:     "8|10|0|8|0",                                                          // Tally does not clear.
+				Score:            "52||0-50|0||60|0**0|1|bid out 7##1|3|0|P,P,7,P|2|7|H|8,0|10,0|-1|-1", // Score added from tally.
 				LastTrick:        "3|H|AD|AH|AS|AC",
 				PassedCards:      "0|",
 			},
@@ -1170,7 +1625,7 @@ func TestPlayCard(t *testing.T) {
 			if got, want := gotGame.State(), tc.wantState; got != want {
 				t.Errorf("State()=%s want=%s", got, want)
 			}
-			gotGameStorage := storageFromGame(gotGame.(*game))
+			gotGameStorage := legacyStorage(t, gotGame.(*game))
 			opts := []cmp.Option{ignoreDates}
 			if tc.wantEmptyHand {
 				opts = append(opts, cmpopts.IgnoreFields(storage.Game{}, "CurrentHands"))
@@ -1192,6 +1647,151 @@ func TestPlayCard(t *testing.T) {
 	}
 }
 
+func TestPlayHandForPlayers(t *testing.T) {
+	for _, players := range []int{3, 5, 6} {
+		t.Run(fmt.Sprintf("%d players", players), func(t *testing.T) {
+			ctx := context.Background()
+			gameStore := storage.NewFakeGameStore(nil)
+			playerStore := storage.NewFakePlayerStore()
+			rules := NewRules()
+			rules.SetPlayers(players)
+
+			var ps []Player
+			for i := 0; i < players; i++ {
+				ps = append(ps, buildPlayer(t, playerStore, fmt.Sprintf("PLAYER%d", i)))
+			}
+			g, err := NewGame(ctx, gameStore, playerStore, "ABC123", ps[0], rules)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for pos := 1; pos < players; pos++ {
+				if g, err = g.AddPlayer(ctx, ps[pos], pos); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			if got, want := g.State(), BiddingState; got != want {
+				t.Fatalf("State()=%v want=%v", got, want)
+			}
+			for pos, count := range g.HandCounts() {
+				if count != 8 {
+					t.Errorf("HandCounts()[%d]=%d want=8", pos, count)
+				}
+			}
+			if got, want := len(g.HandCounts()), players; got != want {
+				t.Fatalf("len(HandCounts())=%d want=%d", got, want)
+			}
+
+			// Everyone passes, sticking the dealer with the lowest bid.
+			for g.State() == BiddingState {
+				pos, err := g.PosToPlay()
+				if err != nil {
+					t.Fatal(err)
+				}
+				available, err := g.AvailableBids(ps[pos])
+				if err != nil {
+					t.Fatal(err)
+				}
+				if g, err = g.PlaceBid(ctx, ps[pos], available[0]); err != nil {
+					t.Fatal(err)
+				}
+			}
+			_, bidPos, err := g.CurrentBidding().WinningBidAndPos()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got, want := bidPos, g.DealerPos(); got != want {
+				t.Errorf("winning bid position=%d want=%d", got, want)
+			}
+			if g, err = g.CallTrump(ctx, ps[bidPos], buildSuit(t, "H")); err != nil {
+				t.Fatal(err)
+			}
+
+			// Play the first card that follows suit.
+			for g.State() == PlayingState {
+				pos, err := g.PosToPlay()
+				if err != nil {
+					t.Fatal(err)
+				}
+				hand, err := g.PlayerHand(ps[pos])
+				if err != nil {
+					t.Fatal(err)
+				}
+				card := hand.Cards()[0]
+				if g.CurrentTrick().NumPlayed() > 0 {
+					leadSuit, err := g.CurrentTrick().LeadSuit()
+					if err != nil {
+						t.Fatal(err)
+					}
+					for _, c := range hand.Cards() {
+						if c.Suit() == leadSuit {
+							card = c
+							break
+						}
+					}
+				}
+				if g, err = g.PlayCard(ctx, ps[pos], card); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			if got, want := g.State(), DealingState; got != want {
+				t.Fatalf("State()=%v want=%v", got, want)
+			}
+			if got, want := len(g.Score().CurrentScore()), NumTeams(players); got != want {
+				t.Fatalf("len(Score().CurrentScore())=%d want=%d", got, want)
+			}
+			total := 0
+			for _, points := range g.Tally().Points() {
+				total += points
+			}
+			if got, want := total, 10; got != want {
+				t.Errorf("total points=%d want=%d", got, want)
+			}
+			tricks := 0
+			for _, n := range g.Tally().Tricks() {
+				tricks += n
+			}
+			if got, want := tricks, 8; got != want {
+				t.Errorf("total tricks=%d want=%d", got, want)
+			}
+
+			// The game reloads from storage with the same number of players and teams.
+			reloaded, err := GetGame(ctx, gameStore, playerStore, g.ID())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(g.Score().Scores(), reloaded.Score().Scores()); diff != "" {
+				t.Errorf("Score().Scores() mismatch (-want +got):\n%s", diff)
+			}
+
+			// The hand is recorded with the score.
+			hands := reloaded.Score().Hands()
+			if got, want := len(hands), 1; got != want {
+				t.Fatalf("len(Score().Hands())=%d want=%d", got, want)
+			}
+			if got, want := hands[0].BidderPos, bidPos; got != want {
+				t.Errorf("BidderPos=%d want=%d", got, want)
+			}
+			if got, want := len(hands[0].Bids), players; got != want {
+				t.Errorf("len(Bids)=%d want=%d", got, want)
+			}
+			if !hands[0].Trump.IsSameAs(buildSuit(t, "H")) {
+				t.Errorf("Trump=%q want=%q", hands[0].Trump.Encoded(), "H")
+			}
+			if diff := cmp.Diff(g.Tally().Points(), hands[0].Points); diff != "" {
+				t.Errorf("Points mismatch (-want +got):\n%s", diff)
+			}
+			if hands[0].FiveOfHeartsTeam == NoTeam || hands[0].ThreeOfSpadesTeam == NoTeam {
+				t.Errorf("special cards not recorded: five=%d three=%d", hands[0].FiveOfHeartsTeam, hands[0].ThreeOfSpadesTeam)
+			}
+			if got, want := len(reloaded.Players()), players; got != want {
+				t.Errorf("len(Players())=%d want=%d", got, want)
+			}
+		})
+	}
+}
+
 func buildPlayer(t *testing.T, playerStore storage.PlayerStore, id string) Player {
 	t.Helper()
 	p, err := NewPlayer(context.Background(), playerStore, id, id+" NAME")
@@ -1223,3 +1823,121 @@ func buildGame(t *testing.T, gs *storage.Game) (Game, storage.GameStore, storage
 	}
 	return g, gameStore, playerStore
 }
+
+// legacyStorage returns the storage for the game in the form of older games, with the state of play in the version 0
+// encoded strings, which are easier to compare in test cases than the JSON encoded state.
+func legacyStorage(t *testing.T, g *game) *storage.Game {
+	t.Helper()
+	gs, err := storageFromGame(g)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gs.StateVersion, gs.State = 0, ""
+	gs.Score = g.score.Encoded()
+	gs.CurrentBidding = g.currentBidding.Encoded()
+	gs.CurrentHands = g.currentHands.Encoded()
+	if g.currentTrick != nil {
+		gs.CurrentTrick = g.currentTrick.Encoded()
+	}
+	if g.lastTrick != nil {
+		gs.LastTrick = g.lastTrick.Encoded()
+	}
+	gs.CurrentTally = g.currentTally.Encoded()
+	gs.PassedCards = g.passedCards.Encoded()
+	gs.UndoRequest = encodeUndoRequest(g.undoRequest)
+	// Older games did not store the organizer, who had the first seat.
+	if len(gs.PlayerIDs) > 0 && gs.OrganizerID == gs.PlayerIDs[0] {
+		gs.OrganizerID = ""
+	}
+	return gs
+}
+
+func TestSaveConflict(t *testing.T) {
+	ctx := context.Background()
+	gameStore := storage.NewFakeGameStore(nil)
+	playerStore := storage.NewFakePlayerStore()
+
+	var ps []Player
+	for i := 0; i < 4; i++ {
+		ps = append(ps, buildPlayer(t, playerStore, fmt.Sprintf("PLAYER%d", i)))
+	}
+	g, err := NewGame(ctx, gameStore, playerStore, "ABC123", ps[0], NewRules())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for pos := 1; pos < 4; pos++ {
+		if g, err = g.AddPlayer(ctx, ps[pos], pos); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Two requests read the game at the same time.
+	first, err := GetGame(ctx, gameStore, playerStore, g.ID())
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := GetGame(ctx, gameStore, playerStore, g.ID())
+	if err != nil {
+		t.Fatal(err)
+	}
+	pos, err := first.PosToPlay()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := first.PlaceBid(ctx, ps[pos], buildBid(t, "P")); err != nil {
+		t.Fatal(err)
+	}
+
+	// The second write is on stale state.
+	if _, err := second.PlaceBid(ctx, ps[pos], buildBid(t, "P")); err != ErrConflict {
+		t.Fatalf("PlaceBid()=%v want=%v", err, ErrConflict)
+	}
+
+	// Once reloaded, the game reflects the first write only.
+	reloaded, err := GetGame(ctx, gameStore, playerStore, g.ID())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := reloaded.CurrentBidding().NumPlaced(), 1; got != want {
+		t.Errorf("NumPlaced()=%d want=%d", got, want)
+	}
+}
+
+func TestSaveConflictWithJoin(t *testing.T) {
+	ctx := context.Background()
+	gameStore := storage.NewFakeGameStore(nil)
+	playerStore := storage.NewFakePlayerStore()
+
+	var ps []Player
+	for i := 0; i < 3; i++ {
+		ps = append(ps, buildPlayer(t, playerStore, fmt.Sprintf("PLAYER%d", i)))
+	}
+	g, err := NewGame(ctx, gameStore, playerStore, "ABC123", ps[0], NewRules())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g, err = g.AddPlayer(ctx, ps[1], 1); err != nil {
+		t.Fatal(err)
+	}
+	stale, err := GetGame(ctx, gameStore, playerStore, g.ID())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A player joins after the game was read.
+	if _, err := g.AddPlayer(ctx, ps[2], 2); err != nil {
+		t.Fatal(err)
+	}
+
+	// Saving the stale game would empty the seat just taken.
+	if _, err := stale.ChangeSeat(ctx, ps[1], 3); err != ErrConflict {
+		t.Fatalf("ChangeSeat()=%v want=%v", err, ErrConflict)
+	}
+	reloaded, err := GetGame(ctx, gameStore, playerStore, g.ID())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := playerIDs(reloaded), []string{"PLAYER0", "PLAYER1", "PLAYER2", ""}; !cmp.Equal(got, want) {
+		t.Errorf("players=%v want=%v", got, want)
+	}
+}
@@ -2,7 +2,6 @@ package game
 
 import (
 	"fmt"
-	"sort"
 	"strings"
 
 	"github.com/squee1945/threespot/server/pkg/deck"
@@ -14,6 +13,10 @@ type Hands interface {
 	Hand(playerPos int) (Hand, error)
 	// Encoded returns the encoded form for the hands.
 	Encoded() string
+	// clone returns a copy of the hands that can be changed independently.
+	clone() Hands
+	// isDealt returns true if a hand of the players holds cards; as with HandCounts, missing hands count as dealt.
+	isDealt(players int) bool
 }
 
 // Hand is a hand of cards held by a player.
@@ -31,8 +34,14 @@ type Hand interface {
 	addCard(card deck.Card) error
 	// IsEmpty returns true if there are no cards left in the hand.
 	IsEmpty() bool
+	// numCards returns the number of cards in the hand.
+	numCards() int
+	// held returns the set of cards in the hand.
+	held() cardSet
 	// Encoded returns the encoded form of the hand.
 	Encoded() string
+	// clone returns a copy of the hand that can be changed independently.
+	clone() Hand
 }
 
 const (
@@ -40,42 +49,28 @@ const (
 	cardsDelim = "|"
 )
 
-var (
-	suitValues = map[string]int{
-		deck.Hearts.Encoded():   4,
-		deck.Spades.Encoded():   3,
-		deck.Diamonds.Encoded(): 2,
-		deck.Clubs.Encoded():    1,
-	}
-)
-
 type hands struct {
 	hs []Hand
 }
 
 type hand struct {
-	cards []deck.Card
+	cards  []deck.Card // The cards as given, or in hand order once sorted; nil if they are to be listed from the set again.
+	set    cardSet     // The cards in the hand.
+	sorted bool        // The cards are in hand order (see cardSet).
 }
 
 var _ Hands = (*hands)(nil) // Ensure interface is implemented.
 var _ Hand = (*hand)(nil)   // Ensure interface is implemented.
 
 // NewHandsFromEncoded creates a set of hands from the Encoded() form.
+// The empty string is four empty hands.
 func NewHandsFromEncoded(encoded string) (Hands, error) {
 	if encoded == "" {
-		hands := &hands{}
-		for i := 0; i < 4; i++ {
-			h, err := NewHandFromEncoded("")
-			if err != nil {
-				return nil, err
-			}
-			hands.hs = append(hands.hs, h)
-		}
-		return hands, nil
+		return newEmptyHands(defaultPlayers), nil
 	}
 	parts := strings.Split(encoded, handsDelim)
-	if len(parts) != 4 {
-		return nil, fmt.Errorf("encoded %q did not have 4 parts", encoded)
+	if len(parts) < deck.MinPlayers || len(parts) > deck.MaxPlayers {
+		return nil, fmt.Errorf("encoded %q did not have %d to %d parts", encoded, deck.MinPlayers, deck.MaxPlayers)
 	}
 	var hs []Hand
 	for _, p := range parts {
@@ -88,25 +83,35 @@ func NewHandsFromEncoded(encoded string) (Hands, error) {
 	return &hands{hs: hs}, nil
 }
 
-// NewHands creates a new set of hands.
+// NewHands creates a new set of hands, one for each player.
 func NewHands(cardSets [][]deck.Card) (Hands, error) {
-	if len(cardSets) != 4 {
-		return nil, fmt.Errorf("must have 4 sets of cards")
+	if len(cardSets) < deck.MinPlayers || len(cardSets) > deck.MaxPlayers {
+		return nil, fmt.Errorf("must have %d to %d sets of cards", deck.MinPlayers, deck.MaxPlayers)
 	}
-	var hs []Hand
-	for _, set := range cardSets {
-		h, err := NewHand(set)
-		if err != nil {
+	// The hands are allocated together; a deal is made for every hand played.
+	store := make([]hand, len(cardSets))
+	hs := make([]Hand, len(cardSets))
+	for i, set := range cardSets {
+		if err := store[i].setCards(set); err != nil {
 			return nil, err
 		}
-		hs = append(hs, h)
+		hs[i] = &store[i]
 	}
 	return &hands{hs: hs}, nil
 }
 
+// newEmptyHands creates a set of empty hands for the given number of players.
+func newEmptyHands(players int) Hands {
+	hs := &hands{}
+	for i := 0; i < players; i++ {
+		hs.hs = append(hs.hs, &hand{})
+	}
+	return hs
+}
+
 func (hs *hands) Hand(playerPos int) (Hand, error) {
-	if playerPos < 0 || playerPos > 3 {
-		return nil, fmt.Errorf("player position must be on interval [0,3]")
+	if playerPos < 0 || playerPos >= len(hs.hs) {
+		return nil, fmt.Errorf("player position must be on interval [0,%d]", len(hs.hs)-1)
 	}
 	return hs.hs[playerPos], nil
 }
@@ -139,63 +144,70 @@ func NewHandFromEncoded(encoded string) (Hand, error) {
 
 // NewHand creates a hand from the given cards. An error is returned if there are duplicates.
 func NewHand(cards []deck.Card) (Hand, error) {
-	seen := make(map[string]bool, len(cards))
+	h := &hand{}
+	if err := h.setCards(cards); err != nil {
+		return nil, err
+	}
+	return h, nil
+}
+
+// setCards sets the hand to hold the cards, in the order given.
+func (h *hand) setCards(cards []deck.Card) error {
+	var set cardSet
 	for _, card := range cards {
-		if _, present := seen[card.Encoded()]; present {
-			return nil, fmt.Errorf("hand %v has duplicate card", cards)
+		if set&cardBit(card) != 0 {
+			return fmt.Errorf("hand %v has duplicate card", cards)
 		}
-		seen[card.Encoded()] = true
+		set |= cardBit(card)
 	}
-	return &hand{cards: cards}, nil
+	*h = hand{cards: cards, set: set}
+	return nil
 }
 
 func (h *hand) Encoded() string {
+	cards := h.cards
+	if h.sorted {
+		cards = h.Cards()
+	}
 	var cs []string
-	for _, card := range h.cards {
+	for _, card := range cards {
 		cs = append(cs, card.Encoded())
 	}
 	return strings.Join(cs, cardsDelim)
 }
 
 func (h *hand) Contains(card deck.Card) bool {
-	for _, c := range h.cards {
-		if c.Encoded() == card.Encoded() {
-			return true
-		}
-	}
-	return false
+	return h.set&cardBit(card) != 0
 }
 
 func (h *hand) ContainsSuit(suit deck.Suit, ignoreCard deck.Card) bool {
-	for _, c := range h.cards {
-		if c.IsSameAs(ignoreCard) {
-			continue
-		}
-		if c.Suit() == suit {
-			return true
-		}
-	}
-	return false
+	return h.set&suitSet(suit)&^cardBit(ignoreCard) != 0
 }
 
 func (h *hand) Cards() []deck.Card {
-	sort.SliceStable(h.cards, handSorter(h.cards))
+	h.sort()
+	if h.cards == nil && h.set != 0 {
+		h.cards = h.set.appendCards(make([]deck.Card, 0, h.set.count()))
+	}
 	return h.cards
 }
 
+// sort puts the cards in hand order, if they are not already.
+func (h *hand) sort() {
+	if !h.sorted {
+		h.cards = h.set.appendCards(h.cards[:0])
+		h.sorted = true
+	}
+}
+
+// removeCard leaves the rest of the cards in hand order. The cards are listed again when next asked for, so a slice
+// returned by Cards does not change.
 func (h *hand) removeCard(card deck.Card) error {
 	if !h.Contains(card) {
 		return ErrMissingCard
 	}
-	var newCards []deck.Card
-	for _, c := range h.cards {
-		if c.Encoded() == card.Encoded() {
-			continue
-		}
-		newCards = append(newCards, c)
-	}
-	h.cards = newCards
-	sort.SliceStable(h.cards, handSorter(h.cards))
+	h.set &^= cardBit(card)
+	h.cards, h.sorted = nil, true
 	return nil
 }
 
@@ -203,21 +215,47 @@ func (h *hand) addCard(card deck.Card) error {
 	if h.Contains(card) {
 		return fmt.Errorf("duplicate card %s", card)
 	}
-	h.cards = append(h.cards, card)
-	sort.SliceStable(h.cards, handSorter(h.cards))
+	h.set |= cardBit(card)
+	h.cards, h.sorted = nil, true
 	return nil
 }
 
-func (h *hand) IsEmpty() bool {
-	return len(h.cards) == 0
+func (hs *hands) isDealt(players int) bool {
+	if players > len(hs.hs) {
+		return true
+	}
+	for _, h := range hs.hs[:players] {
+		if !h.IsEmpty() {
+			return true
+		}
+	}
+	return false
 }
 
-func handSorter(cards []deck.Card) func(i, j int) bool {
-	return func(i, j int) bool {
-		card1, card2 := cards[i], cards[j]
-		if !card1.Suit().IsSameAs(card2.Suit()) {
-			return suitValues[card1.Suit().Encoded()] > suitValues[card2.Suit().Encoded()]
-		}
-		return !isNumHigher(card1.Num(), card2.Num())
+func (hs *hands) clone() Hands {
+	c := &hands{hs: make([]Hand, len(hs.hs))}
+	for i, h := range hs.hs {
+		c.hs[i] = h.clone()
+	}
+	return c
+}
+
+func (h *hand) clone() Hand {
+	c := &hand{set: h.set, sorted: h.sorted}
+	if !h.sorted {
+		c.cards = append([]deck.Card(nil), h.cards...)
 	}
+	return c
+}
+
+func (h *hand) IsEmpty() bool {
+	return h.set == 0
+}
+
+func (h *hand) numCards() int {
+	return h.set.count()
+}
+
+func (h *hand) held() cardSet {
+	return h.set
 }
@@ -28,12 +28,12 @@ func TestNewHandsFromEncoded(t *testing.T) {
 		},
 		{
 			name:    "too short",
-			encoded: "++",
+			encoded: "+",
 			wantErr: true,
 		},
 		{
 			name:    "too long",
-			encoded: "++++",
+			encoded: "++++++",
 			wantErr: true,
 		},
 		{
@@ -51,6 +51,27 @@ func TestNewHandsFromEncoded(t *testing.T) {
 				[]deck.Card{buildCard(t, "JD")},
 			},
 		},
+		{
+			name:    "valid three player hands",
+			encoded: "QH|5H+8S|3S+KC",
+			want: [][]deck.Card{
+				[]deck.Card{buildCard(t, "QH"), buildCard(t, "5H")},
+				[]deck.Card{buildCard(t, "8S"), buildCard(t, "3S")},
+				[]deck.Card{buildCard(t, "KC")},
+			},
+		},
+		{
+			name:    "valid six player hands",
+			encoded: "QH+8S+KC+JD+4S+6H",
+			want: [][]deck.Card{
+				[]deck.Card{buildCard(t, "QH")},
+				[]deck.Card{buildCard(t, "8S")},
+				[]deck.Card{buildCard(t, "KC")},
+				[]deck.Card{buildCard(t, "JD")},
+				[]deck.Card{buildCard(t, "4S")},
+				[]deck.Card{buildCard(t, "6H")},
+			},
+		},
 	}
 
 	for _, tc := range testCases {
@@ -68,7 +89,7 @@ func TestNewHandsFromEncoded(t *testing.T) {
 			}
 
 			var got [][]deck.Card
-			for i := 0; i < 4; i++ {
+			for i := range tc.want {
 				h, err := hands.Hand(i)
 				if err != nil {
 					t.Fatal(err)
@@ -119,11 +140,11 @@ func TestNewHands(t *testing.T) {
 }
 
 func TestNewHandsErrors(t *testing.T) {
-	_, err := NewHands(make([][]deck.Card, 3))
+	_, err := NewHands(make([][]deck.Card, 2))
 	if err == nil {
 		t.Errorf("missing error for too few hands")
 	}
-	_, err = NewHands(make([][]deck.Card, 5))
+	_, err = NewHands(make([][]deck.Card, 7))
 	if err == nil {
 		t.Errorf("missing error for too many hands")
 	}
@@ -0,0 +1,244 @@
+package game
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/squee1945/threespot/server/pkg/deck"
+)
+
+// HandRecord is the breakdown of a single hand, kept with the score.
+type HandRecord struct {
+	// ScoreIndex is the index of the hand's entry in Score.Scores().
+	ScoreIndex int
+	// DealerPos is the position of the dealer.
+	DealerPos int
+	// BidLeadPos is the position of the first bidder.
+	BidLeadPos int
+	// Bids are the bids placed, in bidding order starting from BidLeadPos.
+	Bids []Bid
+	// BidderPos is the position of the player that won the auction, or -1 if the hand was thrown in.
+	BidderPos int
+	// WinningBid is the winning bid, or nil if the hand was thrown in.
+	WinningBid Bid
+	// Trump is the trump suit (deck.NoTrump for a no trump bid), or nil if the hand was thrown in.
+	Trump deck.Suit
+	// Tricks is the number of tricks taken, indexed by team.
+	Tricks []int
+	// Points is the points taken, indexed by team.
+	Points []int
+	// FiveOfHeartsTeam is the team that took the 5 of hearts, or NoTeam.
+	FiveOfHeartsTeam int
+	// ThreeOfSpadesTeam is the team that took the 3 of spades, or NoTeam.
+	ThreeOfSpadesTeam int
+	// Seed is the seed the hand was shuffled with (see DealHands), or 0 if unknown.
+	Seed int64
+	// DealVersion is the deal version the hand was shuffled with.
+	DealVersion int
+}
+
+// ThrownIn returns true if the hand was thrown in before it was played (e.g., everyone passed, or a misdeal).
+func (r HandRecord) ThrownIn() bool {
+	return r.WinningBid == nil
+}
+
+// MadeBid returns true if the team that won the auction took the points it bid (every trick, for a Kaiser bid).
+// It is false for a thrown-in hand.
+func (r HandRecord) MadeBid() bool {
+	if r.ThrownIn() || len(r.Points) == 0 {
+		return false
+	}
+	made, err := bidMade(r.WinningBid, teamOfForTeams(r.BidderPos, len(r.Points)), r.Points, r.Tricks)
+	return err == nil && made
+}
+
+// encodeHandRecord encodes the record as
+// "scoreIndex|dealerPos|bidLeadPos|bid,bid,...|bidderPos|winningBid|trump|tricks,...|points,...|fiveTeam|threeTeam".
+// The winning bid and trump are empty for a thrown-in hand. If the seed is known, it is appended as a final part,
+// followed by the deal version if it is not DealMathRand.
+func encodeHandRecord(r HandRecord) string {
+	var bids []string
+	for _, b := range r.Bids {
+		bids = append(bids, b.Encoded())
+	}
+	var winningBid, trump string
+	if r.WinningBid != nil {
+		winningBid = r.WinningBid.Encoded()
+	}
+	if r.Trump != nil {
+		trump = r.Trump.Encoded()
+	}
+	parts := []string{
+		strconv.Itoa(r.ScoreIndex),
+		strconv.Itoa(r.DealerPos),
+		strconv.Itoa(r.BidLeadPos),
+		strings.Join(bids, ","),
+		strconv.Itoa(r.BidderPos),
+		winningBid,
+		trump,
+		encodeInts(r.Tricks),
+		encodeInts(r.Points),
+		strconv.Itoa(r.FiveOfHeartsTeam),
+		strconv.Itoa(r.ThreeOfSpadesTeam),
+	}
+	if r.Seed != 0 {
+		parts = append(parts, strconv.FormatInt(r.Seed, 10))
+		if r.DealVersion != DealMathRand {
+			parts = append(parts, strconv.Itoa(r.DealVersion))
+		}
+	}
+	return strings.Join(parts, "|")
+}
+
+// decodeHandRecord builds a record from the encodeHandRecord() form.
+func decodeHandRecord(encoded string) (HandRecord, error) {
+	var r HandRecord
+	parts := strings.Split(encoded, "|")
+	if len(parts) < 11 || len(parts) > 13 {
+		return r, fmt.Errorf("encoded hand record %q must have 11 to 13 parts", encoded)
+	}
+
+	var err error
+	for i, dest := range map[int]*int{0: &r.ScoreIndex, 1: &r.DealerPos, 2: &r.BidLeadPos, 4: &r.BidderPos, 9: &r.FiveOfHeartsTeam, 10: &r.ThreeOfSpadesTeam} {
+		if *dest, err = strconv.Atoi(parts[i]); err != nil {
+			return r, fmt.Errorf("parsing part %d of hand record %q: %v", i, encoded, err)
+		}
+	}
+
+	if parts[3] != "" {
+		for _, eb := range strings.Split(parts[3], ",") {
+			b, err := NewBidFromEncoded(eb)
+			if err != nil {
+				return r, err
+			}
+			r.Bids = append(r.Bids, b)
+		}
+	}
+	if parts[5] != "" {
+		if r.WinningBid, err = NewBidFromEncoded(parts[5]); err != nil {
+			return r, err
+		}
+	}
+	if parts[6] != "" {
+		if r.Trump, err = deck.NewSuitFromEncoded(parts[6]); err != nil {
+			return r, err
+		}
+	}
+	if r.Tricks, err = decodeInts(parts[7]); err != nil {
+		return r, fmt.Errorf("parsing tricks of hand record %q: %v", encoded, err)
+	}
+	if r.Points, err = decodeInts(parts[8]); err != nil {
+		return r, fmt.Errorf("parsing points of hand record %q: %v", encoded, err)
+	}
+	if len(parts) >= 12 {
+		if r.Seed, err = strconv.ParseInt(parts[11], 10, 64); err != nil {
+			return r, fmt.Errorf("parsing seed of hand record %q: %v", encoded, err)
+		}
+	}
+	if len(parts) == 13 {
+		if r.DealVersion, err = strconv.Atoi(parts[12]); err != nil {
+			return r, fmt.Errorf("parsing deal version of hand record %q: %v", encoded, err)
+		}
+	}
+	return r, nil
+}
+
+// storedHandRecord is the structured form of a HandRecord, kept in storage with the score.
+type storedHandRecord struct {
+	ScoreIndex        int
+	DealerPos         int
+	BidLeadPos        int
+	Bids              []string
+	BidderPos         int
+	WinningBid        string // Empty for a thrown-in hand.
+	Trump             string // Empty for a thrown-in hand.
+	Tricks            []int
+	Points            []int
+	FiveOfHeartsTeam  int
+	ThreeOfSpadesTeam int
+	Seed              int64
+	DealVersion       int
+}
+
+func storedFromHandRecord(r HandRecord) storedHandRecord {
+	st := storedHandRecord{
+		ScoreIndex:        r.ScoreIndex,
+		DealerPos:         r.DealerPos,
+		BidLeadPos:        r.BidLeadPos,
+		BidderPos:         r.BidderPos,
+		Tricks:            r.Tricks,
+		Points:            r.Points,
+		FiveOfHeartsTeam:  r.FiveOfHeartsTeam,
+		ThreeOfSpadesTeam: r.ThreeOfSpadesTeam,
+		Seed:              r.Seed,
+		DealVersion:       r.DealVersion,
+	}
+	for _, b := range r.Bids {
+		st.Bids = append(st.Bids, b.Encoded())
+	}
+	if r.WinningBid != nil {
+		st.WinningBid = r.WinningBid.Encoded()
+	}
+	if r.Trump != nil {
+		st.Trump = r.Trump.Encoded()
+	}
+	return st
+}
+
+func handRecordFromStored(st storedHandRecord) (HandRecord, error) {
+	r := HandRecord{
+		ScoreIndex:        st.ScoreIndex,
+		DealerPos:         st.DealerPos,
+		BidLeadPos:        st.BidLeadPos,
+		BidderPos:         st.BidderPos,
+		Tricks:            st.Tricks,
+		Points:            st.Points,
+		FiveOfHeartsTeam:  st.FiveOfHeartsTeam,
+		ThreeOfSpadesTeam: st.ThreeOfSpadesTeam,
+		Seed:              st.Seed,
+		DealVersion:       st.DealVersion,
+	}
+	for _, eb := range st.Bids {
+		b, err := NewBidFromEncoded(eb)
+		if err != nil {
+			return r, err
+		}
+		r.Bids = append(r.Bids, b)
+	}
+	var err error
+	if st.WinningBid != "" {
+		if r.WinningBid, err = NewBidFromEncoded(st.WinningBid); err != nil {
+			return r, err
+		}
+	}
+	if st.Trump != "" {
+		if r.Trump, err = deck.NewSuitFromEncoded(st.Trump); err != nil {
+			return r, err
+		}
+	}
+	return r, nil
+}
+
+func encodeInts(values []int) string {
+	var parts []string
+	for _, v := range values {
+		parts = append(parts, strconv.Itoa(v))
+	}
+	return strings.Join(parts, ",")
+}
+
+func decodeInts(encoded string) ([]int, error) {
+	if encoded == "" {
+		return nil, nil
+	}
+	var values []int
+	for _, part := range strings.Split(encoded, ",") {
+		v, err := strconv.Atoi(part)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, v)
+	}
+	return values, nil
+}
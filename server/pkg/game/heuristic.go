@@ -0,0 +1,562 @@
+package game
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+
+	"github.com/squee1945/threespot/server/pkg/deck"
+)
+
+// HeuristicLevel is how well a heuristic strategy plays.
+type HeuristicLevel int
+
+const (
+	// EasyLevel judges its hand by its high cards, never bids no trump, and plays each trick without remembering
+	// the cards already played.
+	EasyLevel HeuristicLevel = iota
+	// HardLevel also values long suits and voids, leaves the bid with its partner, bids no trump with a strong
+	// hand, and plays knowing which cards are still out.
+	HardLevel
+)
+
+// noTrumpPoints are the points a hand must be expected to take in no trump before a HardLevel strategy bids it; a no
+// trump bid scores double, made or missed.
+const noTrumpPoints = 9
+
+// NewHeuristicStrategy returns a strategy that bids on an estimate of the points its hand will take, calls its
+// strongest suit, and plays by rules of thumb: protect the 5 of hearts, dump the 3 of spades on the opponents'
+// tricks, and lead trump to draw it when its team has the bid.
+func NewHeuristicStrategy(level HeuristicLevel) Strategy {
+	return &heuristicStrategy{level: level}
+}
+
+type heuristicStrategy struct {
+	level HeuristicLevel
+}
+
+func (s *heuristicStrategy) Pass(view *SeatView) (deck.Card, error) {
+	cards := view.Legal.Pass
+	players := view.Rules.Players()
+	if view.PassTo >= 0 && isPartner(view.Pos, view.PassTo, players) {
+		// Strengthen the partner's hand with the best card outside the suit this player would call.
+		trump, _ := bestContract(view.Hand, trumpSuits, players, s.level, false)
+		var others []deck.Card
+		for _, card := range withoutPointCards(cards) {
+			if card.Suit() != trump {
+				others = append(others, card)
+			}
+		}
+		if len(others) > 0 {
+			return highestCard(others), nil
+		}
+		return lowestCard(withoutPointCards(cards, cards...)), nil
+	}
+	// Hand an opponent the 3 of spades, or else the least useful card.
+	if card := findCard(cards, deck.ThreeOfSpades); card != nil {
+		return card, nil
+	}
+	return lowestCard(withoutPointCards(cards, cards...)), nil
+}
+
+func (s *heuristicStrategy) Bid(view *SeatView) (Bid, error) {
+	players := view.Rules.Players()
+	trump, points := bestContract(view.Hand, trumpSuits, players, s.level, true)
+
+	margin := 0.5
+	if s.level == HardLevel {
+		margin = 0.25
+		if view.Pos == view.DealerPos {
+			// The dealer bids last, knowing what it has to beat.
+			margin = 0
+		}
+		if high, highPos := highestBidAndPos(view); highPos >= 0 && !high.IsPass() && isPartner(view.Pos, highPos, players) {
+			// Leave the bid with the partner.
+			if view.Legal.Bids[0].IsPass() {
+				return view.Legal.Bids[0], nil
+			}
+		}
+		for team, score := range view.Score {
+			if team != TeamOf(view.Pos, players) && score >= view.ToWin-deck.CardsPerHand {
+				// Push the opponents' bids when they are close to winning.
+				margin -= 0.5
+				break
+			}
+		}
+	}
+
+	target := int(math.Floor(points - margin))
+	noTrump := trump == deck.NoTrump
+	var choice Bid
+	for _, b := range view.Legal.Bids {
+		if b.IsPass() || b.IsKaiser() || b.IsNoTrump() != noTrump {
+			continue
+		}
+		v, err := b.Value()
+		if err != nil {
+			return nil, err
+		}
+		if v > target {
+			break
+		}
+		if choice == nil {
+			choice = b
+		}
+		if s.level == HardLevel && view.Pos != view.DealerPos && v < target {
+			// Bid up to a point short of the estimate, to keep the players still to bid out.
+			choice = b
+		}
+	}
+	if choice != nil {
+		return choice, nil
+	}
+	if view.Legal.Bids[0].IsPass() {
+		return view.Legal.Bids[0], nil
+	}
+	// The dealer is stuck with the bid; take the lowest one in a suit.
+	for _, b := range view.Legal.Bids {
+		if !b.IsKaiser() && !b.IsNoTrump() {
+			return b, nil
+		}
+	}
+	return view.Legal.Bids[0], nil
+}
+
+func (s *heuristicStrategy) Trump(view *SeatView) (deck.Suit, error) {
+	trump, _ := bestContract(view.Hand, view.Legal.Trumps, view.Rules.Players(), s.level, false)
+	return trump, nil
+}
+
+func (s *heuristicStrategy) Play(view *SeatView) (deck.Card, error) {
+	legal := view.Legal.Play
+	if len(legal) == 1 {
+		return legal[0], nil
+	}
+	t := newHeuristicTable(view, s.level)
+	if view.Trick == nil || view.Trick.NumPlayed() == 0 || view.Trick.IsDone() {
+		return t.lead(legal), nil
+	}
+	return t.follow(legal), nil
+}
+
+// bestContract returns the suit (deck.NoTrump for no trump, if allowed) in which the cards are expected to take the
+// most points, and those points.
+func bestContract(cards []deck.Card, suits []deck.Suit, players int, level HeuristicLevel, allowNoTrump bool) (deck.Suit, float64) {
+	best, most := suits[0], math.Inf(-1)
+	for _, suit := range suits {
+		if points := estimatePoints(cards, suit, players, level); points > most {
+			best, most = suit, points
+		}
+	}
+	if allowNoTrump && level == HardLevel {
+		if points := estimatePoints(cards, deck.NoTrump, players, level); points >= noTrumpPoints && points > most-0.5 {
+			best, most = deck.NoTrump, points
+		}
+	}
+	return best, most
+}
+
+// estimatePoints returns the points the team of the player holding the cards is expected to take in a hand played
+// with the trump (deck.NoTrump for no trump): a point for each trick, 5 for the 5 of hearts and -3 for the 3 of spades.
+func estimatePoints(cards []deck.Card, trump deck.Suit, players int, level HeuristicLevel) float64 {
+	tricks := estimateTricks(cards, trump, players, level)
+	team := tricks
+	if hasPartners(players) {
+		// The partner is expected to take their share of the rest.
+		team += (deck.CardsPerHand - tricks) / float64(players-1)
+	}
+	share := team / deck.CardsPerHand
+	five, three := share, share
+	if findCard(cards, deck.FiveOfHearts) != nil && hasPartners(players) {
+		// It can be kept for a trick the team wins.
+		five = math.Max(share, 0.6)
+	}
+	if findCard(cards, deck.ThreeOfSpades) != nil {
+		// It can be dumped on a trick the opponents win.
+		three = math.Min(share, 0.25)
+	}
+	return team + 5*five - 3*three
+}
+
+// estimateTricks returns the tricks the cards are expected to take in a hand played with the trump (deck.NoTrump
+// for no trump).
+func estimateTricks(cards []deck.Card, trump deck.Suit, players int, level HeuristicLevel) float64 {
+	out := outstanding(deckCards(players), cards)
+	var tricks, trumpTricks float64
+	trumps := len(cardsOfSuit(cards, trump))
+	for _, suit := range trumpSuits {
+		held := sortHighestFirst(cardsOfSuit(cards, suit))
+		isTrump := suit == trump
+		stopped := false
+		for i, card := range held {
+			above := countHigher(out, card)
+			guards := len(held) - 1 - i
+			v := 0.0
+			switch {
+			case above == 0:
+				v = 1
+				if !isTrump && trump != deck.NoTrump && len(held) > 4 {
+					// A long side suit is likely to be ruffed.
+					v = 0.6
+				}
+			case level == HardLevel && guards >= above:
+				// An honor is worth less for each higher card out, but can be kept back until they fall.
+				v = math.Max(0, 1-0.3*float64(above))
+			}
+			if isTrump {
+				trumpTricks += v
+			} else {
+				tricks += v
+			}
+			stopped = stopped || v > 0
+		}
+		if level == HardLevel && trump == deck.NoTrump && !stopped {
+			// Without a card to stop it, the opponents can run the suit.
+			tricks--
+		}
+		if level == HardLevel && trump == deck.NoTrump && len(held) > 4 && countHigher(out, held[0]) == 0 {
+			// The long cards of an established suit win once the others are out.
+			tricks += 0.6 * float64(len(held)-4)
+		}
+		if level == HardLevel && !isTrump && trump != deck.NoTrump && trumps > 2 {
+			// Short side suits let the trumps ruff.
+			switch len(held) {
+			case 0:
+				trumpTricks += 0.8
+			case 1:
+				trumpTricks += 0.4
+			}
+		}
+	}
+	if trump != deck.NoTrump && trumps > 3 {
+		// The long trumps win once the others are drawn.
+		long := 0.5
+		if level == HardLevel {
+			long = 0.8
+		}
+		trumpTricks += long * float64(trumps-3)
+	}
+	tricks += math.Min(trumpTricks, float64(trumps))
+	return math.Max(0, math.Min(tricks, deck.CardsPerHand))
+}
+
+// heuristicTable is what a heuristic strategy makes of the play so far.
+type heuristicTable struct {
+	view    *SeatView
+	level   HeuristicLevel
+	players int
+	trump   deck.Suit
+	// out are the cards that may still be played by the others. At EasyLevel, it includes the cards already played.
+	out []deck.Card
+}
+
+func newHeuristicTable(view *SeatView, level HeuristicLevel) *heuristicTable {
+	players := view.Rules.Players()
+	t := &heuristicTable{view: view, level: level, players: players, trump: view.Trump}
+	seen := append([]deck.Card(nil), view.Hand...)
+	if level == HardLevel {
+		for _, trick := range view.Tricks {
+			seen = append(seen, trick.Cards()...)
+		}
+		if view.Trick != nil {
+			seen = append(seen, view.Trick.Cards()...)
+		}
+	}
+	t.out = outstanding(deckCards(players), seen)
+	return t
+}
+
+// lead chooses a card to lead.
+func (t *heuristicTable) lead(legal []deck.Card) deck.Card {
+	plain := withoutPointCards(legal)
+
+	// Draw trump while the opponents may hold some, if the team has the bid.
+	if t.trump != deck.NoTrump && t.view.WinningBidPos >= 0 && TeamOf(t.view.WinningBidPos, t.players) == TeamOf(t.view.Pos, t.players) {
+		trumps := cardsOfSuit(plain, t.trump)
+		if len(trumps) > 0 && len(cardsOfSuit(t.out, t.trump)) > 0 {
+			if high := highestCard(trumps); t.isMaster(high) {
+				return high
+			}
+			if t.level == HardLevel && len(trumps) > 2 {
+				return lowestCard(trumps)
+			}
+		}
+	}
+
+	// Cash a winner in a side suit.
+	for _, card := range sortHighestFirst(plain) {
+		if card.Suit() != t.trump && t.isMaster(card) {
+			return card
+		}
+	}
+
+	// Otherwise lead low from the longest side suit, keeping the 5 of hearts and the 3 of spades back.
+	var side []deck.Card
+	for _, card := range plain {
+		if card.Suit() != t.trump {
+			side = append(side, card)
+		}
+	}
+	if len(side) == 0 {
+		side = plain
+	}
+	if len(side) == 0 {
+		// Only the point cards are left; better the 3 of spades goes than the 5 of hearts.
+		if card := findCard(legal, deck.ThreeOfSpades); card != nil {
+			return card
+		}
+		return legal[0]
+	}
+	suit := longestSuit(side, trumpSuits)
+	return lowestCard(cardsOfSuit(side, suit))
+}
+
+// follow chooses a card to play into a trick that has been led.
+func (t *heuristicTable) follow(legal []deck.Card) deck.Card {
+	trick := t.view.Trick
+	lead := trick.Cards()[0].Suit()
+	winning, winningPos := winningCard(trick, t.players)
+	last := trick.NumPlayed() == t.players-1
+	partnerWinning := isPartner(t.view.Pos, winningPos, t.players)
+	// safe is true if the card winning the trick can no longer be beaten.
+	safe := last || t.isMaster(winning) && (winning.Suit() == t.trump || t.trump == deck.NoTrump)
+
+	value := 1
+	if trick.ContainsFiveOfHearts() {
+		value += 5
+	}
+	if trick.ContainsThreeOfSpades() {
+		value -= 3
+	}
+
+	five := findCard(legal, deck.FiveOfHearts)
+	three := findCard(legal, deck.ThreeOfSpades)
+	var winners []deck.Card
+	for _, card := range legal {
+		if beats(t.trump, lead, winning, card) {
+			winners = append(winners, card)
+		}
+	}
+
+	if partnerWinning {
+		// Protect the 5 of hearts by giving it to a trick the team has won.
+		if five != nil && safe {
+			return five
+		}
+		return t.discard(legal, false)
+	}
+
+	// Dump the 3 of spades on a trick the opponents will win.
+	if three != nil && (len(winners) == 0 || value == 1) && (t.level == EasyLevel || safe || trick.NumPlayed() > 1) {
+		return three
+	}
+
+	if value > 0 && len(winners) > 0 {
+		if five != nil && (last || t.isMaster(five)) && beats(t.trump, lead, winning, five) {
+			return five
+		}
+		winners = withoutPointCards(winners, winners...)
+		if last {
+			return cheapestWinner(winners, lead)
+		}
+		if high := highestCard(cardsOfSuit(winners, lead)); high != nil && t.isMaster(high) {
+			return high
+		}
+		if len(cardsOfSuit(winners, lead)) == 0 {
+			// Ruff low; the higher trumps are better kept for drawing.
+			return lowestCard(winners)
+		}
+		if value > 1 || trick.NumPlayed() == t.players-2 {
+			// Play high to win a trick worth the 5 of hearts, or third in hand.
+			return highestCard(winners)
+		}
+	}
+	return t.discard(legal, true)
+}
+
+// discard chooses a card to throw away, keeping the 5 of hearts and trumps. The 3 of spades is thrown away only if
+// dumpThree.
+func (t *heuristicTable) discard(legal []deck.Card, dumpThree bool) deck.Card {
+	if three := findCard(legal, deck.ThreeOfSpades); three != nil && dumpThree {
+		return three
+	}
+	plain := withoutPointCards(legal)
+	if len(plain) == 0 {
+		if t.level == HardLevel && !dumpThree {
+			if five := findCard(legal, deck.FiveOfHearts); five != nil {
+				return five
+			}
+		}
+		return lowestCard(legal)
+	}
+	var side []deck.Card
+	for _, card := range plain {
+		if card.Suit() != t.trump {
+			side = append(side, card)
+		}
+	}
+	if len(side) == 0 {
+		return lowestCard(plain)
+	}
+	if t.level == HardLevel {
+		// Discard from the shortest suit, to be able to ruff it.
+		shortest, fewest := side[0].Suit(), len(side)+1
+		for _, card := range side {
+			if n := len(cardsOfSuit(t.view.Hand, card.Suit())); n < fewest {
+				shortest, fewest = card.Suit(), n
+			}
+		}
+		return lowestCard(cardsOfSuit(side, shortest))
+	}
+	return lowestCard(side)
+}
+
+// isMaster returns true if none of the cards out is higher in the card's suit.
+func (t *heuristicTable) isMaster(card deck.Card) bool {
+	return countHigher(t.out, card) == 0
+}
+
+// winningCard returns the card winning the trick so far, and the position of the player that played it.
+func winningCard(trick Trick, players int) (deck.Card, int) {
+	cards := trick.Cards()
+	best := 0
+	for i := 1; i < len(cards); i++ {
+		if beats(trick.Trump(), cards[0].Suit(), cards[best], cards[i]) {
+			best = i
+		}
+	}
+	return cards[best], (trick.LeadPos() + best) % players
+}
+
+// beats returns true if card b beats card a in a trick with the trump and lead suit.
+func beats(trump, lead deck.Suit, a, b deck.Card) bool {
+	return (&trick{trump: trump}).isHigher(lead, a, b)
+}
+
+// cheapestWinner returns the lowest winner of the lead suit, or else the lowest winner.
+func cheapestWinner(winners []deck.Card, lead deck.Suit) deck.Card {
+	if suited := cardsOfSuit(winners, lead); len(suited) > 0 {
+		return lowestCard(suited)
+	}
+	return lowestCard(winners)
+}
+
+// isPartner returns true if the players in pos and other are different players on the same team.
+func isPartner(pos, other, players int) bool {
+	return pos != other && TeamOf(pos, players) == TeamOf(other, players)
+}
+
+// highestBidAndPos returns the highest bid placed so far and the position of the player that placed it; nil and -1
+// if no bid has been placed.
+func highestBidAndPos(view *SeatView) (Bid, int) {
+	var high Bid
+	highPos := -1
+	for i, b := range view.Bids {
+		if high == nil || high.IsLessThan(b) {
+			high, highPos = b, (view.LeadBidPos+i)%view.Rules.Players()
+		}
+	}
+	return high, highPos
+}
+
+// decks are the cards in the deck for each number of players.
+var decks = func() map[int][]deck.Card {
+	decks := make(map[int][]deck.Card)
+	for players := deck.MinPlayers; players <= deck.MaxPlayers; players++ {
+		d, err := deck.NewDeckForPlayers(players, rand.NewSource(0))
+		if err != nil {
+			continue
+		}
+		for _, hand := range d.Deal() {
+			decks[players] = append(decks[players], hand...)
+		}
+	}
+	return decks
+}()
+
+// deckCards returns all the cards in the deck for the number of players. The cards must not be changed.
+func deckCards(players int) []deck.Card {
+	return decks[players]
+}
+
+// outstanding returns the cards that are not among the seen cards.
+func outstanding(cards, seen []deck.Card) []deck.Card {
+	isSeen := make(map[string]bool, len(seen))
+	for _, card := range seen {
+		isSeen[card.Encoded()] = true
+	}
+	var out []deck.Card
+	for _, card := range cards {
+		if !isSeen[card.Encoded()] {
+			out = append(out, card)
+		}
+	}
+	return out
+}
+
+// countHigher returns the number of the cards that are higher than the card in its suit.
+func countHigher(cards []deck.Card, card deck.Card) int {
+	count := 0
+	for _, c := range cards {
+		if c.Suit() == card.Suit() && isNumHigher(card.Num(), c.Num()) {
+			count++
+		}
+	}
+	return count
+}
+
+// findCard returns the card if it is among the cards, and nil otherwise.
+func findCard(cards []deck.Card, card deck.Card) deck.Card {
+	for _, c := range cards {
+		if c.IsSameAs(card) {
+			return c
+		}
+	}
+	return nil
+}
+
+// cardsOfSuit returns the cards of the suit.
+func cardsOfSuit(cards []deck.Card, suit deck.Suit) []deck.Card {
+	var suited []deck.Card
+	for _, card := range cards {
+		if card.Suit() == suit {
+			suited = append(suited, card)
+		}
+	}
+	return suited
+}
+
+// withoutPointCards returns the cards other than the 5 of hearts and the 3 of spades; the fallback if there are none.
+func withoutPointCards(cards []deck.Card, fallback ...deck.Card) []deck.Card {
+	var plain []deck.Card
+	for _, card := range cards {
+		if !card.IsSameAs(deck.FiveOfHearts) && !card.IsSameAs(deck.ThreeOfSpades) {
+			plain = append(plain, card)
+		}
+	}
+	if len(plain) == 0 {
+		return fallback
+	}
+	return plain
+}
+
+// highestCard returns the card with the highest num; nil if there are no cards.
+func highestCard(cards []deck.Card) deck.Card {
+	if len(cards) == 0 {
+		return nil
+	}
+	highest := cards[0]
+	for _, card := range cards[1:] {
+		if isNumHigher(highest.Num(), card.Num()) {
+			highest = card
+		}
+	}
+	return highest
+}
+
+// sortHighestFirst returns a copy of the cards ordered by num, highest first.
+func sortHighestFirst(cards []deck.Card) []deck.Card {
+	sorted := append([]deck.Card(nil), cards...)
+	sort.SliceStable(sorted, func(i, j int) bool { return isNumHigher(sorted[j].Num(), sorted[i].Num()) })
+	return sorted
+}
@@ -0,0 +1,415 @@
+package game
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/squee1945/threespot/server/pkg/deck"
+)
+
+func TestEstimatePoints(t *testing.T) {
+	testCases := []struct {
+		name          string
+		stronger      []string
+		strongerTrump string
+		weaker        []string
+		weakerTrump   string
+		level         HeuristicLevel
+	}{
+		{
+			name:          "long suit is better as trump",
+			stronger:      []string{"AH", "KH", "QH", "9H", "8H", "9C", "8D", "9S"},
+			strongerTrump: "H",
+			weaker:        []string{"AH", "KH", "QH", "9H", "8H", "9C", "8D", "9S"},
+			weakerTrump:   "C",
+			level:         HardLevel,
+		},
+		{
+			name:          "aces are better than low cards",
+			stronger:      []string{"AH", "AD", "AC", "AS", "8H", "9C", "8D", "9S"},
+			strongerTrump: "H",
+			weaker:        []string{"TH", "TD", "TC", "TS", "8H", "9C", "8D", "9S"},
+			weakerTrump:   "H",
+			level:         EasyLevel,
+		},
+		{
+			name:          "holding the 5 of hearts is worth points",
+			stronger:      []string{"AH", "KH", "5H", "9D", "8D", "9C", "8C", "9S"},
+			strongerTrump: "D",
+			weaker:        []string{"AH", "KH", "8H", "9D", "8D", "9C", "8C", "9S"},
+			weakerTrump:   "D",
+			level:         HardLevel,
+		},
+		{
+			name:          "holding the 3 of spades costs less than not",
+			stronger:      []string{"AS", "KS", "3S", "9D", "8D", "9C", "8C", "9H"},
+			strongerTrump: "S",
+			weaker:        []string{"AS", "KS", "8S", "9D", "8D", "9C", "8C", "9H"},
+			weakerTrump:   "S",
+			level:         HardLevel,
+		},
+		{
+			name:          "voids are worth ruffs at HardLevel",
+			stronger:      []string{"AS", "9S", "8S", "AD", "KD", "9D", "AC", "KC"},
+			strongerTrump: "S",
+			weaker:        []string{"AS", "9S", "8S", "AD", "KD", "9H", "AC", "KC"},
+			weakerTrump:   "S",
+			level:         HardLevel,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			stronger := estimatePoints(buildCards(t, tc.stronger), buildSuit(t, tc.strongerTrump), 4, tc.level)
+			weaker := estimatePoints(buildCards(t, tc.weaker), buildSuit(t, tc.weakerTrump), 4, tc.level)
+
+			if stronger <= weaker {
+				t.Errorf("estimatePoints(%v, %s)=%.2f not more than estimatePoints(%v, %s)=%.2f", tc.stronger, tc.strongerTrump, stronger, tc.weaker, tc.weakerTrump, weaker)
+			}
+		})
+	}
+}
+
+func TestBestContract(t *testing.T) {
+	testCases := []struct {
+		name      string
+		cards     []string
+		level     HeuristicLevel
+		wantTrump string
+	}{
+		{
+			name:      "longest strong suit",
+			cards:     []string{"AS", "KS", "QS", "9S", "8H", "9C", "8D", "9D"},
+			level:     HardLevel,
+			wantTrump: "S",
+		},
+		{
+			name:      "top cards in every suit bid no trump",
+			cards:     []string{"AS", "KS", "AH", "KH", "AD", "KD", "AC", "KC"},
+			level:     HardLevel,
+			wantTrump: "N",
+		},
+		{
+			name:      "never no trump at EasyLevel",
+			cards:     []string{"AS", "KS", "AH", "KH", "AD", "KD", "AC", "KC"},
+			level:     EasyLevel,
+			wantTrump: "H",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, _ := bestContract(buildCards(t, tc.cards), trumpSuits, 4, tc.level, true)
+
+			if got.Encoded() != tc.wantTrump {
+				t.Errorf("bestContract()=%s want=%s", got.Encoded(), tc.wantTrump)
+			}
+		})
+	}
+}
+
+func TestHeuristicBid(t *testing.T) {
+	strong := []string{"AH", "KH", "QH", "JH", "TH", "AD", "AC", "9S"}
+	strongest := []string{"AH", "KH", "QH", "JH", "TH", "AD", "AC", "AS"}
+	weak := []string{"9D", "8D", "7D", "9C", "8C", "7C", "8S", "9S"}
+	testCases := []struct {
+		name      string
+		level     HeuristicLevel
+		cards     []string
+		pos       int
+		dealerPos int
+		bids      []string
+		want      string
+	}{
+		{name: "weak hand passes", level: HardLevel, cards: weak, pos: 0, dealerPos: 3, want: "P"},
+		{name: "weak dealer is stuck with the lowest bid", level: HardLevel, cards: weak, pos: 3, dealerPos: 3, bids: []string{"P", "P", "P"}, want: "7"},
+		{name: "strongest hand bids no trump short of its estimate", level: HardLevel, cards: strongest, pos: 0, dealerPos: 3, want: "8N"},
+		{name: "strong hand bids the lowest bid at EasyLevel", level: EasyLevel, cards: strongest, pos: 0, dealerPos: 3, want: "7"},
+		{name: "dealer takes the bid at the same value", level: HardLevel, cards: strong, pos: 3, dealerPos: 3, bids: []string{"8", "P", "P"}, want: "8"},
+		{name: "leave the bid with the partner", level: HardLevel, cards: strong, pos: 2, dealerPos: 3, bids: []string{"7", "P"}, want: "P"},
+		{name: "overbid the partner at EasyLevel", level: EasyLevel, cards: strong, pos: 2, dealerPos: 3, bids: []string{"7", "P"}, want: "8"},
+		{name: "outbid the opponents", level: HardLevel, cards: strong, pos: 1, dealerPos: 3, bids: []string{"7"}, want: "8"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rules := NewRules()
+			var bids []Bid
+			for _, b := range tc.bids {
+				bids = append(bids, buildBid(t, b))
+			}
+			view := &SeatView{
+				Pos:           tc.pos,
+				Rules:         rules,
+				State:         BiddingState,
+				Legal:         LegalActions{Bids: nextBidValues(rules, bids, tc.pos == tc.dealerPos)},
+				DealerPos:     tc.dealerPos,
+				Hand:          buildCards(t, tc.cards),
+				Score:         []int{0, 0},
+				ToWin:         rules.ToWin(),
+				LeadBidPos:    (tc.dealerPos + 1) % 4,
+				Bids:          bids,
+				WinningBidPos: -1,
+			}
+
+			got, err := NewHeuristicStrategy(tc.level).Bid(view)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got.Encoded() != tc.want {
+				t.Errorf("Bid()=%s want=%s", got.Encoded(), tc.want)
+			}
+		})
+	}
+}
+
+func TestHeuristicPlay(t *testing.T) {
+	testCases := []struct {
+		name          string
+		level         HeuristicLevel
+		pos           int
+		winningBidPos int
+		trick         string
+		tricks        []string
+		cards         []string
+		want          string
+	}{
+		{
+			name:  "dump the 3 of spades on the opponents' trick",
+			level: HardLevel, pos: 3, winningBidPos: 0,
+			trick: "0|C|AD|KD|QD",
+			cards: []string{"5H", "9C", "3S"},
+			want:  "3S",
+		},
+		{
+			name:  "keep the 3 of spades off the partner's trick",
+			level: HardLevel, pos: 3, winningBidPos: 0,
+			trick: "0|C|KD|AD|QD",
+			cards: []string{"9H", "9C", "3S"},
+			want:  "9H",
+		},
+		{
+			name:  "give the 5 of hearts to the partner's trick",
+			level: HardLevel, pos: 3, winningBidPos: 0,
+			trick: "0|C|KD|AD|QD",
+			cards: []string{"5H", "9C", "8S"},
+			want:  "5H",
+		},
+		{
+			name:  "keep the 5 of hearts off the opponents' trick",
+			level: EasyLevel, pos: 3, winningBidPos: 0,
+			trick: "0|C|AD|KD|QD",
+			cards: []string{"5H", "9S", "8S"},
+			want:  "8S",
+		},
+		{
+			name:  "keep the 5 of hearts off a trick the partner may lose",
+			level: HardLevel, pos: 2, winningBidPos: 0,
+			trick: "0|C|QD|KD",
+			cards: []string{"5H", "9S", "8S"},
+			want:  "8S",
+		},
+		{
+			name:  "win with the 5 of hearts as trump",
+			level: HardLevel, pos: 3, winningBidPos: 1,
+			trick: "0|H|AD|KD|QD",
+			cards: []string{"5H", "9S", "8S"},
+			want:  "5H",
+		},
+		{
+			name:  "win the last trick cheaply",
+			level: HardLevel, pos: 3, winningBidPos: 1,
+			trick: "0|S|9D|8D|TD",
+			cards: []string{"QD", "KD", "AD", "9C"},
+			want:  "QD",
+		},
+		{
+			name:  "ruff low",
+			level: HardLevel, pos: 3, winningBidPos: 1,
+			trick: "0|S|9D|8D|TD",
+			cards: []string{"AS", "9S", "9C"},
+			want:  "9S",
+		},
+		{
+			name:  "do not win a trick with the 3 of spades",
+			level: HardLevel, pos: 3, winningBidPos: 1,
+			trick: "0|H|9S|3S|TS",
+			cards: []string{"AS", "8S", "9C"},
+			want:  "8S",
+		},
+		{
+			name:  "lead trump to draw it",
+			level: HardLevel, pos: 0, winningBidPos: 0,
+			trick: "0|S",
+			cards: []string{"AS", "9S", "8S", "KD", "9C"},
+			want:  "AS",
+		},
+		{
+			name:  "lead low trump to draw it",
+			level: HardLevel, pos: 0, winningBidPos: 2,
+			trick: "0|S",
+			cards: []string{"KS", "9S", "8S", "KD", "9C"},
+			want:  "8S",
+		},
+		{
+			name:  "defenders do not lead trump",
+			level: HardLevel, pos: 0, winningBidPos: 1,
+			trick: "0|S",
+			cards: []string{"AS", "9S", "QD", "KD", "9C"},
+			want:  "QD",
+		},
+		{
+			name:  "lead a card that has become the highest at HardLevel",
+			level: HardLevel, pos: 0, winningBidPos: 1,
+			trick:  "0|S",
+			tricks: []string{"1|S|AD|9D|TD|8D"},
+			cards:  []string{"KD", "9C", "8C"},
+			want:   "KD",
+		},
+		{
+			name:  "forget the cards played at EasyLevel",
+			level: EasyLevel, pos: 0, winningBidPos: 1,
+			trick:  "0|S",
+			tricks: []string{"1|S|AD|9D|TD|8D"},
+			cards:  []string{"KD", "9C", "8C"},
+			want:   "8C",
+		},
+		{
+			name:  "keep the 5 of hearts and 3 of spades when leading",
+			level: HardLevel, pos: 0, winningBidPos: 1,
+			trick: "0|C",
+			cards: []string{"5H", "3S", "9D"},
+			want:  "9D",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			trick, err := NewTrickFromEncoded(tc.trick)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var tricks []Trick
+			for _, encoded := range tc.tricks {
+				tr, err := NewTrickFromEncoded(encoded)
+				if err != nil {
+					t.Fatal(err)
+				}
+				tricks = append(tricks, tr)
+			}
+			hand := buildHand(t, tc.cards)
+			// The cards the player may play follow the rules of the engine.
+			e := &Engine{currentTrick: trick}
+			var legal []deck.Card
+			for _, card := range hand.Cards() {
+				if e.followsSuit(hand, card) {
+					legal = append(legal, card)
+				}
+			}
+			view := &SeatView{
+				Pos:           tc.pos,
+				Rules:         NewRules(),
+				State:         PlayingState,
+				Legal:         LegalActions{Play: legal},
+				Hand:          hand.Cards(),
+				WinningBidPos: tc.winningBidPos,
+				Trump:         trick.Trump(),
+				Trick:         trick,
+				Tricks:        tricks,
+			}
+
+			got, err := NewHeuristicStrategy(tc.level).Play(view)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if findCard(legal, got) == nil {
+				t.Fatalf("Play()=%s not in legal cards %v", got.Encoded(), encodeCards(legal))
+			}
+			if got.Encoded() != tc.want {
+				t.Errorf("Play()=%s want=%s", got.Encoded(), tc.want)
+			}
+		})
+	}
+}
+
+func TestHeuristicStrategiesPlayLegally(t *testing.T) {
+	for _, preset := range Presets() {
+		for _, level := range []HeuristicLevel{EasyLevel, HardLevel} {
+			t.Run(fmt.Sprintf("%s/%d", preset.ID, level), func(t *testing.T) {
+				rules, err := NewRulesFromPreset(preset.ID)
+				if err != nil {
+					t.Fatal(err)
+				}
+				e, err := NewEngine(rules, 0, rand.NewSource(int64(level)))
+				if err != nil {
+					t.Fatal(err)
+				}
+				strategy := NewHeuristicStrategy(level)
+
+				for len(e.Score().Scores()) < 10 && e.State() != CompletedState {
+					pos, err := e.PosToPlay()
+					if err != nil {
+						t.Fatal(err)
+					}
+					view, err := e.SeatView(pos)
+					if err != nil {
+						t.Fatal(err)
+					}
+					m, err := ChooseMove(strategy, view)
+					if err != nil {
+						t.Fatal(err)
+					}
+					if m.Action == PlayAction {
+						legal, err := e.LegalCards(pos)
+						if err != nil {
+							t.Fatal(err)
+						}
+						if findCard(legal, m.Card) == nil {
+							t.Fatalf("played %s, not in legal cards %v for trick %s", m.Card.Encoded(), encodeCards(legal), view.Trick.Encoded())
+						}
+					}
+					if err := e.ApplyMove(pos, m); err != nil {
+						t.Fatalf("ApplyMove(%d, %+v) in %v: %v", pos, m, e.State(), err)
+					}
+				}
+			})
+		}
+	}
+}
+
+func TestHardLevelBeatsEasyLevel(t *testing.T) {
+	wins := make([]int, 2)
+	for seed := int64(0); seed < 20; seed++ {
+		e, err := NewEngine(NewRules(), int(seed%4), rand.NewSource(seed))
+		if err != nil {
+			t.Fatal(err)
+		}
+		// Swap the teams' levels every other game.
+		hardTeam := int(seed % 2)
+		strategies := make([]Strategy, 4)
+		for pos := range strategies {
+			strategies[pos] = NewHeuristicStrategy(EasyLevel)
+			if TeamOf(pos, 4) == hardTeam {
+				strategies[pos] = NewHeuristicStrategy(HardLevel)
+			}
+		}
+
+		for e.State() != CompletedState {
+			pos, err := e.PosToPlay()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := e.Act(pos, strategies[pos]); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if e.Score().Winner() == hardTeam {
+			wins[HardLevel]++
+		} else {
+			wins[EasyLevel]++
+		}
+	}
+
+	if wins[HardLevel] <= wins[EasyLevel] {
+		t.Errorf("HardLevel won %d games, EasyLevel won %d", wins[HardLevel], wins[EasyLevel])
+	}
+}
@@ -0,0 +1,126 @@
+package game
+
+import (
+	"github.com/squee1945/threespot/server/pkg/deck"
+)
+
+// trumpSuits are the suits that may be called as trump; a no trump bid skips calling.
+var trumpSuits = []deck.Suit{deck.Hearts, deck.Diamonds, deck.Spades, deck.Clubs}
+
+// LegalActions are the actions a player may take in the current state of the game.
+type LegalActions struct {
+	// Deal is true if the player may deal the next hand.
+	Deal bool
+	// Misdeal is true if the player may claim a misdeal.
+	Misdeal bool
+	// Pass are the cards the player may pass.
+	Pass []deck.Card
+	// Bids are the bids the player may place.
+	Bids []Bid
+	// Trumps are the suits the player may call as trump.
+	Trumps []deck.Suit
+	// Play are the cards the player may play.
+	Play []deck.Card
+}
+
+// IsEmpty returns true if the player has no action to take.
+func (a LegalActions) IsEmpty() bool {
+	return !a.Deal && !a.Misdeal && len(a.Pass) == 0 && len(a.Bids) == 0 && len(a.Trumps) == 0 && len(a.Play) == 0
+}
+
+// LegalCards returns the cards the player in pos may play into the current trick.
+func (e *Engine) LegalCards(pos int) ([]deck.Card, error) {
+	if e.phase() != playingPhase {
+		return nil, ErrNotPlaying
+	}
+	currentTurnPos, err := e.currentTrick.CurrentTurnPos()
+	if err != nil {
+		return nil, err
+	}
+	if pos != currentTurnPos {
+		return nil, ErrIncorrectPlayOrder
+	}
+	playerHand, err := e.currentHands.Hand(pos)
+	if err != nil {
+		return nil, err
+	}
+	legal := e.legalCards(playerHand)
+	if legal == 0 {
+		return nil, nil
+	}
+	return legal.appendCards(make([]deck.Card, 0, legal.count())), nil
+}
+
+// legalCards returns the cards of the hand that may be played into the current trick.
+func (e *Engine) legalCards(playerHand Hand) cardSet {
+	legal := playerHand.held()
+	if e.currentTrick != nil && e.currentTrick.NumPlayed() > 0 {
+		if leadSuit, err := e.currentTrick.LeadSuit(); err == nil && legal&suitSet(leadSuit) != 0 {
+			legal &= suitSet(leadSuit)
+		}
+	}
+	return legal
+}
+
+// LegalActions returns what the player in pos may do now; it is empty if the player has nothing to do.
+func (e *Engine) LegalActions(pos int) (LegalActions, error) {
+	var actions LegalActions
+	err := e.FillLegalActions(pos, &actions)
+	return actions, err
+}
+
+// FillLegalActions sets actions to what the player in pos may do now, as LegalActions does. The slices of actions
+// are reused, so a caller that fills the same actions for each move does not allocate once they have grown.
+func (e *Engine) FillLegalActions(pos int, actions *LegalActions) error {
+	*actions = LegalActions{
+		Pass:   actions.Pass[:0],
+		Bids:   actions.Bids[:0],
+		Trumps: actions.Trumps[:0],
+		Play:   actions.Play[:0],
+	}
+	if e.rules.Misdeal() != NoMisdeals {
+		if _, err := e.checkMisdeal(pos); err == nil {
+			actions.Misdeal = true
+		}
+	}
+
+	p := e.phase()
+	if p == completedPhase {
+		return nil
+	}
+	currentTurnPos, err := e.posToPlay(p)
+	if err != nil {
+		return err
+	}
+	if pos != currentTurnPos {
+		return nil
+	}
+
+	switch p {
+	case dealingPhase:
+		actions.Deal = true
+	case passingPhase:
+		playerHand, err := e.currentHands.Hand(pos)
+		if err != nil {
+			return err
+		}
+		actions.Pass = playerHand.held().appendCards(actions.Pass)
+	case biddingPhase:
+		actions.Bids = appendNextBidValues(actions.Bids, e.rules, e.currentBidding.Bids(), pos == e.currentDealerPos)
+	case callingPhase:
+		actions.Trumps = append(actions.Trumps, trumpSuits...)
+	case playingPhase:
+		playerHand, err := e.currentHands.Hand(pos)
+		if err != nil {
+			return err
+		}
+		actions.Play = e.legalCards(playerHand).appendCards(actions.Play)
+	}
+	return nil
+}
+
+// followsSuit returns true if the card may be played from the hand into the current trick: it follows the
+// lead suit, or the hand has no card of the lead suit.
+func (e *Engine) followsSuit(playerHand Hand, card deck.Card) bool {
+	return e.legalCards(playerHand)&cardBit(card) != 0
+}
@@ -0,0 +1,299 @@
+package game
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/squee1945/threespot/server/pkg/deck"
+	"github.com/squee1945/threespot/server/pkg/storage"
+)
+
+func TestLegalCards(t *testing.T) {
+	pids := []string{"ABE", "BOB", "CAL", "DON"}
+	hands := "AH|KH|7D+AS|KS+AC|KC+AD|KD"
+	testCases := []struct {
+		name    string
+		gs      *storage.Game
+		pid     string
+		want    []string
+		wantErr error
+	}{
+		{
+			name: "not playing",
+			gs: &storage.Game{
+				PlayerIDs:      pids,
+				CurrentHands:   hands,
+				CurrentBidding: "0|P|P|P|7",
+			},
+			pid:     "DON",
+			wantErr: ErrNotPlaying,
+		},
+		{
+			name: "lead",
+			gs: &storage.Game{
+				PlayerIDs:      pids,
+				CurrentHands:   hands,
+				CurrentBidding: "0|P|P|P|7",
+				CurrentTrick:   "0|H",
+			},
+			pid:  "ABE",
+			want: []string{"7D", "AH", "KH"},
+		},
+		{
+			name: "must follow suit",
+			gs: &storage.Game{
+				PlayerIDs:      pids,
+				CurrentHands:   hands,
+				CurrentBidding: "0|P|P|P|7",
+				CurrentTrick:   "3|H|AD",
+			},
+			pid:  "ABE",
+			want: []string{"7D"},
+		},
+		{
+			name: "void in lead suit",
+			gs: &storage.Game{
+				PlayerIDs:      pids,
+				CurrentHands:   hands,
+				CurrentBidding: "0|P|P|P|7",
+				CurrentTrick:   "1|H|AS",
+			},
+			pid:  "CAL",
+			want: []string{"AC", "KC"},
+		},
+		{
+			name: "out of order",
+			gs: &storage.Game{
+				PlayerIDs:      pids,
+				CurrentHands:   hands,
+				CurrentBidding: "0|P|P|P|7",
+				CurrentTrick:   "3|H",
+			},
+			pid:     "ABE",
+			wantErr: ErrIncorrectPlayOrder,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			g, _, playerStore := buildGame(t, tc.gs)
+			player := getPlayer(t, playerStore, tc.pid)
+
+			got, err := g.LegalCards(player)
+
+			if err != tc.wantErr {
+				t.Fatalf("LegalCards()=%v want=%v", err, tc.wantErr)
+			}
+			if diff := cmp.Diff(tc.want, sortedCards(got)); diff != "" {
+				t.Errorf("LegalCards() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestLegalActions(t *testing.T) {
+	pids := []string{"ABE", "BOB", "CAL", "DON"}
+	weakHand := "7H|8H|9H|7S|8S|9S|7D|8D"
+	testCases := []struct {
+		name        string
+		gs          *storage.Game
+		pid         string
+		wantDeal    bool
+		wantMisdeal bool
+		wantBids    []string
+		wantTrumps  []string
+		wantPlay    []string
+	}{
+		{
+			name: "joining",
+			gs: &storage.Game{
+				PlayerIDs: []string{"ABE", "BOB", "", ""},
+			},
+			pid: "ABE",
+		},
+		{
+			name: "dealer",
+			gs: &storage.Game{
+				PlayerIDs:        pids,
+				CurrentDealerPos: 1,
+			},
+			pid:      "BOB",
+			wantDeal: true,
+		},
+		{
+			name: "not dealer",
+			gs: &storage.Game{
+				PlayerIDs:        pids,
+				CurrentDealerPos: 1,
+			},
+			pid: "ABE",
+		},
+		{
+			name: "bidder",
+			gs: &storage.Game{
+				PlayerIDs:        pids,
+				CurrentBidding:   "2|C|P|P",
+				CurrentDealerPos: 1,
+				CurrentHands:     "AH+AS+AD+AC",
+			},
+			pid:      "BOB",
+			wantBids: []string{"P", "C", "CN", "K"},
+		},
+		{
+			name: "misdeal out of turn",
+			gs: &storage.Game{
+				PlayerIDs:      pids,
+				CurrentBidding: "1|",
+				CurrentHands:   weakHand + "+AS+AD+AC",
+				Rules:          storage.Rules{Misdeal: "noface"},
+			},
+			pid:         "ABE",
+			wantMisdeal: true,
+		},
+		{
+			name: "caller",
+			gs: &storage.Game{
+				PlayerIDs:      pids,
+				CurrentHands:   "AH|KH|7D+AS|KS+AC|KC+AD|KD",
+				CurrentBidding: "0|P|P|P|7",
+			},
+			pid:        "DON",
+			wantTrumps: []string{"H", "D", "S", "C"},
+		},
+		{
+			name: "player",
+			gs: &storage.Game{
+				PlayerIDs:      pids,
+				CurrentHands:   "AH|KH|7D+AS|KS+AC|KC+AD|KD",
+				CurrentBidding: "0|P|P|P|7",
+				CurrentTrick:   "3|H|AD",
+			},
+			pid:      "ABE",
+			wantPlay: []string{"7D"},
+		},
+		{
+			name: "waiting to play",
+			gs: &storage.Game{
+				PlayerIDs:      pids,
+				CurrentHands:   "AH|KH|7D+AS|KS+AC|KC+AD|KD",
+				CurrentBidding: "0|P|P|P|7",
+				CurrentTrick:   "3|H|AD",
+			},
+			pid: "BOB",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			g, _, playerStore := buildGame(t, tc.gs)
+			player := getPlayer(t, playerStore, tc.pid)
+
+			got, err := g.LegalActions(player)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got.Deal != tc.wantDeal {
+				t.Errorf("Deal=%t want=%t", got.Deal, tc.wantDeal)
+			}
+			if got.Misdeal != tc.wantMisdeal {
+				t.Errorf("Misdeal=%t want=%t", got.Misdeal, tc.wantMisdeal)
+			}
+			var gotBids []string
+			for _, b := range got.Bids {
+				gotBids = append(gotBids, b.Encoded())
+			}
+			if diff := cmp.Diff(tc.wantBids, gotBids); diff != "" {
+				t.Errorf("Bids mismatch (-want +got):\n%s", diff)
+			}
+			var gotTrumps []string
+			for _, s := range got.Trumps {
+				gotTrumps = append(gotTrumps, s.Encoded())
+			}
+			if diff := cmp.Diff(tc.wantTrumps, gotTrumps); diff != "" {
+				t.Errorf("Trumps mismatch (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.wantPlay, sortedCards(got.Play)); diff != "" {
+				t.Errorf("Play mismatch (-want +got):\n%s", diff)
+			}
+			if got, want := got.IsEmpty(), !tc.wantDeal && !tc.wantMisdeal && tc.wantBids == nil && tc.wantTrumps == nil && tc.wantPlay == nil; got != want {
+				t.Errorf("IsEmpty()=%t want=%t", got, want)
+			}
+		})
+	}
+}
+
+// sortedCards returns the encoded cards, sorted.
+func sortedCards(cards []deck.Card) []string {
+	var encoded []string
+	for _, c := range cards {
+		encoded = append(encoded, c.Encoded())
+	}
+	sort.Strings(encoded)
+	return encoded
+}
+
+func TestFillLegalActions(t *testing.T) {
+	e, err := NewEngine(NewRules(), 0, rand.NewSource(3))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// One set of actions is filled for every player at every step of a game, as a simulation would.
+	var filled LegalActions
+	for e.State() != CompletedState {
+		for pos := 0; pos < 4; pos++ {
+			want, err := e.LegalActions(pos)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := e.FillLegalActions(pos, &filled); err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(encodeLegalActions(want), encodeLegalActions(filled)); diff != "" {
+				t.Fatalf("FillLegalActions(%d) in %s mismatch (-want +got):\n%s", pos, e.State(), diff)
+			}
+		}
+		playEngineAction(t, e)
+	}
+
+	// Once the actions have grown, filling them does not allocate.
+	e, err = NewEngine(NewRules(), 0, rand.NewSource(3))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for e.State() != PlayingState {
+		playEngineAction(t, e)
+	}
+	pos, err := e.PosToPlay()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if allocs := testing.AllocsPerRun(10, func() {
+		if err := e.FillLegalActions(pos, &filled); err != nil {
+			t.Fatal(err)
+		}
+	}); allocs != 0 {
+		t.Errorf("FillLegalActions() allocs=%v want=0", allocs)
+	}
+}
+
+// encodeLegalActions returns the actions in encoded form, for comparison.
+func encodeLegalActions(a LegalActions) []string {
+	encoded := []string{fmt.Sprintf("deal=%t misdeal=%t", a.Deal, a.Misdeal)}
+	for _, c := range a.Pass {
+		encoded = append(encoded, "pass "+c.Encoded())
+	}
+	for _, b := range a.Bids {
+		encoded = append(encoded, "bid "+b.Encoded())
+	}
+	for _, s := range a.Trumps {
+		encoded = append(encoded, "trump "+s.Encoded())
+	}
+	for _, c := range a.Play {
+		encoded = append(encoded, "play "+c.Encoded())
+	}
+	return encoded
+}
@@ -0,0 +1,30 @@
+package game
+
+import (
+	"github.com/squee1945/threespot/server/pkg/deck"
+)
+
+// misdealNote returns the score note explaining why a hand was redealt, and false if the hand does not qualify for the rule.
+func misdealNote(rule MisdealRule, hand Hand) (string, bool) {
+	if hand == nil || hand.IsEmpty() {
+		return "", false
+	}
+	switch rule {
+	case MisdealNoFaceCards:
+		for _, card := range hand.Cards() {
+			switch card.Num() {
+			case "J", "Q", "K":
+				return "", false
+			}
+		}
+		return "misdeal, no face cards", true
+	case MisdealNoAcesOrSpecials:
+		for _, card := range hand.Cards() {
+			if card.Num() == "A" || card.IsSameAs(deck.FiveOfHearts) || card.IsSameAs(deck.ThreeOfSpades) {
+				return "", false
+			}
+		}
+		return "misdeal, no aces or special cards", true
+	}
+	return "", false
+}
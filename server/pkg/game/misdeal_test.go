@@ -0,0 +1,74 @@
+package game
+
+import (
+	"testing"
+)
+
+func TestMisdealNote(t *testing.T) {
+	testCases := []struct {
+		name     string
+		rule     MisdealRule
+		hand     []string
+		wantNote string
+		wantOK   bool
+	}{
+		{
+			name: "misdeals not allowed",
+			rule: NoMisdeals,
+			hand: []string{"7H", "8H", "9H", "7S", "8S", "9S", "7D", "8D"},
+		},
+		{
+			name: "empty hand",
+			rule: MisdealNoFaceCards,
+		},
+		{
+			name:     "no face cards",
+			rule:     MisdealNoFaceCards,
+			hand:     []string{"AH", "8H", "9H", "TS", "8S", "9S", "7D", "8D"},
+			wantNote: "misdeal, no face cards",
+			wantOK:   true,
+		},
+		{
+			name: "has a jack",
+			rule: MisdealNoFaceCards,
+			hand: []string{"7H", "8H", "9H", "JS", "8S", "9S", "7D", "8D"},
+		},
+		{
+			name:     "no aces or special cards",
+			rule:     MisdealNoAcesOrSpecials,
+			hand:     []string{"KH", "8H", "9H", "QS", "8S", "9S", "7D", "8D"},
+			wantNote: "misdeal, no aces or special cards",
+			wantOK:   true,
+		},
+		{
+			name: "has an ace",
+			rule: MisdealNoAcesOrSpecials,
+			hand: []string{"7H", "8H", "9H", "7S", "8S", "9S", "7D", "AC"},
+		},
+		{
+			name: "has the 5 of hearts",
+			rule: MisdealNoAcesOrSpecials,
+			hand: []string{"5H", "8H", "9H", "7S", "8S", "9S", "7D", "8D"},
+		},
+		{
+			name: "has the 3 of spades",
+			rule: MisdealNoAcesOrSpecials,
+			hand: []string{"7H", "8H", "9H", "3S", "8S", "9S", "7D", "8D"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			hand := buildHand(t, tc.hand)
+
+			note, ok := misdealNote(tc.rule, hand)
+
+			if got, want := ok, tc.wantOK; got != want {
+				t.Errorf("misdealNote() ok=%t want=%t", got, want)
+			}
+			if got, want := note, tc.wantNote; got != want {
+				t.Errorf("misdealNote() note=%q want=%q", got, want)
+			}
+		})
+	}
+}
@@ -11,7 +11,7 @@ import (
 
 // PassingRound round is a collection of passed cards.
 type PassingRound interface {
-	// IsDone returns true if the passing is complete (4 cards).
+	// IsDone returns true if the passing is complete (every passer has passed PerPlayer cards).
 	IsDone() bool
 
 	// passCard passes a card for the player in playerPos position.
@@ -23,23 +23,64 @@ type PassingRound interface {
 	// LeadPos returns the position of the lead player.
 	LeadPos() int
 
-	// Cards returns the cards passed; the first card is for the LeadPos, clockwise from there.
+	// PerPlayer returns the number of cards each passer passes.
+	PerPlayer() int
+
+	// Passers returns the positions of the players that pass, in passing order.
+	Passers() []int
+
+	// ToPos returns the position of the player that receives the cards passed by the player in playerPos.
+	ToPos(playerPos int) int
+
+	// Cards returns the cards passed; the first PerPlayer cards are for the LeadPos, then the next passer, and so on.
 	Cards() []deck.Card
 
 	// NumPassed returns the number of cards passed.
 	NumPassed() int
 
-	// FromPlayer returns the card that was passed from a given player. May only be called when passing IsDone.
-	FromPlayer(playerPos int) (deck.Card, error)
+	// NumPassedBy returns the number of cards passed so far by the player in playerPos.
+	NumPassedBy(playerPos int) int
+
+	// FromPlayer returns the cards that were passed from a given player. May only be called when passing IsDone.
+	FromPlayer(playerPos int) ([]deck.Card, error)
+
+	// ReceivedBy returns the cards that were passed to a given player; empty if the player receives no cards.
+	// May only be called when passing IsDone.
+	ReceivedBy(playerPos int) ([]deck.Card, error)
 
 	// Encoded returns the passed cards encoded into a single string.
 	Encoded() string
+
+	// clone returns a copy of the passed cards that can be changed independently.
+	clone() PassingRound
+
+	// stored returns the structured form of the passed cards, for storage.
+	stored() storedPassing
 }
 
+const (
+	// defaultPassPerPlayer is the number of cards passed by each player.
+	defaultPassPerPlayer = 1
+	// defaultPassOffset passes cards across the table, to partners.
+	defaultPassOffset = 2
+	// defaultPassStep has every player pass.
+	defaultPassStep = 1
+	// maxPassPerPlayer is the most cards a player may pass.
+	maxPassPerPlayer = 3
+)
+
 type passingRound struct {
-	// leadPos is the position (0..3) of the leadoff passer.
+	// leadPos is the position (0..players-1) of the leadoff passer.
 	leadPos int
-	// cards are the cards passed. cards[0] is the card passed by the player in leadPos.
+	// players is the number of players at the table.
+	players int
+	// perPlayer is the number of cards each passer passes.
+	perPlayer int
+	// offset is the receiving position relative to the passer; 1 is left, players-1 is right, players/2 is across.
+	offset int
+	// step is the distance between passers; 1 if every player passes, players/2 if only the leadPos and partner pass.
+	step int
+	// cards are the cards passed. cards[0:perPlayer] are the cards passed by the player in leadPos.
 	cards []deck.Card
 }
 
@@ -48,6 +89,10 @@ var _ PassingRound = (*passingRound)(nil) // Ensure interface is implemented.
 // NewPassingRoundFromEncoded returns a set of bigs from the Encoded() form.
 func NewPassingRoundFromEncoded(encoded string) (PassingRound, error) {
 	// "{leadPos}|{card0}|{card1}|{card2}|{card3}"
+	// If the passing is not the default (everyone passes one card across), the leadPos is followed by the options:
+	// "{leadPos}/{perPlayer}/{offset}/{step}|{card0}|..."
+	// If there are not four players, the number of players is added to the options:
+	// "{leadPos}/{perPlayer}/{offset}/{step}/{players}|{card0}|..."
 	if encoded == "" {
 		encoded = "0|"
 	}
@@ -55,22 +100,38 @@ func NewPassingRoundFromEncoded(encoded string) (PassingRound, error) {
 	if len(parts) < 1 {
 		return nil, fmt.Errorf("encoded %q has too few parts", encoded)
 	}
-	if len(parts) > 5 {
-		return nil, fmt.Errorf("encoded %q has too many parts", encoded)
-	}
 
-	leadPos, err := strconv.Atoi(parts[0])
-	if err != nil {
-		return nil, fmt.Errorf("encoded part[0] %q was not an int: %v", parts[0], err)
+	header := strings.Split(parts[0], "/")
+	if len(header) != 1 && len(header) != 4 && len(header) != 5 {
+		return nil, fmt.Errorf("encoded part[0] %q must have one, four or five parts", parts[0])
+	}
+	var values []int
+	for _, h := range header {
+		v, err := strconv.Atoi(h)
+		if err != nil {
+			return nil, fmt.Errorf("encoded part[0] %q was not an int: %v", parts[0], err)
+		}
+		values = append(values, v)
+	}
+	perPlayer, offset, step, players := defaultPassPerPlayer, defaultPassOffset, defaultPassStep, defaultPlayers
+	if len(values) >= 4 {
+		perPlayer, offset, step = values[1], values[2], values[3]
+	}
+	if len(values) == 5 {
+		players = values[4]
 	}
 
-	prr, err := NewPassingRound(leadPos)
+	prr, err := newPassingRound(values[0], perPlayer, offset, step, players)
 	if err != nil {
 		return nil, err
 	}
 
 	pr := prr.(*passingRound)
 
+	if len(parts)-1 > pr.numCards() {
+		return nil, fmt.Errorf("encoded %q has too many parts", encoded)
+	}
+
 	for _, cstr := range parts[1:] {
 		if cstr == "" {
 			continue
@@ -84,38 +145,143 @@ func NewPassingRoundFromEncoded(encoded string) (PassingRound, error) {
 	return pr, nil
 }
 
-// NewPassingRound creates a new passing round starting with the player in leadPos.
+// storedPassing is the structured form of a passing round, kept in storage.
+type storedPassing struct {
+	LeadPos   int
+	Players   int
+	PerPlayer int
+	Offset    int
+	Step      int
+	Cards     []string // The cards passed, PerPlayer for each passer, starting with the player in LeadPos.
+}
+
+// newPassingRoundFromStored builds a passing round from the stored() form.
+func newPassingRoundFromStored(st storedPassing) (PassingRound, error) {
+	prr, err := newPassingRound(st.LeadPos, st.PerPlayer, st.Offset, st.Step, st.Players)
+	if err != nil {
+		return nil, err
+	}
+	pr := prr.(*passingRound)
+	if len(st.Cards) > pr.numCards() {
+		return nil, fmt.Errorf("too many passed cards in %v", st.Cards)
+	}
+	if pr.cards, err = decodeCards(st.Cards); err != nil {
+		return nil, err
+	}
+	return pr, nil
+}
+
+// NewPassingRound creates a new passing round for four players starting with the player in leadPos,
+// where each player passes one card to their partner.
 func NewPassingRound(leadPos int) (PassingRound, error) {
-	if leadPos < 0 || leadPos > 3 {
-		return nil, errors.New("leadPos must be on the interval [0,3]")
+	return newPassingRound(leadPos, defaultPassPerPlayer, defaultPassOffset, defaultPassStep, defaultPlayers)
+}
+
+// newPassingRoundForRules creates a passing round before bidding starting with the player in leadPos.
+// handNum is the number of hands already played, used to rotate the passing direction.
+// Without partners, there is no one across the table; passes go left instead, and rotating passes alternate left and right.
+func newPassingRoundForRules(rules Rules, leadPos, handNum int) (PassingRound, error) {
+	players := rules.Players()
+	left, right, across := 1, players-1, players/2
+	if !hasPartners(players) {
+		across = left
+	}
+	offset := across
+	switch rules.PassDirection() {
+	case PassLeft:
+		offset = left
+	case PassRight:
+		offset = right
+	case PassRotate:
+		// Left, right, then across.
+		if hasPartners(players) {
+			offset = []int{left, right, across}[handNum%3]
+		} else {
+			offset = []int{left, right}[handNum%2]
+		}
+	}
+	return newPassingRound(leadPos, rules.PassCount(), offset, defaultPassStep, players)
+}
+
+// newPartnerExchange creates a passing round where the player in leadPos and their partner exchange cards.
+func newPartnerExchange(leadPos, perPlayer, players int) (PassingRound, error) {
+	if !hasPartners(players) {
+		return nil, errors.New("cannot exchange cards without partners")
+	}
+	return newPassingRound(leadPos, perPlayer, players/2, players/2, players)
+}
+
+func newPassingRound(leadPos, perPlayer, offset, step, players int) (PassingRound, error) {
+	if players < deck.MinPlayers || players > deck.MaxPlayers {
+		return nil, fmt.Errorf("players must be on the interval [%d,%d]", deck.MinPlayers, deck.MaxPlayers)
+	}
+	if leadPos < 0 || leadPos >= players {
+		return nil, fmt.Errorf("leadPos must be on the interval [0,%d]", players-1)
+	}
+	if perPlayer < 1 || perPlayer > maxPassPerPlayer {
+		return nil, fmt.Errorf("perPlayer must be on the interval [1,%d]", maxPassPerPlayer)
 	}
-	return &passingRound{leadPos: leadPos}, nil
+	if offset < 1 || offset >= players {
+		return nil, fmt.Errorf("offset must be on the interval [1,%d]", players-1)
+	}
+	if step != 1 && (step != players/2 || !hasPartners(players)) {
+		return nil, fmt.Errorf("step must be 1 or %d", players/2)
+	}
+	return &passingRound{leadPos: leadPos, players: players, perPlayer: perPlayer, offset: offset, step: step}, nil
 }
 
 func (r *passingRound) IsDone() bool {
-	return r.NumPassed() == 4
+	return r.NumPassed() == r.numCards()
 }
 
 func (r *passingRound) passCard(playerPos int, card deck.Card) error {
-	ord := r.toOrd(playerPos)
-	if len(r.cards) != ord {
+	pos, err := r.CurrentTurnPos()
+	if err != nil {
+		return err
+	}
+	if pos != playerPos {
+		if r.NumPassedBy(playerPos) == r.perPlayer {
+			return ErrAlreadyPassed
+		}
 		return ErrIncorrectPassOrder
 	}
 	r.cards = append(r.cards, card)
 	return nil
 }
 
+func (r *passingRound) clone() PassingRound {
+	c := *r
+	c.cards = append([]deck.Card(nil), r.cards...)
+	return &c
+}
+
 func (r *passingRound) CurrentTurnPos() (int, error) {
 	if r.IsDone() {
 		return -1, fmt.Errorf("passing is complete")
 	}
-	return (r.leadPos + len(r.cards)) % 4, nil
+	return r.toPos(len(r.cards) / r.perPlayer), nil
 }
 
 func (r *passingRound) LeadPos() int {
 	return r.leadPos
 }
 
+func (r *passingRound) PerPlayer() int {
+	return r.perPlayer
+}
+
+func (r *passingRound) Passers() []int {
+	var passers []int
+	for ord := 0; ord < r.numPassers(); ord++ {
+		passers = append(passers, r.toPos(ord))
+	}
+	return passers
+}
+
+func (r *passingRound) ToPos(playerPos int) int {
+	return (playerPos + r.offset) % r.players
+}
+
 func (r *passingRound) Cards() []deck.Card {
 	return r.cards
 }
@@ -124,17 +290,52 @@ func (r *passingRound) NumPassed() int {
 	return len(r.cards)
 }
 
-func (r *passingRound) FromPlayer(playerPos int) (deck.Card, error) {
+func (r *passingRound) NumPassedBy(playerPos int) int {
+	ord := r.toOrd(playerPos)
+	if ord < 0 {
+		return 0
+	}
+	n := len(r.cards) - ord*r.perPlayer
+	if n < 0 {
+		return 0
+	}
+	if n > r.perPlayer {
+		return r.perPlayer
+	}
+	return n
+}
+
+func (r *passingRound) FromPlayer(playerPos int) ([]deck.Card, error) {
 	if !r.IsDone() {
 		return nil, errors.New("passing is not complete")
 	}
-	for i, card := range r.cards {
-		if (r.leadPos+i)%4 == playerPos {
-			return card, nil
-		}
+	ord := r.toOrd(playerPos)
+	if ord < 0 {
+		return nil, fmt.Errorf("player %d does not pass", playerPos)
+	}
+	return r.cards[ord*r.perPlayer : (ord+1)*r.perPlayer], nil
+}
+
+func (r *passingRound) ReceivedBy(playerPos int) ([]deck.Card, error) {
+	if !r.IsDone() {
+		return nil, errors.New("passing is not complete")
+	}
+	fromPos := (playerPos + r.players - r.offset) % r.players
+	if r.toOrd(fromPos) < 0 {
+		return nil, nil
+	}
+	return r.FromPlayer(fromPos)
+}
+
+func (r *passingRound) stored() storedPassing {
+	return storedPassing{
+		LeadPos:   r.leadPos,
+		Players:   r.players,
+		PerPlayer: r.perPlayer,
+		Offset:    r.offset,
+		Step:      r.step,
+		Cards:     encodeCards(r.cards),
 	}
-	// Should not happen.
-	return nil, errors.New("unexpectedly reached end of passed cards")
 }
 
 func (r *passingRound) Encoded() string {
@@ -142,15 +343,35 @@ func (r *passingRound) Encoded() string {
 	for _, card := range r.cards {
 		parts = append(parts, card.Encoded())
 	}
-	return strconv.Itoa(r.leadPos) + "|" + strings.Join(parts, "|")
+	header := strconv.Itoa(r.leadPos)
+	if r.players != defaultPlayers {
+		header = fmt.Sprintf("%d/%d/%d/%d/%d", r.leadPos, r.perPlayer, r.offset, r.step, r.players)
+	} else if r.perPlayer != defaultPassPerPlayer || r.offset != defaultPassOffset || r.step != defaultPassStep {
+		header = fmt.Sprintf("%d/%d/%d/%d", r.leadPos, r.perPlayer, r.offset, r.step)
+	}
+	return header + "|" + strings.Join(parts, "|")
 }
 
-// toOrd returns the player order for this pass (0..3), computed from the leadPos.
+// numPassers returns the number of players that pass.
+func (r *passingRound) numPassers() int {
+	return r.players / r.step
+}
+
+// numCards returns the number of cards passed when the passing is done.
+func (r *passingRound) numCards() int {
+	return r.numPassers() * r.perPlayer
+}
+
+// toOrd returns the passing order (0..numPassers-1) for the player, computed from the leadPos. Returns -1 if the player does not pass.
 func (r *passingRound) toOrd(playerPos int) int {
-	return (playerPos + 4 - r.leadPos) % 4
+	dist := (playerPos + r.players - r.leadPos) % r.players
+	if dist%r.step != 0 {
+		return -1
+	}
+	return dist / r.step
 }
 
-// toPos returns the player position for this pass (0..3), computed from the leadPos.
+// toPos returns the player position for this passing order, computed from the leadPos.
 func (r *passingRound) toPos(playerOrd int) int {
-	return (r.leadPos + playerOrd) % 4
+	return (r.leadPos + playerOrd*r.step) % r.players
 }
@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
 	"github.com/squee1945/threespot/server/pkg/deck"
 )
 
@@ -63,6 +64,43 @@ func TestNewPassingRoundFromEncoded(t *testing.T) {
 			encoded: "0|7C|8C|9C|TC|JC",
 			wantErr: true,
 		},
+		{
+			name:        "two passed cards each",
+			encoded:     "2/2/1/1|7C|8C|9C",
+			wantLeadPos: 2,
+			wantCards:   []deck.Card{buildCard(t, "7C"), buildCard(t, "8C"), buildCard(t, "9C")},
+		},
+		{
+			name:        "partner exchange",
+			encoded:     "1/3/2/2|7C|8C|9C|TC|JC|QC",
+			wantLeadPos: 1,
+			wantCards:   buildCards(t, []string{"7C", "8C", "9C", "TC", "JC", "QC"}),
+		},
+		{
+			name:    "partner exchange too many cards",
+			encoded: "1/1/2/2|7C|8C|9C",
+			wantErr: true,
+		},
+		{
+			name:    "malformed options",
+			encoded: "1/1/2|7C",
+			wantErr: true,
+		},
+		{
+			name:    "too many cards per player",
+			encoded: "1/4/2/1|",
+			wantErr: true,
+		},
+		{
+			name:    "invalid offset",
+			encoded: "1/1/0/1|",
+			wantErr: true,
+		},
+		{
+			name:    "invalid step",
+			encoded: "1/1/2/3|",
+			wantErr: true,
+		},
 	}
 
 	for _, tc := range testCases {
@@ -151,6 +189,8 @@ func TestIsDoneAndNumPassed(t *testing.T) {
 		{"0|7C|8C", false, 2},
 		{"0|7C|8C|9C", false, 3},
 		{"0|7C|8C|9C|TC", true, 4},
+		{"0/2/1/1|7C|8C|9C|TC", false, 4},
+		{"0/2/2/2|7C|8C|9C|TC", true, 4},
 	}
 	for i, tc := range testCases {
 		t.Run(fmt.Sprintf("case %d", i), func(t *testing.T) {
@@ -197,6 +237,21 @@ func TestCurrentTurnPos(t *testing.T) {
 			encoded: "3|7C|8C",
 			want:    1,
 		},
+		{
+			name:    "second card of two",
+			encoded: "3/2/1/1|7C",
+			want:    3,
+		},
+		{
+			name:    "next player after two",
+			encoded: "3/2/1/1|7C|8C",
+			want:    0,
+		},
+		{
+			name:    "partner exchange",
+			encoded: "3/1/2/2|7C",
+			want:    1,
+		},
 	}
 
 	for _, tc := range testCases {
@@ -220,7 +275,7 @@ func TestFromPlayer(t *testing.T) {
 		name    string
 		encoded string
 		pos     int
-		want    string
+		want    []string
 		wantErr bool
 	}{
 		{
@@ -232,19 +287,37 @@ func TestFromPlayer(t *testing.T) {
 			name:    "first pos",
 			encoded: "2|8H|8S|8D|8C",
 			pos:     2,
-			want:    "8H",
+			want:    []string{"8H"},
 		},
 		{
 			name:    "last pos",
 			encoded: "2|8H|8S|8D|8C",
 			pos:     1,
-			want:    "8C",
+			want:    []string{"8C"},
 		},
 		{
 			name:    "zero index",
 			encoded: "0|8H|8S|8D|8C",
 			pos:     2,
-			want:    "8D",
+			want:    []string{"8D"},
+		},
+		{
+			name:    "two cards each",
+			encoded: "1/2/1/1|8H|9H|8S|9S|8D|9D|8C|9C",
+			pos:     2,
+			want:    []string{"8S", "9S"},
+		},
+		{
+			name:    "partner exchange",
+			encoded: "3/2/2/2|8H|9H|8S|9S",
+			pos:     1,
+			want:    []string{"8S", "9S"},
+		},
+		{
+			name:    "partner exchange, player does not pass",
+			encoded: "3/2/2/2|8H|9H|8S|9S",
+			pos:     0,
+			wantErr: true,
 		},
 	}
 
@@ -252,7 +325,7 @@ func TestFromPlayer(t *testing.T) {
 		t.Run(tc.name, func(t *testing.T) {
 			r := buildPassingRound(t, tc.encoded)
 
-			card, err := r.FromPlayer(tc.pos)
+			cards, err := r.FromPlayer(tc.pos)
 
 			if tc.wantErr && err == nil {
 				t.Fatal("missing expected error")
@@ -264,13 +337,224 @@ func TestFromPlayer(t *testing.T) {
 				return
 			}
 
-			if got, want := card.Encoded(), tc.want; got != want {
-				t.Errorf("FromPlayer()=%s want=%s", got, want)
+			if diff := cmp.Diff(buildCards(t, tc.want), cards, compareCards); diff != "" {
+				t.Errorf("FromPlayer() mismatch (-want +got):\n%s", diff)
 			}
 		})
 	}
 }
 
+func TestReceivedBy(t *testing.T) {
+	testCases := []struct {
+		name    string
+		encoded string
+		pos     int
+		want    []string
+		wantErr bool
+	}{
+		{
+			name:    "not done",
+			encoded: "0|",
+			wantErr: true,
+		},
+		{
+			name:    "across",
+			encoded: "0|8H|8S|8D|8C",
+			pos:     2,
+			want:    []string{"8H"},
+		},
+		{
+			name:    "left",
+			encoded: "0/1/1/1|8H|8S|8D|8C",
+			pos:     1,
+			want:    []string{"8H"},
+		},
+		{
+			name:    "right",
+			encoded: "0/2/3/1|8H|9H|8S|9S|8D|9D|8C|9C",
+			pos:     3,
+			want:    []string{"8H", "9H"},
+		},
+		{
+			name:    "partner exchange",
+			encoded: "3/1/2/2|8H|8S",
+			pos:     3,
+			want:    []string{"8S"},
+		},
+		{
+			name:    "partner exchange, player receives nothing",
+			encoded: "3/1/2/2|8H|8S",
+			pos:     2,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := buildPassingRound(t, tc.encoded)
+
+			cards, err := r.ReceivedBy(tc.pos)
+
+			if tc.wantErr && err == nil {
+				t.Fatal("missing expected error")
+			}
+			if !tc.wantErr && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tc.wantErr {
+				return
+			}
+
+			if diff := cmp.Diff(buildCards(t, tc.want), cards, compareCards, cmpopts.EquateEmpty()); diff != "" {
+				t.Errorf("ReceivedBy() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestNumPassedBy(t *testing.T) {
+	r := buildPassingRound(t, "1/2/1/1|8H|9H|8S")
+
+	for pos, want := range []int{0, 2, 1, 0} {
+		if got := r.NumPassedBy(pos); got != want {
+			t.Errorf("NumPassedBy(%d)=%d want=%d", pos, got, want)
+		}
+	}
+	if got, want := r.PerPlayer(), 2; got != want {
+		t.Errorf("PerPlayer()=%d want=%d", got, want)
+	}
+	if diff := cmp.Diff([]int{1, 2, 3, 0}, r.Passers()); diff != "" {
+		t.Errorf("Passers() mismatch (-want +got):\n%s", diff)
+	}
+	if got, want := r.ToPos(3), 0; got != want {
+		t.Errorf("ToPos(3)=%d want=%d", got, want)
+	}
+}
+
+func TestNewPassingRoundForRules(t *testing.T) {
+	testCases := []struct {
+		name      string
+		players   int
+		count     int
+		direction PassDirection
+		handNum   int
+		want      string
+	}{
+		{
+			name: "default",
+			want: "1|",
+		},
+		{
+			name:      "left",
+			direction: PassLeft,
+			want:      "1/1/1/1|",
+		},
+		{
+			name:      "right, three cards",
+			count:     3,
+			direction: PassRight,
+			want:      "1/3/3/1|",
+		},
+		{
+			name:      "rotate, first hand",
+			direction: PassRotate,
+			want:      "1/1/1/1|",
+		},
+		{
+			name:      "rotate, second hand",
+			direction: PassRotate,
+			handNum:   1,
+			want:      "1/1/3/1|",
+		},
+		{
+			name:      "rotate, third hand",
+			direction: PassRotate,
+			handNum:   2,
+			want:      "1|",
+		},
+		{
+			name:      "rotate, fourth hand",
+			direction: PassRotate,
+			handNum:   3,
+			want:      "1/1/1/1|",
+		},
+		{
+			name:    "six players",
+			players: 6,
+			want:    "1/1/3/1/6|",
+		},
+		{
+			name:      "six players, right",
+			players:   6,
+			direction: PassRight,
+			want:      "1/1/5/1/6|",
+		},
+		{
+			name:    "three players passes left instead of across",
+			players: 3,
+			want:    "1/1/1/1/3|",
+		},
+		{
+			name:      "three players, rotate, second hand",
+			players:   3,
+			direction: PassRotate,
+			handNum:   1,
+			want:      "1/1/2/1/3|",
+		},
+		{
+			name:      "three players, rotate, third hand",
+			players:   3,
+			direction: PassRotate,
+			handNum:   2,
+			want:      "1/1/1/1/3|",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rules := NewRules()
+			rules.SetPlayers(tc.players)
+			rules.SetPassCount(tc.count)
+			rules.SetPassDirection(tc.direction)
+
+			r, err := newPassingRoundForRules(rules, 1, tc.handNum)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got, want := r.Encoded(), tc.want; got != want {
+				t.Errorf("Encoded()=%q want=%q", got, want)
+			}
+		})
+	}
+}
+
+func TestNewPartnerExchange(t *testing.T) {
+	r, err := newPartnerExchange(4, 2, 6)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([]int{4, 1}, r.Passers()); diff != "" {
+		t.Errorf("Passers() mismatch (-want +got):\n%s", diff)
+	}
+	if got, want := r.ToPos(4), 1; got != want {
+		t.Errorf("ToPos(4)=%d want=%d", got, want)
+	}
+	if got, want := r.Encoded(), "4/2/3/3/6|"; got != want {
+		t.Errorf("Encoded()=%q want=%q", got, want)
+	}
+	decoded, err := NewPassingRoundFromEncoded(r.Encoded())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(r.Passers(), decoded.Passers()); diff != "" {
+		t.Errorf("decoded Passers() mismatch (-want +got):\n%s", diff)
+	}
+
+	if _, err := newPartnerExchange(0, 1, 3); err == nil {
+		t.Errorf("missing expected error for cutthroat exchange")
+	}
+}
+
 func buildPassingRound(t *testing.T, encoded string) PassingRound {
 	r, err := NewPassingRoundFromEncoded(encoded)
 	if err != nil {
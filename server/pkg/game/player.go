@@ -17,6 +17,8 @@ type Player interface {
 	Name() string
 	// SetName updates the name of the player.
 	SetName(context.Context, string) (Player, error)
+	// Strategy is the name of the Strategy that plays for a bot player; empty for a person.
+	Strategy() string
 }
 
 const (
@@ -30,6 +32,7 @@ var (
 type player struct {
 	store    storage.PlayerStore
 	id, name string
+	strategy string
 }
 
 var _ Player = (*player)(nil) // Ensure interface is implemented.
@@ -53,6 +56,20 @@ func NewPlayer(ctx context.Context, store storage.PlayerStore, id, name string)
 	return playerFromStorage(store, id, ps)
 }
 
+// NewBotPlayer creates a new bot player, played by the registered Strategy, storing it in the PlayerStore.
+func NewBotPlayer(ctx context.Context, store storage.PlayerStore, id, name, strategy string) (Player, error) {
+	if _, err := NewStrategy(strategy); err != nil {
+		return nil, err
+	}
+	p, err := NewPlayer(ctx, store, id, name)
+	if err != nil {
+		return nil, err
+	}
+	bot := p.(*player)
+	bot.strategy = strategy
+	return bot.save(ctx)
+}
+
 // GetPlayer fetches the player from the PlayerStore, returning ErrNotFound if not found.
 func GetPlayer(ctx context.Context, store storage.PlayerStore, id string) (Player, error) {
 	ps, err := store.Get(ctx, id)
@@ -73,6 +90,10 @@ func (p *player) Name() string {
 	return p.name
 }
 
+func (p *player) Strategy() string {
+	return p.strategy
+}
+
 func (p *player) SetName(ctx context.Context, name string) (Player, error) {
 	p.name = name
 	return p.save(ctx)
@@ -80,7 +101,8 @@ func (p *player) SetName(ctx context.Context, name string) (Player, error) {
 
 func (p *player) save(ctx context.Context) (Player, error) {
 	ps := &storage.Player{
-		Name: p.name,
+		Name:     p.name,
+		Strategy: p.strategy,
 	}
 	if err := p.store.Set(ctx, p.id, ps); err != nil {
 		return nil, fmt.Errorf("saving player: %v", err)
@@ -93,8 +115,9 @@ func playerFromStorage(store storage.PlayerStore, id string, ps *storage.Player)
 		return nil, errors.New("nil player")
 	}
 	return &player{
-		store: store,
-		id:    id,
-		name:  ps.Name,
+		store:    store,
+		id:       id,
+		name:     ps.Name,
+		strategy: ps.Strategy,
 	}, nil
 }
@@ -0,0 +1,83 @@
+package game
+
+// ErrUnknownPreset is returned when a preset is not registered.
+var ErrUnknownPreset = newError(CodeUnknownPreset, "Unknown rule preset")
+
+// customPresetName is the name shown for rules that were not built from a preset.
+const customPresetName = "Custom"
+
+// Preset is a named set of house rules.
+type Preset struct {
+	// ID is the stable identifier stored with the game (e.g., "standard-52").
+	ID string
+	// Name is the human-readable name (e.g., "Standard 52").
+	Name string
+	// Description is a short summary of how the preset differs from the defaults.
+	Description string
+
+	// apply sets the preset's rules, starting from the defaults.
+	apply func(Rules)
+}
+
+var presets = []Preset{
+	{
+		ID:          "standard-52",
+		Name:        "Standard 52",
+		Description: "Game to 52, raised to 62 by No Trump. No passing; the dealer is stuck when everyone passes.",
+		apply:       func(r Rules) {},
+	},
+	{
+		ID:          "ontario-64",
+		Name:        "Ontario 64",
+		Description: "Game to 64 with no No Trump raise. The non-bidding team is capped at 45.",
+		apply: func(r Rules) {
+			r.SetToWin(64)
+			r.SetNoTrumpRaisesToWin(false)
+			r.SetDefenderCap(45)
+		},
+	},
+	{
+		ID:          "prairie-pass-card",
+		Name:        "Prairie pass-card",
+		Description: "Game to 52, raised to 62 by No Trump. Everyone passes one card to their partner before bidding; when everyone passes, the next dealer deals.",
+		apply: func(r Rules) {
+			r.SetPassCard(true)
+			r.SetPassCount(1)
+			r.SetPassDirection(PassAcross)
+			r.SetPassTiming(PassBeforeBidding)
+			r.SetAllPass(RedealNextDealer)
+		},
+	},
+}
+
+// Presets returns the registered rule presets, in display order.
+func Presets() []Preset {
+	return presets
+}
+
+// NewRulesFromPreset builds the rules for the preset with the given ID, returning ErrUnknownPreset if not registered.
+func NewRulesFromPreset(id string) (Rules, error) {
+	for _, p := range presets {
+		if p.ID != id {
+			continue
+		}
+		r := NewRules()
+		p.apply(r)
+		r.SetPreset(p.ID)
+		if err := r.Validate(); err != nil {
+			return nil, err
+		}
+		return r, nil
+	}
+	return nil, ErrUnknownPreset
+}
+
+// PresetName returns the human-readable name of the preset with the given ID, or "Custom" if not registered.
+func PresetName(id string) string {
+	for _, p := range presets {
+		if p.ID == id {
+			return p.Name
+		}
+	}
+	return customPresetName
+}
@@ -0,0 +1,67 @@
+package game
+
+import "testing"
+
+func TestNewRulesFromPreset(t *testing.T) {
+	for _, p := range Presets() {
+		t.Run(p.ID, func(t *testing.T) {
+			rules, err := NewRulesFromPreset(p.ID)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got, want := rules.Preset(), p.ID; got != want {
+				t.Errorf("Preset()=%q want=%q", got, want)
+			}
+			if got, want := PresetName(rules.Preset()), p.Name; got != want {
+				t.Errorf("PresetName()=%q want=%q", got, want)
+			}
+		})
+	}
+}
+
+func TestNewRulesFromPresetOntario(t *testing.T) {
+	rules, err := NewRulesFromPreset("ontario-64")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got, want := rules.ToWin(), 64; got != want {
+		t.Errorf("ToWin()=%d want=%d", got, want)
+	}
+	if got, want := rules.NoTrumpRaisesToWin(), false; got != want {
+		t.Errorf("NoTrumpRaisesToWin()=%t want=%t", got, want)
+	}
+	if got, want := rules.DefenderCap(), 45; got != want {
+		t.Errorf("DefenderCap()=%d want=%d", got, want)
+	}
+}
+
+func TestNewRulesFromPresetPrairie(t *testing.T) {
+	rules, err := NewRulesFromPreset("prairie-pass-card")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got, want := rules.ToWin(), 52; got != want {
+		t.Errorf("ToWin()=%d want=%d", got, want)
+	}
+	if got, want := rules.NoTrumpRaisesToWin(), true; got != want {
+		t.Errorf("NoTrumpRaisesToWin()=%t want=%t", got, want)
+	}
+	if got, want := rules.NoTrumpToWin(), 62; got != want {
+		t.Errorf("NoTrumpToWin()=%d want=%d", got, want)
+	}
+	if got, want := rules.PassCount(), 1; got != want {
+		t.Errorf("PassCount()=%d want=%d", got, want)
+	}
+	if got, want := rules.AllPass(), RedealNextDealer; got != want {
+		t.Errorf("AllPass()=%q want=%q", got, want)
+	}
+}
+
+func TestNewRulesFromPresetUnknown(t *testing.T) {
+	if _, err := NewRulesFromPreset("bogus"); err != ErrUnknownPreset {
+		t.Errorf("NewRulesFromPreset()=%v want=%v", err, ErrUnknownPreset)
+	}
+	if got, want := PresetName(""), "Custom"; got != want {
+		t.Errorf("PresetName()=%q want=%q", got, want)
+	}
+}
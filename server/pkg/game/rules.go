@@ -1,14 +1,165 @@
 package game
 
-import "github.com/squee1945/threespot/server/pkg/storage"
+import (
+	"time"
+
+	"github.com/squee1945/threespot/server/pkg/deck"
+	"github.com/squee1945/threespot/server/pkg/storage"
+)
+
+const (
+	// defaultToWin is the score needed to win the game.
+	defaultToWin = 52
+	// defaultNoTrumpToWin is the score needed to win once a team has made a no trump bid.
+	defaultNoTrumpToWin = 62
+	// defaultMinBid is the lowest bid that may be placed.
+	defaultMinBid = 7
+	// defaultPlayers is the number of players (two teams of two).
+	defaultPlayers = 4
+)
+
+// AllPassRule is what happens when every player passes during bidding.
+type AllPassRule string
+
+const (
+	// StickTheDealer forces the dealer to bid when the other three players have passed.
+	StickTheDealer AllPassRule = "stick"
+	// RedealSameDealer throws the hand in and the same dealer deals again.
+	RedealSameDealer AllPassRule = "same"
+	// RedealNextDealer throws the hand in and the deal moves to the next dealer.
+	RedealNextDealer AllPassRule = "next"
+)
+
+// PassDirection is who receives the cards passed before bidding.
+type PassDirection string
+
+const (
+	// PassAcross passes cards to partners.
+	PassAcross PassDirection = "across"
+	// PassLeft passes cards to the player on the left.
+	PassLeft PassDirection = "left"
+	// PassRight passes cards to the player on the right.
+	PassRight PassDirection = "right"
+	// PassRotate passes cards left, then right, then across, changing each hand.
+	PassRotate PassDirection = "rotate"
+)
+
+// PassTiming is when cards are passed.
+type PassTiming string
+
+const (
+	// PassBeforeBidding has every player pass cards before bidding.
+	PassBeforeBidding PassTiming = "before"
+	// PassAfterAuction has the bid winner and their partner exchange cards after bidding, before trump is called.
+	PassAfterAuction PassTiming = "after"
+)
+
+// MisdealRule is the kind of hand a player may claim a misdeal for.
+type MisdealRule string
+
+const (
+	// NoMisdeals does not allow misdeal claims.
+	NoMisdeals MisdealRule = "none"
+	// MisdealNoFaceCards allows a misdeal claim for a hand without a Jack, Queen or King.
+	MisdealNoFaceCards MisdealRule = "noface"
+	// MisdealNoAcesOrSpecials allows a misdeal claim for a hand without an Ace, the 5 of hearts or the 3 of spades.
+	MisdealNoAcesOrSpecials MisdealRule = "noaces"
+)
 
 type Rules interface {
+	// SetPreset records the ID of the preset the rules were built from; use "" for custom rules.
+	SetPreset(string)
+	// Preset is the ID of the preset the rules were built from; "" for custom rules.
+	Preset() string
+
+	// SetPlayers sets the number of players (3 to 6); an odd number of players plays cutthroat.
+	SetPlayers(int)
+	// Players is the number of players; the default is 4.
+	Players() int
+
 	SetPassCard(bool)
 	PassCard() bool
+
+	// SetPassCount sets the number of cards each player passes (1 to 3).
+	SetPassCount(int)
+	// PassCount is the number of cards each player passes.
+	PassCount() int
+
+	// SetPassDirection sets who receives the cards passed before bidding.
+	SetPassDirection(PassDirection)
+	// PassDirection is who receives the cards passed before bidding; the default is PassAcross.
+	PassDirection() PassDirection
+
+	// SetPassTiming sets when cards are passed.
+	SetPassTiming(PassTiming)
+	// PassTiming is when cards are passed; the default is PassBeforeBidding.
+	PassTiming() PassTiming
+
+	// SetToWin sets the score needed to win the game.
+	SetToWin(int)
+	// ToWin is the score needed to win the game (until a no trump bid raises it).
+	ToWin() int
+
+	// SetNoTrumpRaisesToWin sets whether a successful no trump bid raises the score needed to win.
+	SetNoTrumpRaisesToWin(bool)
+	// NoTrumpRaisesToWin returns true if a successful no trump bid raises the score needed to win.
+	NoTrumpRaisesToWin() bool
+
+	// SetNoTrumpToWin sets the score needed to win once a successful no trump bid has raised it.
+	SetNoTrumpToWin(int)
+	// NoTrumpToWin is the score needed to win once a successful no trump bid has raised it.
+	NoTrumpToWin() int
+
+	// SetDefenderCap sets the score the non-bidding team cannot climb past; use 0 for no cap.
+	SetDefenderCap(int)
+	// DefenderCap is the score the non-bidding team cannot climb past; 0 means there is no cap.
+	DefenderCap() int
+
+	// SetAllPass sets what happens when every player passes.
+	SetAllPass(AllPassRule)
+	// AllPass is what happens when every player passes; the default is StickTheDealer.
+	AllPass() AllPassRule
+
+	// SetMinBid sets the lowest bid that may be placed (e.g., 6, 7 or 8).
+	SetMinBid(int)
+	// MinBid is the lowest bid that may be placed.
+	MinBid() int
+
+	// SetDealerMustOverbid sets whether the dealer must bid higher than the high bid, rather than taking it at the same value.
+	SetDealerMustOverbid(bool)
+	// DealerMustOverbid returns true if the dealer must bid higher than the high bid.
+	DealerMustOverbid() bool
+
+	// SetMisdeal sets the kind of hand a player may claim a misdeal for.
+	SetMisdeal(MisdealRule)
+	// Misdeal is the kind of hand a player may claim a misdeal for; the default is NoMisdeals.
+	Misdeal() MisdealRule
+
+	// SetTurnLimit sets how long a player has for each turn before a default action is taken for them; use 0 for no limit.
+	SetTurnLimit(time.Duration)
+	// TurnLimit is how long a player has for each turn; 0 means there is no limit.
+	TurnLimit() time.Duration
+
+	// Validate returns an error describing the first invalid rule or incompatible combination of rules.
+	Validate() error
 }
 
 type rules struct {
-	passCard bool
+	preset        string
+	players       int
+	passCard      bool
+	passCount     int
+	passDirection PassDirection
+	passTiming    PassTiming
+	toWin         int
+	fixedToWin    bool
+	noTrumpToWin  int
+	defenderCap   int
+	allPass       AllPassRule
+	minBid        int
+	overbid       bool
+	misdeal       MisdealRule
+	turnLimit     time.Duration
 }
 
 var _ Rules = (*rules)(nil) // Ensure interface is implemented.
@@ -17,6 +168,25 @@ func NewRules() Rules {
 	return &rules{}
 }
 
+func (r *rules) SetPreset(preset string) {
+	r.preset = preset
+}
+
+func (r *rules) Preset() string {
+	return r.preset
+}
+
+func (r *rules) SetPlayers(players int) {
+	r.players = players
+}
+
+func (r *rules) Players() int {
+	if r.players == 0 {
+		return defaultPlayers
+	}
+	return r.players
+}
+
 func (r *rules) SetPassCard(passCard bool) {
 	r.passCard = passCard
 }
@@ -25,8 +195,238 @@ func (r *rules) PassCard() bool {
 	return r.passCard
 }
 
+func (r *rules) SetPassCount(passCount int) {
+	r.passCount = passCount
+}
+
+func (r *rules) PassCount() int {
+	if r.passCount == 0 {
+		return defaultPassPerPlayer
+	}
+	return r.passCount
+}
+
+func (r *rules) SetPassDirection(direction PassDirection) {
+	r.passDirection = direction
+}
+
+func (r *rules) PassDirection() PassDirection {
+	if r.passDirection == "" {
+		return PassAcross
+	}
+	return r.passDirection
+}
+
+func (r *rules) SetPassTiming(timing PassTiming) {
+	r.passTiming = timing
+}
+
+func (r *rules) PassTiming() PassTiming {
+	if r.passTiming == "" {
+		return PassBeforeBidding
+	}
+	return r.passTiming
+}
+
+func (r *rules) SetToWin(toWin int) {
+	r.toWin = toWin
+}
+
+func (r *rules) ToWin() int {
+	if r.toWin == 0 {
+		return defaultToWin
+	}
+	return r.toWin
+}
+
+func (r *rules) SetNoTrumpRaisesToWin(raises bool) {
+	r.fixedToWin = !raises
+}
+
+func (r *rules) NoTrumpRaisesToWin() bool {
+	return !r.fixedToWin
+}
+
+func (r *rules) SetNoTrumpToWin(toWin int) {
+	r.noTrumpToWin = toWin
+}
+
+func (r *rules) NoTrumpToWin() int {
+	if r.noTrumpToWin == 0 {
+		return defaultNoTrumpToWin
+	}
+	return r.noTrumpToWin
+}
+
+func (r *rules) SetDefenderCap(limit int) {
+	r.defenderCap = limit
+}
+
+func (r *rules) DefenderCap() int {
+	return r.defenderCap
+}
+
+func (r *rules) SetAllPass(allPass AllPassRule) {
+	r.allPass = allPass
+}
+
+func (r *rules) AllPass() AllPassRule {
+	if r.allPass == "" {
+		return StickTheDealer
+	}
+	return r.allPass
+}
+
+func (r *rules) SetMinBid(minBid int) {
+	r.minBid = minBid
+}
+
+func (r *rules) MinBid() int {
+	if r.minBid == 0 {
+		return defaultMinBid
+	}
+	return r.minBid
+}
+
+func (r *rules) SetDealerMustOverbid(overbid bool) {
+	r.overbid = overbid
+}
+
+func (r *rules) DealerMustOverbid() bool {
+	return r.overbid
+}
+
+func (r *rules) SetMisdeal(misdeal MisdealRule) {
+	r.misdeal = misdeal
+}
+
+func (r *rules) Misdeal() MisdealRule {
+	if r.misdeal == "" {
+		return NoMisdeals
+	}
+	return r.misdeal
+}
+
+func (r *rules) SetTurnLimit(turnLimit time.Duration) {
+	r.turnLimit = turnLimit
+}
+
+func (r *rules) TurnLimit() time.Duration {
+	return r.turnLimit
+}
+
+func (r *rules) Validate() error {
+	if r.Players() < deck.MinPlayers || r.Players() > deck.MaxPlayers {
+		return invalidRules("Number of players must be %d to %d", deck.MinPlayers, deck.MaxPlayers)
+	}
+	if r.PassCount() < 1 || r.PassCount() > maxPassPerPlayer {
+		return invalidRules("Number of cards to pass must be 1 to %d", maxPassPerPlayer)
+	}
+	switch r.PassDirection() {
+	case PassAcross, PassLeft, PassRight, PassRotate:
+	default:
+		return invalidRules("Invalid passing direction")
+	}
+	switch r.PassTiming() {
+	case PassBeforeBidding, PassAfterAuction:
+	default:
+		return invalidRules("Invalid passing time")
+	}
+	if r.PassCard() && !hasPartners(r.Players()) {
+		if r.PassTiming() == PassAfterAuction {
+			return invalidRules("Cards cannot be exchanged after the auction without partners")
+		}
+		if r.PassDirection() == PassAcross {
+			return invalidRules("Cards cannot be passed across without partners")
+		}
+	}
+	if r.ToWin() < 0 || r.NoTrumpToWin() < 0 {
+		return invalidRules("Invalid score to win")
+	}
+	if r.NoTrumpRaisesToWin() && r.NoTrumpToWin() <= r.ToWin() {
+		return invalidRules("No Trump must raise the score to win above the score to win")
+	}
+	if r.DefenderCap() < 0 || (r.DefenderCap() > 0 && r.DefenderCap() >= r.ToWin()) {
+		return invalidRules("The non-bidding team cap must be below the score to win")
+	}
+	switch r.AllPass() {
+	case StickTheDealer, RedealSameDealer, RedealNextDealer:
+	default:
+		return invalidRules("Invalid all pass rule")
+	}
+	if r.MinBid() < 6 || r.MinBid() > 12 {
+		return invalidRules("Minimum bid must be 6 to 12")
+	}
+	switch r.Misdeal() {
+	case NoMisdeals, MisdealNoFaceCards, MisdealNoAcesOrSpecials:
+	default:
+		return invalidRules("Invalid misdeal rule")
+	}
+	if r.TurnLimit() < 0 || r.TurnLimit()%time.Second != 0 {
+		return invalidRules("Turn limit must be a whole number of seconds")
+	}
+	return nil
+}
+
 func rulesFromStorage(sr storage.Rules) Rules {
 	return &rules{
-		passCard: sr.PassCard,
+		preset:        sr.Preset,
+		players:       sr.Players,
+		passCard:      sr.PassCard,
+		passCount:     sr.PassCount,
+		passDirection: PassDirection(sr.PassDirection),
+		passTiming:    PassTiming(sr.PassTiming),
+		toWin:         sr.ToWin,
+		fixedToWin:    sr.FixedToWin,
+		noTrumpToWin:  sr.NoTrumpToWin,
+		defenderCap:   sr.DefenderCap,
+		allPass:       AllPassRule(sr.AllPass),
+		minBid:        sr.MinBid,
+		overbid:       sr.DealerMustOverbid,
+		misdeal:       MisdealRule(sr.Misdeal),
+		turnLimit:     time.Duration(sr.TurnSeconds) * time.Second,
+	}
+}
+
+// storageFromRules converts the rules into the storage version.
+// Values matching the defaults are left empty so that older games (stored before the rule existed) load with the defaults.
+func storageFromRules(r Rules) storage.Rules {
+	sr := storage.Rules{}
+	if r == nil {
+		return sr
+	}
+	sr.Preset = r.Preset()
+	if r.Players() != defaultPlayers {
+		sr.Players = r.Players()
+	}
+	sr.PassCard = r.PassCard()
+	if r.PassCount() != defaultPassPerPlayer {
+		sr.PassCount = r.PassCount()
+	}
+	if r.PassDirection() != PassAcross {
+		sr.PassDirection = string(r.PassDirection())
+	}
+	if r.PassTiming() != PassBeforeBidding {
+		sr.PassTiming = string(r.PassTiming())
+	}
+	if r.ToWin() != defaultToWin {
+		sr.ToWin = r.ToWin()
+	}
+	sr.FixedToWin = !r.NoTrumpRaisesToWin()
+	if r.NoTrumpToWin() != defaultNoTrumpToWin {
+		sr.NoTrumpToWin = r.NoTrumpToWin()
+	}
+	sr.DefenderCap = r.DefenderCap()
+	if r.AllPass() != StickTheDealer {
+		sr.AllPass = string(r.AllPass())
+	}
+	if r.MinBid() != defaultMinBid {
+		sr.MinBid = r.MinBid()
+	}
+	sr.DealerMustOverbid = r.DealerMustOverbid()
+	if r.Misdeal() != NoMisdeals {
+		sr.Misdeal = string(r.Misdeal())
 	}
+	sr.TurnSeconds = int(r.TurnLimit() / time.Second)
+	return sr
 }
@@ -2,16 +2,54 @@ package game
 
 import (
 	"testing"
+	"time"
 
+	"github.com/google/go-cmp/cmp"
 	"github.com/squee1945/threespot/server/pkg/storage"
 )
 
 func TestNewRules(t *testing.T) {
 	rules := NewRules()
 
+	if got, want := rules.Players(), 4; got != want {
+		t.Errorf("Players()=%d want=%d", got, want)
+	}
 	if got, want := rules.PassCard(), false; got != want {
 		t.Errorf("PassCard()=%t want=%t", got, want)
 	}
+	if got, want := rules.ToWin(), 52; got != want {
+		t.Errorf("ToWin()=%d want=%d", got, want)
+	}
+	if got, want := rules.NoTrumpRaisesToWin(), true; got != want {
+		t.Errorf("NoTrumpRaisesToWin()=%t want=%t", got, want)
+	}
+	if got, want := rules.NoTrumpToWin(), 62; got != want {
+		t.Errorf("NoTrumpToWin()=%d want=%d", got, want)
+	}
+	if got, want := rules.DefenderCap(), 0; got != want {
+		t.Errorf("DefenderCap()=%d want=%d", got, want)
+	}
+	if got, want := rules.AllPass(), StickTheDealer; got != want {
+		t.Errorf("AllPass()=%q want=%q", got, want)
+	}
+	if got, want := rules.MinBid(), 7; got != want {
+		t.Errorf("MinBid()=%d want=%d", got, want)
+	}
+	if got, want := rules.PassCount(), 1; got != want {
+		t.Errorf("PassCount()=%d want=%d", got, want)
+	}
+	if got, want := rules.PassDirection(), PassAcross; got != want {
+		t.Errorf("PassDirection()=%q want=%q", got, want)
+	}
+	if got, want := rules.PassTiming(), PassBeforeBidding; got != want {
+		t.Errorf("PassTiming()=%q want=%q", got, want)
+	}
+	if got, want := rules.Misdeal(), NoMisdeals; got != want {
+		t.Errorf("Misdeal()=%q want=%q", got, want)
+	}
+	if got, want := rules.DealerMustOverbid(), false; got != want {
+		t.Errorf("DealerMustOverbid()=%t want=%t", got, want)
+	}
 }
 
 func TestSetPassCard(t *testing.T) {
@@ -26,14 +64,280 @@ func TestSetPassCard(t *testing.T) {
 	}
 }
 
+func TestSetPassOptions(t *testing.T) {
+	rules := NewRules()
+	rules.SetPassCount(3)
+	rules.SetPassDirection(PassRotate)
+	rules.SetPassTiming(PassAfterAuction)
+	if got, want := rules.PassCount(), 3; got != want {
+		t.Errorf("PassCount()=%d want=%d", got, want)
+	}
+	if got, want := rules.PassDirection(), PassRotate; got != want {
+		t.Errorf("PassDirection()=%q want=%q", got, want)
+	}
+	if got, want := rules.PassTiming(), PassAfterAuction; got != want {
+		t.Errorf("PassTiming()=%q want=%q", got, want)
+	}
+}
+
+func TestSetToWin(t *testing.T) {
+	rules := NewRules()
+	rules.SetToWin(64)
+	rules.SetNoTrumpRaisesToWin(false)
+	rules.SetNoTrumpToWin(72)
+	if got, want := rules.ToWin(), 64; got != want {
+		t.Errorf("ToWin()=%d want=%d", got, want)
+	}
+	if got, want := rules.NoTrumpRaisesToWin(), false; got != want {
+		t.Errorf("NoTrumpRaisesToWin()=%t want=%t", got, want)
+	}
+	if got, want := rules.NoTrumpToWin(), 72; got != want {
+		t.Errorf("NoTrumpToWin()=%d want=%d", got, want)
+	}
+}
+
+func TestSetDefenderCap(t *testing.T) {
+	rules := NewRules()
+	rules.SetDefenderCap(45)
+	if got, want := rules.DefenderCap(), 45; got != want {
+		t.Errorf("DefenderCap()=%d want=%d", got, want)
+	}
+	rules.SetDefenderCap(0)
+	if got, want := rules.DefenderCap(), 0; got != want {
+		t.Errorf("DefenderCap()=%d want=%d", got, want)
+	}
+}
+
+func TestSetAllPass(t *testing.T) {
+	rules := NewRules()
+	rules.SetAllPass(RedealSameDealer)
+	if got, want := rules.AllPass(), RedealSameDealer; got != want {
+		t.Errorf("AllPass()=%q want=%q", got, want)
+	}
+	rules.SetAllPass(StickTheDealer)
+	if got, want := rules.AllPass(), StickTheDealer; got != want {
+		t.Errorf("AllPass()=%q want=%q", got, want)
+	}
+}
+
+func TestSetMinBid(t *testing.T) {
+	rules := NewRules()
+	rules.SetMinBid(6)
+	rules.SetDealerMustOverbid(true)
+	if got, want := rules.MinBid(), 6; got != want {
+		t.Errorf("MinBid()=%d want=%d", got, want)
+	}
+	if got, want := rules.DealerMustOverbid(), true; got != want {
+		t.Errorf("DealerMustOverbid()=%t want=%t", got, want)
+	}
+}
+
 func TestRulesFromStorage(t *testing.T) {
 	sr := storage.Rules{
-		PassCard: true,
+		Players:       6,
+		PassCard:      true,
+		PassCount:     2,
+		PassDirection: "left",
+		PassTiming:    "after",
+		ToWin:         62,
+		FixedToWin:    true,
+		NoTrumpToWin:  72,
+		DefenderCap:   45,
+		AllPass:       "next",
+		MinBid:        8,
+		Misdeal:       "noface",
 	}
 
 	rules := rulesFromStorage(sr)
 
+	if got, want := rules.Players(), 6; got != want {
+		t.Errorf("Players()=%d want=%d", got, want)
+	}
 	if got, want := rules.PassCard(), true; got != want {
 		t.Errorf("PassCard()=%t want=%t", got, want)
 	}
+	if got, want := rules.PassCount(), 2; got != want {
+		t.Errorf("PassCount()=%d want=%d", got, want)
+	}
+	if got, want := rules.PassDirection(), PassLeft; got != want {
+		t.Errorf("PassDirection()=%q want=%q", got, want)
+	}
+	if got, want := rules.PassTiming(), PassAfterAuction; got != want {
+		t.Errorf("PassTiming()=%q want=%q", got, want)
+	}
+	if got, want := rules.ToWin(), 62; got != want {
+		t.Errorf("ToWin()=%d want=%d", got, want)
+	}
+	if got, want := rules.NoTrumpRaisesToWin(), false; got != want {
+		t.Errorf("NoTrumpRaisesToWin()=%t want=%t", got, want)
+	}
+	if got, want := rules.NoTrumpToWin(), 72; got != want {
+		t.Errorf("NoTrumpToWin()=%d want=%d", got, want)
+	}
+	if got, want := rules.DefenderCap(), 45; got != want {
+		t.Errorf("DefenderCap()=%d want=%d", got, want)
+	}
+	if got, want := rules.AllPass(), RedealNextDealer; got != want {
+		t.Errorf("AllPass()=%q want=%q", got, want)
+	}
+	if got, want := rules.MinBid(), 8; got != want {
+		t.Errorf("MinBid()=%d want=%d", got, want)
+	}
+	if got, want := rules.Misdeal(), MisdealNoFaceCards; got != want {
+		t.Errorf("Misdeal()=%q want=%q", got, want)
+	}
+}
+
+func TestStorageFromRules(t *testing.T) {
+	// Defaults are stored as zero values.
+	if diff := cmp.Diff(storage.Rules{}, storageFromRules(NewRules())); diff != "" {
+		t.Errorf("storageFromRules() mismatch (-want +got):\n%s", diff)
+	}
+
+	want := storage.Rules{
+		Preset:            "ontario-64",
+		Players:           3,
+		PassCard:          true,
+		ToWin:             64,
+		FixedToWin:        true,
+		NoTrumpToWin:      72,
+		DefenderCap:       45,
+		AllPass:           "same",
+		MinBid:            6,
+		DealerMustOverbid: true,
+		Misdeal:           "noaces",
+		TurnSeconds:       90,
+	}
+	if diff := cmp.Diff(want, storageFromRules(rulesFromStorage(want))); diff != "" {
+		t.Errorf("storageFromRules() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	testCases := []struct {
+		name    string
+		set     func(Rules)
+		wantErr bool
+	}{
+		{
+			name: "defaults",
+			set:  func(r Rules) {},
+		},
+		{
+			name: "cutthroat passing left",
+			set: func(r Rules) {
+				r.SetPlayers(3)
+				r.SetPassCard(true)
+				r.SetPassDirection(PassLeft)
+			},
+		},
+		{
+			name:    "too few players",
+			set:     func(r Rules) { r.SetPlayers(2) },
+			wantErr: true,
+		},
+		{
+			name:    "too many players",
+			set:     func(r Rules) { r.SetPlayers(7) },
+			wantErr: true,
+		},
+		{
+			name:    "too many cards passed",
+			set:     func(r Rules) { r.SetPassCount(4) },
+			wantErr: true,
+		},
+		{
+			name:    "bad pass direction",
+			set:     func(r Rules) { r.SetPassDirection("up") },
+			wantErr: true,
+		},
+		{
+			name:    "bad pass timing",
+			set:     func(r Rules) { r.SetPassTiming("during") },
+			wantErr: true,
+		},
+		{
+			name: "cutthroat passing across",
+			set: func(r Rules) {
+				r.SetPlayers(5)
+				r.SetPassCard(true)
+			},
+			wantErr: true,
+		},
+		{
+			name: "cutthroat exchange after auction",
+			set: func(r Rules) {
+				r.SetPlayers(3)
+				r.SetPassCard(true)
+				r.SetPassDirection(PassLeft)
+				r.SetPassTiming(PassAfterAuction)
+			},
+			wantErr: true,
+		},
+		{
+			name:    "negative to win",
+			set:     func(r Rules) { r.SetToWin(-1) },
+			wantErr: true,
+		},
+		{
+			name:    "no trump does not raise",
+			set:     func(r Rules) { r.SetToWin(64) },
+			wantErr: true,
+		},
+		{
+			name: "fixed to win",
+			set: func(r Rules) {
+				r.SetToWin(64)
+				r.SetNoTrumpRaisesToWin(false)
+			},
+		},
+		{
+			name:    "defender cap at to win",
+			set:     func(r Rules) { r.SetDefenderCap(52) },
+			wantErr: true,
+		},
+		{
+			name:    "bad all pass",
+			set:     func(r Rules) { r.SetAllPass("shuffle") },
+			wantErr: true,
+		},
+		{
+			name:    "min bid too low",
+			set:     func(r Rules) { r.SetMinBid(5) },
+			wantErr: true,
+		},
+		{
+			name:    "bad misdeal",
+			set:     func(r Rules) { r.SetMisdeal("nohearts") },
+			wantErr: true,
+		},
+		{
+			name: "turn limit",
+			set:  func(r Rules) { r.SetTurnLimit(90 * time.Second) },
+		},
+		{
+			name:    "negative turn limit",
+			set:     func(r Rules) { r.SetTurnLimit(-time.Second) },
+			wantErr: true,
+		},
+		{
+			name:    "fractional turn limit",
+			set:     func(r Rules) { r.SetTurnLimit(1500 * time.Millisecond) },
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rules := NewRules()
+			tc.set(rules)
+			err := rules.Validate()
+			if tc.wantErr && err == nil {
+				t.Error("missing expected error")
+			}
+			if !tc.wantErr && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
 }
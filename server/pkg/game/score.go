@@ -5,48 +5,71 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+
+	"github.com/squee1945/threespot/server/pkg/deck"
 )
 
 const (
 	// NoWinner means there is currently no winner.
 	NoWinner int = -1
+
+	// kaiserPoints are the points scored by a team that makes a Kaiser bid.
+	kaiserPoints = 52
+	// kaiserPenalty are the points lost by a team that misses a Kaiser bid.
+	kaiserPenalty = 26
 )
 
 // Score keeps track of the game store.
 type Score interface {
-	// ToWin is the score to win; either 52 or 62.
+	// ToWin is the score to win (e.g., 52, or 62 after a successful no trump bid).
 	ToWin() int
 
-	// setTopScore62 sets the top score to be 62.
-	setTopScore62()
+	// setToWin sets the score to win.
+	setToWin(int)
 
 	// Encoded is the encoded form of the score.
 	Encoded() string
 
-	// Scores returns a running score as a list of pairs. Each pair is the score of a hand, oldest first.
-	// Each pair is (points02, points13). The current score is the last item in the list.
+	// clone returns a copy of the score that can be changed independently.
+	clone() Score
+
+	// stored returns the structured form of the score, for storage.
+	stored() storedScore
+
+	// Scores returns a running score as a list of entries. Each entry is the score of a hand, oldest first.
+	// Each entry has the score of each team (e.g., (points02, points13)). The current score is the last item in the list.
 	Scores() [][]int
 
 	// Notes is an array of notes to show next to the scores.
 	Notes() []ScoreNote
 
-	// CurrentScore return a pair (points02, points13) representing the current score.
+	// CurrentScore returns the current score of each team (e.g., (points02, points13)).
 	CurrentScore() []int
 
-	// Winner returns 0 if team02 has won, 1 if team13 has one, and NoWinner otherwise.
+	// Winner returns the winning team (e.g., 0 if team02 has won, 1 if team13 has won), and NoWinner otherwise.
 	Winner() int
 
-	// setWinner sets the winning team; use 0 for team02, 1 for team13.
+	// setWinner sets the winning team (e.g., 0 for team02, 1 for team13).
 	setWinner(int)
 
 	// addTally adds a tally to the score, returning true if the game has been won.
 	// An error is returned if the tally is not done.
-	addTally(BiddingRound, Tally) (bool, error)
+	addTally(Rules, BiddingRound, Tally) (bool, error)
+
+	// addRedeal records a thrown-in hand; the score is unchanged and the note is attached to the team.
+	addRedeal(team int, note string) error
+
+	// Hands returns the breakdown of each hand, oldest first. Games started before hands were recorded
+	// have fewer hands than scores; use HandRecord.ScoreIndex to match a hand to its score.
+	Hands() []HandRecord
+
+	// addHand records the breakdown of the most recently scored hand.
+	addHand(HandRecord) error
 }
 
 // ScoreNote is a note to be attached to the score card.
 type ScoreNote struct {
-	// Team is the team, 0 for team02, 1 for team13.
+	// Team is the team (e.g., 0 for team02, 1 for team13).
 	Team int
 	// Index is the score index to attach the note to.
 	Index int
@@ -55,10 +78,12 @@ type ScoreNote struct {
 }
 
 type score struct {
+	teams  int
 	scores [][]int
 	toWin  int
 	winner int
 	notes  []ScoreNote
+	hands  []HandRecord
 }
 
 var _ Score = (*score)(nil) // Ensure interface is implemented.
@@ -66,10 +91,18 @@ var _ Score = (*score)(nil) // Ensure interface is implemented.
 // NewScoreFromEncoded builds a score sheet form the Encoded() representation.
 func NewScoreFromEncoded(encoded string) (Score, error) {
 	// "toWin-p02|p13||p02|p03||"
+	// With more than two teams, each hand has a score for each team: "toWin-p0|p1|p2||p0|p1|p2"
 	// If there is a winning team, it will be encoded like this: "toWin||winningTeam-p02|p13||p02|p03"
 	// If there are score notes, they will be in a new top-level section, demarcated with an "**" like this:
 	//      "toWin||winningTeam-p02|p13||p02|p13**[note1]||[note2]" where each note is "team|index|note"
 	// That is, the notes should be split off the rest of the encoded string first.
+	// If there are hand records, they follow the notes in a final section, demarcated with a "##" like this:
+	//      "toWin-p02|p13**[note1]##[hand1]~~[hand2]" where each hand is in the encodeHandRecord() form.
+	encodedHands := ""
+	if i := strings.Index(encoded, "##"); i >= 0 {
+		encoded, encodedHands = encoded[:i], encoded[i+2:]
+	}
+
 	// Separate the scores from the notes
 	cardAndNotes := strings.SplitN(encoded, "**", 2)
 	encodedNotes := ""
@@ -114,22 +147,22 @@ func NewScoreFromEncoded(encoded string) (Score, error) {
 		return score, nil
 	}
 
-	// Process the rest of encoded as a list of pairs.
-	pairs := strings.Split(topParts[1], "||")
-	for _, pair := range pairs {
-		parts := strings.Split(pair, "|")
-		if len(parts) != 2 {
-			return nil, fmt.Errorf("each element of %q must be a pair", topParts[1])
-		}
-		points02, err := strconv.Atoi(parts[0])
-		if err != nil {
-			return nil, err
+	// Process the rest of encoded as a list of team scores.
+	entries := strings.Split(topParts[1], "||")
+	score.teams = len(strings.Split(entries[0], "|"))
+	for _, entry := range entries {
+		parts := strings.Split(entry, "|")
+		if len(parts) < 2 || len(parts) != score.teams {
+			return nil, fmt.Errorf("each element of %q must have the same number of teams (at least two)", topParts[1])
 		}
-		points13, err := strconv.Atoi(parts[1])
-		if err != nil {
-			return nil, err
+		r := make([]int, score.teams)
+		for team, part := range parts {
+			points, err := strconv.Atoi(part)
+			if err != nil {
+				return nil, err
+			}
+			r[team] = points
 		}
-		r := []int{points02, points13}
 		score.scores = append(score.scores, r)
 	}
 
@@ -146,8 +179,8 @@ func NewScoreFromEncoded(encoded string) (Score, error) {
 			if team, err := strconv.Atoi(parts[0]); err != nil {
 				return nil, fmt.Errorf("parsing team from encoded note %q: %v", encodedNote, err)
 			} else {
-				if team != 0 && team != 1 {
-					return nil, fmt.Errorf("team %q must be 1 or 0", encodedNote)
+				if team < 0 || team >= score.teams {
+					return nil, fmt.Errorf("team %q must be on the interval [0,%d]", encodedNote, score.teams-1)
 				}
 				note.Team = team
 			}
@@ -167,18 +200,91 @@ func NewScoreFromEncoded(encoded string) (Score, error) {
 		score.notes = notes
 	}
 
+	// Process the hand records
+	if encodedHands != "" {
+		for _, encodedHand := range strings.Split(encodedHands, "~~") {
+			hand, err := decodeHandRecord(encodedHand)
+			if err != nil {
+				return nil, err
+			}
+			if hand.ScoreIndex < 0 || hand.ScoreIndex >= len(score.scores) {
+				return nil, fmt.Errorf("hand record index %d is out of range for number of scores %d", hand.ScoreIndex, len(score.scores))
+			}
+			score.hands = append(score.hands, hand)
+		}
+	}
+
 	return score, nil
 }
 
-// NewScore creates an empty score sheet.
+// storedScore is the structured form of a score sheet, kept in storage. Notes may contain any text.
+type storedScore struct {
+	Teams  int
+	ToWin  int
+	Winner int
+	Scores [][]int // The score of each team after each hand, oldest first.
+	Notes  []ScoreNote
+	Hands  []storedHandRecord
+}
+
+// newScoreFromStored builds a score sheet from the stored() form.
+func newScoreFromStored(st storedScore) (Score, error) {
+	if st.Teams < 2 {
+		return nil, fmt.Errorf("score must have at least two teams, got %d", st.Teams)
+	}
+	if st.Winner != NoWinner && (st.Winner < 0 || st.Winner >= st.Teams) {
+		return nil, fmt.Errorf("winning team %d must be on the interval [0,%d]", st.Winner, st.Teams-1)
+	}
+	s := &score{teams: st.Teams, toWin: st.ToWin, winner: st.Winner}
+	for _, r := range st.Scores {
+		if len(r) != st.Teams {
+			return nil, fmt.Errorf("each score %v must have %d teams", st.Scores, st.Teams)
+		}
+		s.scores = append(s.scores, append([]int(nil), r...))
+	}
+	for _, note := range st.Notes {
+		if note.Team < 0 || note.Team >= st.Teams {
+			return nil, fmt.Errorf("note team %d must be on the interval [0,%d]", note.Team, st.Teams-1)
+		}
+		if note.Index < 0 || note.Index >= len(s.scores) {
+			return nil, fmt.Errorf("note index %d is out of range for number of scores %d", note.Index, len(s.scores))
+		}
+		s.notes = append(s.notes, note)
+	}
+	for _, sh := range st.Hands {
+		hand, err := handRecordFromStored(sh)
+		if err != nil {
+			return nil, err
+		}
+		if hand.ScoreIndex < 0 || hand.ScoreIndex >= len(s.scores) {
+			return nil, fmt.Errorf("hand record index %d is out of range for number of scores %d", hand.ScoreIndex, len(s.scores))
+		}
+		s.hands = append(s.hands, hand)
+	}
+	return s, nil
+}
+
+// NewScore creates an empty score sheet for two teams.
 func NewScore() Score {
-	return &score{toWin: 52, winner: NoWinner}
+	return &score{teams: 2, toWin: defaultToWin, winner: NoWinner}
+}
+
+// newScoreForRules creates an empty score sheet using the score to win and the number of teams from the rules.
+func newScoreForRules(rules Rules) Score {
+	if rules == nil {
+		return NewScore()
+	}
+	return &score{teams: NumTeams(rules.Players()), toWin: rules.ToWin(), winner: NoWinner}
 }
 
 func (s *score) Encoded() string {
 	var pairs []string
 	for _, r := range s.scores {
-		pairs = append(pairs, fmt.Sprintf("%d|%d", r[0], r[1]))
+		var parts []string
+		for _, points := range r {
+			parts = append(parts, strconv.Itoa(points))
+		}
+		pairs = append(pairs, strings.Join(parts, "|"))
 	}
 	winningPart := strconv.Itoa(s.toWin)
 	if s.winner != NoWinner {
@@ -193,23 +299,52 @@ func (s *score) Encoded() string {
 		}
 		res += "**" + strings.Join(parts, "||")
 	}
+	if len(s.hands) > 0 {
+		var parts []string
+		for _, hand := range s.hands {
+			parts = append(parts, encodeHandRecord(hand))
+		}
+		res += "##" + strings.Join(parts, "~~")
+	}
 	return res
 }
 
+func (s *score) stored() storedScore {
+	st := storedScore{Teams: s.teams, ToWin: s.toWin, Winner: s.winner}
+	for _, r := range s.scores {
+		st.Scores = append(st.Scores, append([]int(nil), r...))
+	}
+	st.Notes = append(st.Notes, s.notes...)
+	for _, hand := range s.hands {
+		st.Hands = append(st.Hands, storedFromHandRecord(hand))
+	}
+	return st
+}
+
+func (s *score) clone() Score {
+	c := *s
+	c.scores = make([][]int, len(s.scores))
+	for i, scores := range s.scores {
+		c.scores[i] = append([]int(nil), scores...)
+	}
+	c.notes = append([]ScoreNote(nil), s.notes...)
+	c.hands = append([]HandRecord(nil), s.hands...)
+	return &c
+}
+
 func (s *score) ToWin() int {
 	return s.toWin
 }
 
-func (s *score) setTopScore62() {
-	s.toWin = 62
+func (s *score) setToWin(toWin int) {
+	s.toWin = toWin
 }
 
-// Returns 0 if team02 won, 1 if team13 won, and NoWinner if no one has won.
+// Returns the winning team, or NoWinner if no one has won.
 func (s *score) Winner() int {
 	return s.winner
 }
 
-// team02 is 0, team13 is 1.
 func (s *score) setWinner(winner int) {
 	s.winner = winner
 }
@@ -222,14 +357,27 @@ func (s *score) Notes() []ScoreNote {
 	return s.notes
 }
 
+func (s *score) Hands() []HandRecord {
+	return s.hands
+}
+
+func (s *score) addHand(hand HandRecord) error {
+	if len(s.scores) == 0 {
+		return errors.New("hand must be scored before it is recorded")
+	}
+	hand.ScoreIndex = len(s.scores) - 1
+	s.hands = append(s.hands, hand)
+	return nil
+}
+
 func (s *score) attachNote(team, index int, note string) error {
-	if team != 0 && team != 1 {
-		return errors.New("team must be 0 or 1")
+	if team < 0 || team >= s.teams {
+		return fmt.Errorf("team must be on the interval [0,%d]", s.teams-1)
 	}
 	if index >= len(s.scores) {
 		return fmt.Errorf("index %d is too large for number of scores %d", index, len(s.scores))
 	}
-	if strings.Contains(note, "-") || strings.Contains(note, "|") || strings.Contains(note, "*") {
+	if strings.ContainsAny(note, "-|*#~") {
 		return fmt.Errorf("note %q cannot contain special characters", note)
 	}
 	for _, n := range s.notes {
@@ -243,27 +391,31 @@ func (s *score) attachNote(team, index int, note string) error {
 
 func (s *score) CurrentScore() []int {
 	if len(s.scores) == 0 {
-		return []int{0, 0}
+		return make([]int, s.teams)
 	}
 	return s.scores[len(s.scores)-1]
 }
 
-func (s *score) addTally(br BiddingRound, tally Tally) (bool, error) {
+func (s *score) addTally(rules Rules, br BiddingRound, tally Tally) (bool, error) {
 	if !tally.IsDone() {
 		return false, errors.New("tally is not done")
 	}
+	if len(tally.Points()) != s.teams {
+		return false, fmt.Errorf("tally has %d teams, score has %d", len(tally.Points()), s.teams)
+	}
 
 	bid, pos, err := br.WinningBidAndPos()
 	if err != nil {
 		return false, err
 	}
+	team := TeamOf(pos, rules.Players())
 
-	last02, last13 := 0, 0
-	if len(s.scores) > 0 {
-		last := s.scores[len(s.scores)-1]
-		last02, last13 = last[0], last[1]
+	if bid.IsKaiser() {
+		return s.addKaiserTally(rules, bid, team, tally)
 	}
 
+	last := s.lastScores()
+
 	bidValue, err := bid.Value()
 	if err != nil {
 		return false, err
@@ -279,56 +431,134 @@ func (s *score) addTally(br BiddingRound, tally Tally) (bool, error) {
 		noteTrump += "N"
 	}
 
-	note02, note13 := "", ""
-	points02, points13 := tally.Points()
-	madeBid02, madeBid13 := false, false
-	sc := make([]int, 2)
-	if pos == 0 || pos == 2 {
-		if points02 >= bidValue {
-			// Team02 made the bid.
-			sc[0] = last02 + (points02 * multiplier)
-			madeBid02 = true
-			note02 = fmt.Sprintf("made %s bid", noteTrump)
-		} else {
-			// Team02 missed the bid.
-			sc[0] = last02 - (bidValue * multiplier)
-			note02 = fmt.Sprintf("missed %s bid", noteTrump)
-		}
-		sc[1] = last13 + points13
+	notes := make([]string, s.teams)
+	points := tally.Points()
+	madeBid, err := bidMade(bid, team, points, tally.Tricks())
+	if err != nil {
+		return false, err
+	}
+	sc := make([]int, s.teams)
+	if madeBid {
+		sc[team] = last[team] + (points[team] * multiplier)
+		notes[team] = fmt.Sprintf("made %s bid", noteTrump)
 	} else {
-		if points13 >= bidValue {
-			// Team13 made the bid.
-			sc[1] = last13 + (points13 * multiplier)
-			madeBid13 = true
-			note13 = fmt.Sprintf("made %s bid", noteTrump)
-		} else {
-			// Team13 missed the bid.
-			sc[1] = last13 - (bidValue * multiplier)
-			note13 = fmt.Sprintf("missed %s bid", noteTrump)
+		// The bidding team missed the bid.
+		sc[team] = last[team] - (bidValue * multiplier)
+		notes[team] = fmt.Sprintf("missed %s bid", noteTrump)
+	}
+	for other := range sc {
+		if other == team {
+			continue
 		}
-		sc[0] = last02 + points02
+		sc[other], notes[other] = defenderScore(rules, last[other], points[other])
 	}
 	s.scores = append(s.scores, sc)
 
-	if multiplier == 2 && (madeBid02 || madeBid13) {
-		s.setTopScore62()
+	if multiplier == 2 && madeBid && rules.NoTrumpRaisesToWin() && s.ToWin() < rules.NoTrumpToWin() {
+		s.setToWin(rules.NoTrumpToWin())
 	}
 
 	// Check to see if there is a winner.
-	if madeBid02 && (last02+(bidValue*multiplier) >= s.ToWin()) {
-		s.setWinner(0)
-		note02 = "bid out " + noteTrump
-	} else if madeBid13 && (last13+(bidValue*multiplier) >= s.ToWin()) {
-		s.setWinner(1)
-		note13 = "bid out " + noteTrump
+	if madeBid && (last[team]+(bidValue*multiplier) >= s.ToWin()) {
+		s.setWinner(team)
+		notes[team] = "bid out " + noteTrump
 	}
 
-	if note02 != "" {
-		s.attachNote(0, len(s.scores)-1, note02)
+	for t, note := range notes {
+		if note != "" {
+			s.attachNote(t, len(s.scores)-1, note)
+		}
 	}
-	if note13 != "" {
-		s.attachNote(1, len(s.scores)-1, note13)
+
+	return s.Winner() != NoWinner, nil
+}
+
+// addKaiserTally scores a hand that was played on a Kaiser bid by the team.
+// The bidding team must take every trick; if they do, they score kaiserPoints (a bid out if
+// this reaches ToWin), otherwise they lose kaiserPenalty. The other teams score their points as usual.
+func (s *score) addKaiserTally(rules Rules, bid Bid, team int, tally Tally) (bool, error) {
+	last := s.lastScores()
+	points := tally.Points()
+	tricks := tally.Tricks()
+
+	sc := make([]int, s.teams)
+	otherNotes := make([]string, s.teams)
+	for other := range sc {
+		if other == team {
+			continue
+		}
+		sc[other], otherNotes[other] = defenderScore(rules, last[other], points[other])
 	}
 
+	madeBid, err := bidMade(bid, team, points, tricks)
+	if err != nil {
+		return false, err
+	}
+	var note string
+	if madeBid {
+		sc[team] = last[team] + kaiserPoints
+		note = "made Kaiser bid"
+		if sc[team] >= s.ToWin() {
+			s.setWinner(team)
+			note = "bid out Kaiser"
+		}
+	} else {
+		sc[team] = last[team] - kaiserPenalty
+		note = "missed Kaiser bid"
+	}
+	s.scores = append(s.scores, sc)
+
+	if err := s.attachNote(team, len(s.scores)-1, note); err != nil {
+		return false, err
+	}
+	for other, otherNote := range otherNotes {
+		if otherNote == "" {
+			continue
+		}
+		if err := s.attachNote(other, len(s.scores)-1, otherNote); err != nil {
+			return false, err
+		}
+	}
 	return s.Winner() != NoWinner, nil
 }
+
+// bidMade returns true if the team that won the auction with the bid took the points it bid, or every trick for a
+// Kaiser bid; points and tricks are those taken by each team.
+func bidMade(bid Bid, team int, points, tricks []int) (bool, error) {
+	if bid.IsKaiser() {
+		return tricks[team] == deck.CardsPerHand, nil
+	}
+	value, err := bid.Value()
+	if err != nil {
+		return false, err
+	}
+	return points[team] >= value, nil
+}
+
+func (s *score) addRedeal(team int, note string) error {
+	s.scores = append(s.scores, s.lastScores())
+	return s.attachNote(team, len(s.scores)-1, note)
+}
+
+// lastScores returns a copy of the current score, so that it can be built upon.
+func (s *score) lastScores() []int {
+	last := make([]int, s.teams)
+	copy(last, s.CurrentScore())
+	return last
+}
+
+// defenderScore returns the new score for the non-bidding team, given their last score and the points they took.
+// If the rules cap the non-bidding team, points above the cap are discarded and a note explaining this is returned.
+func defenderScore(rules Rules, last, points int) (int, string) {
+	total := last + points
+	limit := rules.DefenderCap()
+	if limit == 0 || points <= 0 || total <= limit {
+		return total, ""
+	}
+	capped := limit
+	if last > limit {
+		// Already past the cap (by winning bids); the non-bidding team cannot climb any higher.
+		capped = last
+	}
+	return capped, fmt.Sprintf("capped at %d, %d points discarded", limit, total-capped)
+}
@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/squee1945/threespot/server/pkg/deck"
 )
 
 func TestNewScore(t *testing.T) {
@@ -80,6 +81,23 @@ func TestNewScoreFromEncoded(t *testing.T) {
 			wantScores: [][]int{{0, 52}},
 			wantWinner: 1,
 		},
+		{
+			name:       "three teams",
+			encoded:    "52||2-0|1|2||3|4|52",
+			wantToWin:  52,
+			wantScores: [][]int{{0, 1, 2}, {3, 4, 52}},
+			wantWinner: 2,
+		},
+		{
+			name:    "mismatched teams",
+			encoded: "52-0|1||2|3|4",
+			wantErr: true,
+		},
+		{
+			name:    "single team",
+			encoded: "52-1",
+			wantErr: true,
+		},
 		{
 			name:    "bad winner",
 			encoded: "52||A-0|52",
@@ -309,22 +327,34 @@ func TestAttachNote(t *testing.T) {
 	}
 }
 
-func TestScoreSetTopScore62(t *testing.T) {
+func TestScoreSetToWin(t *testing.T) {
 	score := NewScore()
 	if got, want := score.ToWin(), 52; got != want {
 		t.Errorf("got=%d want=%d", got, want)
 	}
-	score.setTopScore62()
+	score.setToWin(62)
 	if got, want := score.ToWin(), 62; got != want {
 		t.Errorf("got=%d want=%d", got, want)
 	}
 }
 
+func TestNewScoreForRules(t *testing.T) {
+	rules := NewRules()
+	rules.SetToWin(64)
+	score := newScoreForRules(rules)
+	if got, want := score.ToWin(), 64; got != want {
+		t.Errorf("ToWin()=%d want=%d", got, want)
+	}
+	if got, want := score.Winner(), NoWinner; got != want {
+		t.Errorf("Winner()=%d want=%d", got, want)
+	}
+}
+
 func TestScoreScores(t *testing.T) {
 	score := NewScore()
-	score.addTally(buildBiddingRound(t, "0|7|P|P|P"), buildTally(t, 8, 7, 3))
-	score.addTally(buildBiddingRound(t, "0|7|P|P|P"), buildTally(t, 8, 8, 2))
-	score.addTally(buildBiddingRound(t, "0|7|P|P|P"), buildTally(t, 8, 9, 1))
+	score.addTally(NewRules(), buildBiddingRound(t, "0|7|P|P|P"), buildTally(t, 8, 7, 3))
+	score.addTally(NewRules(), buildBiddingRound(t, "0|7|P|P|P"), buildTally(t, 8, 8, 2))
+	score.addTally(NewRules(), buildBiddingRound(t, "0|7|P|P|P"), buildTally(t, 8, 9, 1))
 	// Check that we're keeping a running score.
 	want := [][]int{
 		{7, 3},
@@ -377,7 +407,7 @@ func TestScoreCurrentScore(t *testing.T) {
 			score := NewScore()
 			for i := range tc.tallies {
 				tally := buildTally(t, 8, tc.tallies[i][0], tc.tallies[i][1])
-				if _, err := score.addTally(buildBiddingRound(t, tc.bids[i]), tally); err != nil {
+				if _, err := score.addTally(NewRules(), buildBiddingRound(t, tc.bids[i]), tally); err != nil {
 					t.Fatal(err)
 				}
 			}
@@ -415,6 +445,12 @@ func TestScoreAddTally(t *testing.T) {
 			tally: buildTally(t, 8, 10, 0),
 			want:  []int{20, 0},
 		},
+		{
+			name:  "team02 makes six bid",
+			bid:   "0|P|P|6|P",
+			tally: buildTally(t, 8, 6, 4),
+			want:  []int{6, 4},
+		},
 		{
 			name:  "team02 misses no trump bid",
 			bid:   "0|P|P|9N|P",
@@ -451,7 +487,7 @@ func TestScoreAddTally(t *testing.T) {
 		t.Run(tc.name, func(t *testing.T) {
 			score := NewScore()
 
-			hasWinner, err := score.addTally(buildBiddingRound(t, tc.bid), tc.tally)
+			hasWinner, err := score.addTally(NewRules(), buildBiddingRound(t, tc.bid), tc.tally)
 
 			if tc.wantErr && err == nil {
 				t.Fatal("missing expected error")
@@ -473,6 +509,104 @@ func TestScoreAddTally(t *testing.T) {
 	}
 }
 
+func TestScoreAddTallyThreeTeams(t *testing.T) {
+	testCases := []struct {
+		name       string
+		players    int
+		bid        string
+		last       string
+		points     []int
+		tricks     []int
+		want       []int
+		wantNotes  []ScoreNote
+		wantWinner int
+	}{
+		{
+			name:       "three players, bidder makes bid",
+			players:    3,
+			bid:        "0/3|P|8|P",
+			last:       "52-10|20|30",
+			points:     []int{1, 8, 1},
+			tricks:     []int{1, 6, 1},
+			want:       []int{11, 28, 31},
+			wantNotes:  []ScoreNote{{Team: 1, Index: 1, Note: "made 8 bid"}},
+			wantWinner: NoWinner,
+		},
+		{
+			name:       "three players, bidder misses bid",
+			players:    3,
+			bid:        "0/3|P|P|9",
+			last:       "52-10|20|30",
+			points:     []int{3, 4, 3},
+			tricks:     []int{3, 2, 3},
+			want:       []int{13, 24, 21},
+			wantNotes:  []ScoreNote{{Team: 2, Index: 1, Note: "missed 9 bid"}},
+			wantWinner: NoWinner,
+		},
+		{
+			name:       "six players, partners bid out",
+			players:    6,
+			bid:        "0/6|P|P|P|P|8|P",
+			last:       "52-10|45|30",
+			points:     []int{1, 8, 1},
+			tricks:     []int{1, 6, 1},
+			want:       []int{11, 53, 31},
+			wantNotes:  []ScoreNote{{Team: 1, Index: 1, Note: "bid out 8"}},
+			wantWinner: 1,
+		},
+		{
+			name:       "three players, Kaiser",
+			players:    3,
+			bid:        "0/3|K|P|P",
+			last:       "52-10|20|30",
+			points:     []int{10, 0, 0},
+			tricks:     []int{8, 0, 0},
+			want:       []int{62, 20, 30},
+			wantNotes:  []ScoreNote{{Team: 0, Index: 1, Note: "bid out Kaiser"}},
+			wantWinner: 0,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rules := NewRules()
+			rules.SetPlayers(tc.players)
+			last, err := NewScoreFromEncoded(tc.last)
+			if err != nil {
+				t.Fatal(err)
+			}
+			sc := &score{teams: NumTeams(tc.players), toWin: last.ToWin(), winner: NoWinner, scores: last.Scores()}
+			tl := &tally{points: tc.points, tricks: tc.tricks, trickCount: 8}
+
+			hasWinner, err := sc.addTally(rules, buildBiddingRound(t, tc.bid), tl)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got, want := hasWinner, tc.wantWinner != NoWinner; got != want {
+				t.Errorf("hasWinner=%t want=%t", got, want)
+			}
+			if got, want := sc.Winner(), tc.wantWinner; got != want {
+				t.Errorf("score.Winner()=%d want=%d", got, want)
+			}
+			if diff := cmp.Diff(tc.want, sc.CurrentScore()); diff != "" {
+				t.Errorf("score.CurrentScore() mismatch (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.wantNotes, sc.Notes()); diff != "" {
+				t.Errorf("score.Notes() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestScoreAddTallyMismatchedTeams(t *testing.T) {
+	score := NewScore()
+	tally := &tally{points: []int{1, 8, 1}, tricks: []int{1, 6, 1}, trickCount: 8}
+	if _, err := score.addTally(NewRules(), buildBiddingRound(t, "0|P|8|P|P"), tally); err == nil {
+		t.Errorf("missing expected error")
+	}
+}
+
 func TestEndOfGameScoreAddTally(t *testing.T) {
 	testCases := []struct {
 		name         string
@@ -593,7 +727,7 @@ func TestEndOfGameScoreAddTally(t *testing.T) {
 				t.Fatal(err)
 			}
 
-			hasWinner, err := score.addTally(buildBiddingRound(t, tc.bid), tc.tally)
+			hasWinner, err := score.addTally(NewRules(), buildBiddingRound(t, tc.bid), tc.tally)
 			if err != nil {
 				t.Fatal(err)
 			}
@@ -615,3 +749,450 @@ func TestEndOfGameScoreAddTally(t *testing.T) {
 		})
 	}
 }
+
+func TestScoreAddKaiserTally(t *testing.T) {
+	testCases := []struct {
+		name        string
+		team02Score int
+		team13Score int
+		bid         string
+		tally       Tally
+		want        []int
+		wantNote    ScoreNote
+		wantWinner  int
+	}{
+		{
+			name:        "team02 makes Kaiser bid",
+			team02Score: -10,
+			bid:         "0|K|P|P|P",
+			tally:       buildTallyWithTricks(t, 8, 10, 0, 8, 0),
+			want:        []int{42, 0},
+			wantNote:    ScoreNote{Team: 0, Index: 0, Note: "made Kaiser bid"},
+			wantWinner:  NoWinner,
+		},
+		{
+			name:       "team02 makes Kaiser bid and bids out",
+			bid:        "0|K|P|P|P",
+			tally:      buildTallyWithTricks(t, 8, 10, 0, 8, 0),
+			want:       []int{52, 0},
+			wantNote:   ScoreNote{Team: 0, Index: 0, Note: "bid out Kaiser"},
+			wantWinner: 0,
+		},
+		{
+			name:       "team02 misses Kaiser bid",
+			bid:        "0|K|P|P|P",
+			tally:      buildTallyWithTricks(t, 8, 9, 1, 7, 1),
+			want:       []int{-26, 1},
+			wantNote:   ScoreNote{Team: 0, Index: 0, Note: "missed Kaiser bid"},
+			wantWinner: NoWinner,
+		},
+		{
+			name:        "team13 makes Kaiser bid and bids out",
+			team02Score: 20,
+			team13Score: 10,
+			bid:         "0|P|K|P|P",
+			tally:       buildTallyWithTricks(t, 8, 0, 10, 0, 8),
+			want:        []int{20, 62},
+			wantNote:    ScoreNote{Team: 1, Index: 0, Note: "bid out Kaiser"},
+			wantWinner:  1,
+		},
+		{
+			name:        "team13 misses Kaiser bid with points from the 3 of spades",
+			team02Score: 20,
+			team13Score: 10,
+			bid:         "0|P|K|P|P",
+			tally:       buildTallyWithTricks(t, 8, -2, 12, 1, 7), // Team02 took only the 3 of spades trick.
+			want:        []int{18, -16},
+			wantNote:    ScoreNote{Team: 1, Index: 0, Note: "missed Kaiser bid"},
+			wantWinner:  NoWinner,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			score := NewScore().(*score)
+			if tc.team02Score != 0 || tc.team13Score != 0 {
+				score.scores = [][]int{{tc.team02Score, tc.team13Score}}
+				tc.wantNote.Index++
+			}
+
+			hasWinner, err := score.addTally(NewRules(), buildBiddingRound(t, tc.bid), tc.tally)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got, want := hasWinner, tc.wantWinner != NoWinner; got != want {
+				t.Errorf("hasWinner=%t want=%t", got, want)
+			}
+			if got, want := score.Winner(), tc.wantWinner; got != want {
+				t.Errorf("score.Winner()=%d want=%d", got, want)
+			}
+			if diff := cmp.Diff(tc.want, score.CurrentScore()); diff != "" {
+				t.Errorf("score.CurrentScore() mismatch (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff([]ScoreNote{tc.wantNote}, score.Notes()); diff != "" {
+				t.Errorf("score.Notes() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestScoreAddTallyToWinRules(t *testing.T) {
+	testCases := []struct {
+		name         string
+		toWin        int
+		fixedToWin   bool
+		noTrumpToWin int
+		team02Score  int
+		bid          string
+		tally        Tally
+		wantToWin    int
+		wantWinner   int
+	}{
+		{
+			name:        "default rules raise to 62 on no trump",
+			team02Score: 40,
+			bid:         "0|7N|P|P|P",
+			tally:       buildTally(t, 8, 7, 3),
+			wantToWin:   62,
+			wantWinner:  NoWinner,
+		},
+		{
+			name:        "fixed target is not raised on no trump",
+			fixedToWin:  true,
+			team02Score: 40,
+			bid:         "0|7N|P|P|P",
+			tally:       buildTally(t, 8, 7, 3), // 54 points is enough to win.
+			wantToWin:   52,
+			wantWinner:  0,
+		},
+		{
+			name:         "custom raised target",
+			noTrumpToWin: 72,
+			team02Score:  50,
+			bid:          "0|7N|P|P|P",
+			tally:        buildTally(t, 8, 7, 3), // 64 points is not enough to win.
+			wantToWin:    72,
+			wantWinner:   NoWinner,
+		},
+		{
+			name:        "game to 64 is not lowered by no trump",
+			toWin:       64,
+			team02Score: 50,
+			bid:         "0|7N|P|P|P",
+			tally:       buildTally(t, 8, 7, 3),
+			wantToWin:   64,
+			wantWinner:  0,
+		},
+		{
+			name:        "game to 62 requires 62 without no trump",
+			toWin:       62,
+			team02Score: 50,
+			bid:         "0|7|P|P|P",
+			tally:       buildTally(t, 8, 7, 3),
+			wantToWin:   62,
+			wantWinner:  NoWinner,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rules := NewRules()
+			rules.SetToWin(tc.toWin)
+			rules.SetNoTrumpRaisesToWin(!tc.fixedToWin)
+			rules.SetNoTrumpToWin(tc.noTrumpToWin)
+			score := newScoreForRules(rules).(*score)
+			score.scores = [][]int{{tc.team02Score, 0}}
+
+			if _, err := score.addTally(rules, buildBiddingRound(t, tc.bid), tc.tally); err != nil {
+				t.Fatal(err)
+			}
+
+			if got, want := score.ToWin(), tc.wantToWin; got != want {
+				t.Errorf("ToWin()=%d want=%d", got, want)
+			}
+			if got, want := score.Winner(), tc.wantWinner; got != want {
+				t.Errorf("Winner()=%d want=%d", got, want)
+			}
+		})
+	}
+}
+
+func TestScoreAddTallyDefenderCap(t *testing.T) {
+	testCases := []struct {
+		name        string
+		defenderCap int
+		team02Score int
+		team13Score int
+		bid         string
+		tally       Tally
+		want        []int
+		wantNotes   []ScoreNote
+	}{
+		{
+			name:        "no cap",
+			team02Score: 10,
+			team13Score: 44,
+			bid:         "0|7|P|P|P",
+			tally:       buildTally(t, 8, 7, 3),
+			want:        []int{17, 47},
+			wantNotes:   []ScoreNote{{Team: 0, Index: 1, Note: "made 7 bid"}},
+		},
+		{
+			name:        "team13 defending is capped",
+			defenderCap: 45,
+			team02Score: 10,
+			team13Score: 44,
+			bid:         "0|7|P|P|P",
+			tally:       buildTally(t, 8, 7, 3),
+			want:        []int{17, 45},
+			wantNotes: []ScoreNote{
+				{Team: 0, Index: 1, Note: "made 7 bid"},
+				{Team: 1, Index: 1, Note: "capped at 45, 2 points discarded"},
+			},
+		},
+		{
+			name:        "team02 defending is capped",
+			defenderCap: 45,
+			team02Score: 40,
+			team13Score: 10,
+			bid:         "0|P|7|P|P",
+			tally:       buildTally(t, 8, 8, 2),
+			want:        []int{45, 3},
+			wantNotes: []ScoreNote{
+				{Team: 0, Index: 1, Note: "capped at 45, 3 points discarded"},
+				{Team: 1, Index: 1, Note: "missed 7 bid"},
+			},
+		},
+		{
+			name:        "defenders below the cap are not capped",
+			defenderCap: 45,
+			team02Score: 10,
+			team13Score: 30,
+			bid:         "0|7|P|P|P",
+			tally:       buildTally(t, 8, 7, 3),
+			want:        []int{17, 33},
+			wantNotes:   []ScoreNote{{Team: 0, Index: 1, Note: "made 7 bid"}},
+		},
+		{
+			name:        "defenders already past the cap do not climb",
+			defenderCap: 45,
+			team02Score: 10,
+			team13Score: 48,
+			bid:         "0|7|P|P|P",
+			tally:       buildTally(t, 8, 7, 3),
+			want:        []int{17, 48},
+			wantNotes: []ScoreNote{
+				{Team: 0, Index: 1, Note: "made 7 bid"},
+				{Team: 1, Index: 1, Note: "capped at 45, 3 points discarded"},
+			},
+		},
+		{
+			name:        "defenders losing points are not capped",
+			defenderCap: 45,
+			team02Score: 10,
+			team13Score: 48,
+			bid:         "0|7|P|P|P",
+			tally:       buildTally(t, 8, 13, -3), // Team13 took only the 3 of spades trick.
+			want:        []int{23, 45},
+			wantNotes:   []ScoreNote{{Team: 0, Index: 1, Note: "made 7 bid"}},
+		},
+		{
+			name:        "team13 defending a Kaiser bid is capped",
+			defenderCap: 45,
+			team02Score: 10,
+			team13Score: 44,
+			bid:         "0|K|P|P|P",
+			tally:       buildTallyWithTricks(t, 8, 7, 3, 6, 2),
+			want:        []int{-16, 45},
+			wantNotes: []ScoreNote{
+				{Team: 0, Index: 1, Note: "missed Kaiser bid"},
+				{Team: 1, Index: 1, Note: "capped at 45, 2 points discarded"},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rules := NewRules()
+			rules.SetDefenderCap(tc.defenderCap)
+			score := newScoreForRules(rules).(*score)
+			score.scores = [][]int{{tc.team02Score, tc.team13Score}}
+
+			if _, err := score.addTally(rules, buildBiddingRound(t, tc.bid), tc.tally); err != nil {
+				t.Fatal(err)
+			}
+
+			if diff := cmp.Diff(tc.want, score.CurrentScore()); diff != "" {
+				t.Errorf("score.CurrentScore() mismatch (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.wantNotes, score.Notes()); diff != "" {
+				t.Errorf("score.Notes() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestScoreAddRedeal(t *testing.T) {
+	score := NewScore().(*score)
+	score.scores = [][]int{{10, 4}}
+
+	if err := score.addRedeal(1, "all passed, next dealer deals"); err != nil {
+		t.Fatal(err)
+	}
+
+	if diff := cmp.Diff([][]int{{10, 4}, {10, 4}}, score.Scores()); diff != "" {
+		t.Errorf("score.Scores() mismatch (-want +got):\n%s", diff)
+	}
+	wantNotes := []ScoreNote{{Team: 1, Index: 1, Note: "all passed, next dealer deals"}}
+	if diff := cmp.Diff(wantNotes, score.Notes()); diff != "" {
+		t.Errorf("score.Notes() mismatch (-want +got):\n%s", diff)
+	}
+	if got, want := score.Winner(), NoWinner; got != want {
+		t.Errorf("score.Winner()=%d want=%d", got, want)
+	}
+}
+
+func TestScoreAddHand(t *testing.T) {
+	score := NewScore().(*score)
+	if err := score.addHand(HandRecord{}); err == nil {
+		t.Error("missing expected error for unscored hand")
+	}
+
+	score.scores = [][]int{{0, 0}, {14, 0}}
+	hand := HandRecord{
+		DealerPos:         3,
+		BidLeadPos:        0,
+		Bids:              []Bid{buildBid(t, "P"), buildBid(t, "7N"), buildBid(t, "P"), buildBid(t, "P")},
+		BidderPos:         1,
+		WinningBid:        buildBid(t, "7N"),
+		Trump:             deck.NoTrump,
+		Tricks:            []int{1, 7},
+		Points:            []int{1, 7},
+		FiveOfHeartsTeam:  1,
+		ThreeOfSpadesTeam: NoTeam,
+	}
+	if err := score.addHand(hand); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := score.Hands()[0].ScoreIndex, 1; got != want {
+		t.Errorf("ScoreIndex=%d want=%d", got, want)
+	}
+
+	// Round trip through the encoded form.
+	wantEncoded := "52-0|0||14|0##1|3|0|P,7N,P,P|1|7N|N|1,7|1,7|1|-1"
+	if got, want := score.Encoded(), wantEncoded; got != want {
+		t.Fatalf("score.Encoded()=%q want=%q", got, want)
+	}
+	decoded, err := NewScoreFromEncoded(wantEncoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := decoded.Encoded(), wantEncoded; got != want {
+		t.Errorf("decoded.Encoded()=%q want=%q", got, want)
+	}
+	got := decoded.Hands()[0]
+	if got.ThrownIn() {
+		t.Error("ThrownIn()=true want=false")
+	}
+	if !got.Trump.IsSameAs(deck.NoTrump) {
+		t.Errorf("Trump=%q want=%q", got.Trump, deck.NoTrump)
+	}
+	if !got.WinningBid.IsEqualTo(buildBid(t, "7N")) {
+		t.Errorf("WinningBid=%q want=%q", got.WinningBid.Encoded(), "7N")
+	}
+	if diff := cmp.Diff([]int{1, 7}, got.Points); diff != "" {
+		t.Errorf("Points mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestHandRecordMadeBid(t *testing.T) {
+	testCases := []struct {
+		name   string
+		record HandRecord
+		want   bool
+	}{
+		{name: "made", record: HandRecord{BidderPos: 1, WinningBid: buildBid(t, "7"), Tricks: []int{2, 6}, Points: []int{2, 8}}, want: true},
+		{name: "made exactly", record: HandRecord{BidderPos: 2, WinningBid: buildBid(t, "A"), Tricks: []int{8, 0}, Points: []int{10, 0}, FiveOfHeartsTeam: 0, ThreeOfSpadesTeam: 0}, want: true},
+		{name: "missed by one", record: HandRecord{BidderPos: 0, WinningBid: buildBid(t, "A"), Tricks: []int{7, 1}, Points: []int{9, 1}, FiveOfHeartsTeam: 0, ThreeOfSpadesTeam: 0}, want: false},
+		{name: "missed", record: HandRecord{BidderPos: 0, WinningBid: buildBid(t, "9"), Tricks: []int{6, 2}, Points: []int{3, 7}}, want: false},
+		{name: "made Kaiser", record: HandRecord{BidderPos: 3, WinningBid: buildBid(t, "K"), Tricks: []int{0, 8}, Points: []int{0, 10}}, want: true},
+		{name: "missed Kaiser", record: HandRecord{BidderPos: 3, WinningBid: buildBid(t, "K"), Tricks: []int{1, 7}, Points: []int{1, 9}}, want: false},
+		{name: "thrown in", record: HandRecord{BidderPos: -1}, want: false},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.record.MadeBid(); got != tc.want {
+				t.Errorf("MadeBid()=%t want=%t", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestNewScoreFromEncodedWithHands(t *testing.T) {
+	testCases := []struct {
+		name    string
+		encoded string
+		wantErr bool
+	}{
+		{
+			name:    "thrown in hand with notes",
+			encoded: "52-0|0**1|0|all passed, next dealer deals##0|3|0|P,P,P,P|-1|||0,0|0,0|-1|-1",
+		},
+		{
+			name:    "several hands",
+			encoded: "52-0|0||8|-7##0|3|0|P,P,P,P|-1|||0,0|0,0|-1|-1~~1|3|0|P,P,P,7|3|7|H|8,0|8,0|0|0",
+		},
+		{
+			name:    "seed",
+			encoded: "52-0|0##0|3|0|P,P,P,P|-1|||0,0|0,0|-1|-1|42",
+		},
+		{
+			name:    "seed and deal version",
+			encoded: "52-0|0##0|3|0|P,P,P,P|-1|||0,0|0,0|-1|-1|42|1",
+		},
+		{
+			name:    "bad deal version",
+			encoded: "52-0|0##0|3|0|P,P,P,P|-1|||0,0|0,0|-1|-1|42|X",
+			wantErr: true,
+		},
+		{
+			name:    "index out of range",
+			encoded: "52-0|0##1|3|0|P,P,P,P|-1|||0,0|0,0|-1|-1",
+			wantErr: true,
+		},
+		{
+			name:    "too few parts",
+			encoded: "52-0|0##0|3|0|P,P,P,P|-1|||0,0|0,0|-1",
+			wantErr: true,
+		},
+		{
+			name:    "bad bid",
+			encoded: "52-0|0##0|3|0|P,X,P,P|-1|||0,0|0,0|-1|-1",
+			wantErr: true,
+		},
+		{
+			name:    "bad trump",
+			encoded: "52-0|0##0|3|0|P,P,P,7|3|7|X|8,0|8,0|0|0",
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			score, err := NewScoreFromEncoded(tc.encoded)
+			if tc.wantErr && err == nil {
+				t.Fatal("missing expected error")
+			}
+			if !tc.wantErr && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tc.wantErr {
+				return
+			}
+			if got, want := score.Encoded(), tc.encoded; got != want {
+				t.Errorf("re-encoding does not match got=%q want=%q", got, want)
+			}
+		})
+	}
+}
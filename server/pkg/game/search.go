@@ -0,0 +1,495 @@
+package game
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+	"sort"
+	"time"
+
+	"github.com/squee1945/threespot/server/pkg/deck"
+)
+
+// SearchBudget limits how long a search strategy thinks about each decision. The search stops at whichever limit it
+// reaches first; a zero limit is no limit. A zero budget is DefaultSearchBudget.
+type SearchBudget struct {
+	// Iterations is the number of deals sampled and played out.
+	Iterations int
+	// Duration is the time spent searching.
+	Duration time.Duration
+}
+
+var (
+	// DefaultSearchBudget is the budget of the SearchStrategy bots; it is small enough for PlayBots to take a hand's
+	// worth of their turns while serving a request.
+	DefaultSearchBudget = SearchBudget{Iterations: 200, Duration: 20 * time.Millisecond}
+
+	// TournamentSearchBudget is a budget for play away from the server (e.g., tournaments between strategies), where
+	// each decision can take longer.
+	TournamentSearchBudget = SearchBudget{Iterations: 1000, Duration: 250 * time.Millisecond}
+)
+
+const (
+	// searchExploration is the UCB1 exploration constant.
+	searchExploration = 0.7
+	// searchRewardScale is the difference in points between the teams that is worth a full win or loss.
+	searchRewardScale = 40.0
+	// maxSampleAttempts is how many times a deal that respects the voids shown is tried for, before they are ignored.
+	maxSampleAttempts = 20
+)
+
+var errNoSample = errors.New("no deal is consistent with the cards seen")
+
+// NewSearchStrategy returns a strategy that bids, calls and plays by information set Monte Carlo tree search: it
+// samples deals of the cards it cannot see that are consistent with what it has seen (the cards played, the suits
+// players have shown they are out of, and the cards it passed), plays the rest of the hand out with the engine, and
+// takes the action that did best across them. Cards are passed as by the HardLevel heuristic strategy.
+// Random choices are drawn from the source.
+func NewSearchStrategy(budget SearchBudget, src rand.Source) Strategy {
+	if budget == (SearchBudget{}) {
+		budget = DefaultSearchBudget
+	}
+	return &searchStrategy{
+		budget:  budget,
+		rnd:     rand.New(src),
+		rollout: NewHeuristicStrategy(EasyLevel),
+	}
+}
+
+type searchStrategy struct {
+	budget  SearchBudget
+	rnd     *rand.Rand
+	rollout Strategy // The strategy players follow outside the tree, and the others in the auction.
+}
+
+func (s *searchStrategy) Pass(view *SeatView) (deck.Card, error) {
+	return NewHeuristicStrategy(HardLevel).Pass(view)
+}
+
+func (s *searchStrategy) Bid(view *SeatView) (Bid, error) {
+	if len(view.Legal.Bids) == 1 {
+		return view.Legal.Bids[0], nil
+	}
+	m, err := s.search(view)
+	if err != nil {
+		return nil, err
+	}
+	return m.Bid, nil
+}
+
+func (s *searchStrategy) Trump(view *SeatView) (deck.Suit, error) {
+	m, err := s.search(view)
+	if err != nil {
+		return nil, err
+	}
+	return m.Trump, nil
+}
+
+func (s *searchStrategy) Play(view *SeatView) (deck.Card, error) {
+	if len(view.Legal.Play) == 1 {
+		return view.Legal.Play[0], nil
+	}
+	m, err := s.search(view)
+	if err != nil {
+		return nil, err
+	}
+	return m.Card, nil
+}
+
+// searchNode is a move in the search tree, shared by every sampled deal in which it is legal.
+type searchNode struct {
+	move     Move
+	key      string
+	pos      int // The position of the player that made the move.
+	children []*searchNode
+	visits   int
+	avail    int     // The number of times the move was legal when its parent was reached.
+	reward   float64 // The total reward to the player that made the move.
+}
+
+func (n *searchNode) child(key string) *searchNode {
+	for _, c := range n.children {
+		if c.key == key {
+			return c
+		}
+	}
+	return nil
+}
+
+// ucb is the upper confidence bound of the move, balancing its average reward against how little it was tried.
+func (n *searchNode) ucb() float64 {
+	return n.reward/float64(n.visits) + searchExploration*math.Sqrt(math.Log(float64(n.avail))/float64(n.visits))
+}
+
+// search returns the move that did best over the budget's sampled deals.
+func (s *searchStrategy) search(view *SeatView) (Move, error) {
+	root := &searchNode{pos: -1}
+	start := time.Now()
+	for i := 0; ; i++ {
+		if s.budget.Iterations > 0 && i >= s.budget.Iterations {
+			break
+		}
+		if s.budget.Duration > 0 && time.Since(start) >= s.budget.Duration {
+			break
+		}
+		if err := s.iterate(view, root); err != nil {
+			return Move{}, err
+		}
+	}
+
+	var best *searchNode
+	for _, c := range root.children {
+		if best == nil || c.visits > best.visits {
+			best = c
+		}
+	}
+	if best == nil {
+		// Not a single deal was played out; fall back to the heuristic.
+		return ChooseMove(NewHeuristicStrategy(HardLevel), view)
+	}
+	return best.move, nil
+}
+
+// iterate samples a deal, walks the tree from the root choosing moves until it adds a new one, plays the rest of the
+// hand out, and credits each move on the way with the result for the player that made it. The other players bid and
+// call as the rollout strategy would; searching their auction too makes overbidding look better than it is.
+func (s *searchStrategy) iterate(view *SeatView, root *searchNode) error {
+	e, err := sampleEngine(view, s.rnd)
+	if err == errNoSample {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	path := []*searchNode{root}
+	node := root
+	for !handOver(e) {
+		pos, err := e.PosToPlay()
+		if err != nil {
+			return err
+		}
+		if pos != view.Pos && e.State() != PlayingState {
+			if err := e.Act(pos, s.rollout); err != nil {
+				return err
+			}
+			continue
+		}
+		moves, err := legalMoves(e, pos)
+		if err != nil {
+			return err
+		}
+		if len(moves) == 0 {
+			return ErrNoMove
+		}
+		var untried []Move
+		var tried []*searchNode
+		for _, m := range moves {
+			if c := node.child(moveKey(m)); c != nil {
+				tried = append(tried, c)
+			} else {
+				untried = append(untried, m)
+			}
+		}
+		for _, c := range tried {
+			c.avail++
+		}
+
+		if len(untried) > 0 {
+			m := untried[s.rnd.Intn(len(untried))]
+			if err := e.ApplyMove(pos, m); err != nil {
+				return err
+			}
+			c := &searchNode{move: m, key: moveKey(m), pos: pos, avail: 1}
+			node.children = append(node.children, c)
+			path = append(path, c)
+			break
+		}
+
+		best := tried[0]
+		for _, c := range tried[1:] {
+			if c.ucb() > best.ucb() {
+				best = c
+			}
+		}
+		if err := e.ApplyMove(pos, best.move); err != nil {
+			return err
+		}
+		node = best
+		path = append(path, node)
+	}
+
+	for !handOver(e) {
+		pos, err := e.PosToPlay()
+		if err != nil {
+			return err
+		}
+		if err := e.Act(pos, s.rollout); err != nil {
+			return err
+		}
+	}
+
+	for _, n := range path[1:] {
+		n.visits++
+		n.reward += handReward(e, n.pos)
+	}
+	return nil
+}
+
+// handOver returns true once the hand the engine was sampled in has been scored or thrown in.
+func handOver(e *Engine) bool {
+	return len(e.score.Scores()) > 0
+}
+
+// handReward returns the reward, on [0,1], to the player in pos for the hand just scored.
+func handReward(e *Engine, pos int) float64 {
+	scores := e.score.CurrentScore()
+	team := TeamOf(pos, e.rules.Players())
+	best := math.Inf(-1)
+	for other, score := range scores {
+		if other != team {
+			best = math.Max(best, float64(score))
+		}
+	}
+	return math.Max(0, math.Min(1, 0.5+(float64(scores[team])-best)/searchRewardScale))
+}
+
+// legalMoves returns the moves the player in pos may make; bots never claim misdeals.
+func legalMoves(e *Engine, pos int) ([]Move, error) {
+	legal, err := e.LegalActions(pos)
+	if err != nil {
+		return nil, err
+	}
+	var moves []Move
+	if legal.Deal {
+		moves = append(moves, Move{Action: DealAction})
+	}
+	for _, card := range legal.Pass {
+		moves = append(moves, Move{Action: PassAction, Card: card})
+	}
+	for _, b := range legal.Bids {
+		moves = append(moves, Move{Action: BidAction, Bid: b})
+	}
+	for _, trump := range legal.Trumps {
+		moves = append(moves, Move{Action: TrumpAction, Trump: trump})
+	}
+	for _, card := range legal.Play {
+		moves = append(moves, Move{Action: PlayAction, Card: card})
+	}
+	return moves, nil
+}
+
+// moveKey identifies the move among the moves of a player.
+func moveKey(m Move) string {
+	switch m.Action {
+	case PassAction, PlayAction:
+		return string(m.Action) + m.Card.Encoded()
+	case BidAction:
+		return string(m.Action) + m.Bid.Encoded()
+	case TrumpAction:
+		return string(m.Action) + m.Trump.Encoded()
+	}
+	return string(m.Action)
+}
+
+// sampleEngine returns an engine in the state of play of the view, with the cards the player cannot see dealt at
+// random, consistent with what they have seen. The engine starts with an empty score; handOver reports when the
+// hand is done. Cards are passed without searching, so the view is never in the middle of a passing round.
+func sampleEngine(view *SeatView, rnd *rand.Rand) (*Engine, error) {
+	players := view.Rules.Players()
+	hands, err := sampleHands(view, rnd)
+	if err != nil {
+		return nil, err
+	}
+	e := &Engine{
+		rules:            view.Rules,
+		score:            newScoreForRules(view.Rules),
+		currentDealerPos: view.DealerPos,
+		currentHands:     hands,
+		tricks:           append([]Trick(nil), view.Tricks...),
+		currentTally:     newTallyForTeams(NumTeams(players)),
+		seeds:            rnd,
+	}
+
+	if e.currentBidding, err = newBiddingRoundForPlayers(view.LeadBidPos, players); err != nil {
+		return nil, err
+	}
+	for i, b := range view.Bids {
+		if err := e.currentBidding.placeBid((view.LeadBidPos+i)%players, b); err != nil {
+			return nil, err
+		}
+	}
+	if e.passedCards, err = samplePassing(view); err != nil {
+		return nil, err
+	}
+	for _, trick := range view.Tricks {
+		if err := e.currentTally.addTrick(trick); err != nil {
+			return nil, err
+		}
+		e.lastTrick = trick
+	}
+	if view.Trick != nil {
+		e.currentTrick = view.Trick.clone()
+	}
+	e.updatePhase()
+	return e, nil
+}
+
+// samplePassing returns the passing round of the view's hand. Once passing is done, the cards the player did not
+// see passed are stood in for by others from the deck; the engine does not look at them again.
+func samplePassing(view *SeatView) (PassingRound, error) {
+	rules := view.Rules
+	players := rules.Players()
+	leadPos := (view.DealerPos + 1) % players
+	if !rules.PassCard() {
+		return nil, nil
+	}
+
+	var round PassingRound
+	var err error
+	switch {
+	case rules.PassTiming() == PassBeforeBidding && view.PassTo >= 0 && view.State != PassingState:
+		round, err = newPassingRound(leadPos, rules.PassCount(), (view.PassTo-view.Pos+players)%players, defaultPassStep, players)
+	case rules.PassTiming() == PassAfterAuction && (view.State == CallingState || view.State == PlayingState):
+		round, err = newPartnerExchange(view.WinningBidPos, rules.PassCount(), players)
+	default:
+		// Passing is still to come; the engine starts the partner exchange itself once the auction is done.
+		return newPassingRoundForRules(rules, leadPos, 0)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	used := append(append([]deck.Card(nil), view.Passed...), view.Received...)
+	stand := outstanding(deckCards(players), used)
+	for _, passer := range round.Passers() {
+		var cards []deck.Card
+		switch {
+		case passer == view.Pos:
+			cards = view.Passed
+		case round.ToPos(passer) == view.Pos:
+			cards = view.Received
+		default:
+			cards, stand = stand[:round.PerPlayer()], stand[round.PerPlayer():]
+		}
+		for _, card := range cards {
+			if err := round.passCard(passer, card); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return round, nil
+}
+
+// sampleHands deals the cards the player cannot see to the other players at random: each gets as many as they hold,
+// none of a suit they have shown they are out of, and the player they passed to holds the cards they passed.
+func sampleHands(view *SeatView, rnd *rand.Rand) (Hands, error) {
+	players := view.Rules.Players()
+	seen := append([]deck.Card(nil), view.Hand...)
+	for _, trick := range view.Tricks {
+		seen = append(seen, trick.Cards()...)
+	}
+	if view.Trick != nil {
+		seen = append(seen, view.Trick.Cards()...)
+	}
+
+	known := make([][]deck.Card, players)
+	known[view.Pos] = append([]deck.Card(nil), view.Hand...)
+	if view.PassTo >= 0 && view.PassTo != view.Pos {
+		for _, card := range view.Passed {
+			if findCard(seen, card) == nil {
+				known[view.PassTo] = append(known[view.PassTo], card)
+				seen = append(seen, card)
+			}
+		}
+	}
+	need := make([]int, players)
+	total := 0
+	for pos := range need {
+		need[pos] = view.HandCounts[pos] - len(known[pos])
+		if need[pos] < 0 {
+			return nil, errNoSample
+		}
+		total += need[pos]
+	}
+	unseen := outstanding(deckCards(players), seen)
+	if total != len(unseen) {
+		return nil, errNoSample
+	}
+
+	voids := shownVoids(view)
+	for attempt := 0; attempt <= maxSampleAttempts; attempt++ {
+		if attempt == maxSampleAttempts {
+			// The voids could not be kept to by chance; deal without them rather than not at all.
+			voids = make([]map[string]bool, players)
+		}
+		sets, ok := dealUnseen(unseen, known, need, voids, rnd)
+		if ok {
+			return NewHands(sets)
+		}
+	}
+	return nil, errNoSample
+}
+
+// dealUnseen deals the unseen cards to the players that need them, respecting the voids; false if it ran into a card
+// no one could take.
+func dealUnseen(unseen []deck.Card, known [][]deck.Card, need []int, voids []map[string]bool, rnd *rand.Rand) ([][]deck.Card, bool) {
+	sets := make([][]deck.Card, len(known))
+	for pos := range known {
+		sets[pos] = append([]deck.Card(nil), known[pos]...)
+	}
+	left := append([]int(nil), need...)
+
+	// Deal the cards of the suits fewest players can take first.
+	cards := append([]deck.Card(nil), unseen...)
+	rnd.Shuffle(len(cards), func(i, j int) { cards[i], cards[j] = cards[j], cards[i] })
+	takers := make(map[string]int)
+	for _, suit := range trumpSuits {
+		for pos := range need {
+			if need[pos] > 0 && !voids[pos][suit.Encoded()] {
+				takers[suit.Encoded()]++
+			}
+		}
+	}
+	sort.SliceStable(cards, func(i, j int) bool {
+		return takers[cards[i].Suit().Encoded()] < takers[cards[j].Suit().Encoded()]
+	})
+
+	for _, card := range cards {
+		var eligible []int
+		for pos := range left {
+			if left[pos] > 0 && !voids[pos][card.Suit().Encoded()] {
+				eligible = append(eligible, pos)
+			}
+		}
+		if len(eligible) == 0 {
+			return nil, false
+		}
+		pos := eligible[rnd.Intn(len(eligible))]
+		sets[pos] = append(sets[pos], card)
+		left[pos]--
+	}
+	return sets, true
+}
+
+// shownVoids returns, for each player, the suits they have shown they are out of by not following the lead.
+func shownVoids(view *SeatView) []map[string]bool {
+	players := view.Rules.Players()
+	voids := make([]map[string]bool, players)
+	for pos := range voids {
+		voids[pos] = make(map[string]bool)
+	}
+	tricks := view.Tricks
+	if view.Trick != nil {
+		tricks = append(append([]Trick(nil), tricks...), view.Trick)
+	}
+	for _, trick := range tricks {
+		cards := trick.Cards()
+		for i, card := range cards {
+			if lead := cards[0].Suit(); card.Suit() != lead {
+				voids[(trick.LeadPos()+i)%players][lead.Encoded()] = true
+			}
+		}
+	}
+	return voids
+}
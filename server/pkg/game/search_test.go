@@ -0,0 +1,299 @@
+package game
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/squee1945/threespot/server/pkg/deck"
+)
+
+// playTo returns an engine dealt the hands by dealer 3, after the actions are taken in turn: each is a card to pass
+// or play, a bid, or a trump suit, as the state of the engine calls for.
+func playTo(t *testing.T, rules Rules, encodedHands string, actions ...string) *Engine {
+	t.Helper()
+	e, err := NewEngine(rules, 0, rand.NewSource(7))
+	if err != nil {
+		t.Fatal(err)
+	}
+	hands, err := NewHandsFromEncoded(encodedHands)
+	if err != nil {
+		t.Fatal(err)
+	}
+	e.QueueDeals(Dealt{DealerPos: 3, Hands: hands})
+	for len(e.Score().Scores()) == 0 {
+		playEngineAction(t, e)
+	}
+	if err := e.Deal(e.DealerPos()); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, action := range actions {
+		pos, err := e.PosToPlay()
+		if err != nil {
+			t.Fatal(err)
+		}
+		switch e.State() {
+		case PassingState:
+			err = e.Pass(pos, buildCard(t, action))
+		case BiddingState:
+			err = e.Bid(pos, buildBid(t, action))
+		case CallingState:
+			err = e.Call(pos, buildSuit(t, action))
+		case PlayingState:
+			err = e.Play(pos, buildCard(t, action))
+		}
+		if err != nil {
+			t.Fatalf("%s by %d in %v: %v", action, pos, e.State(), err)
+		}
+	}
+	return e
+}
+
+const searchHands = "KD|QD|JD|AC|KC|QC|JC|TC+5H|AH|KH|QH|JH|TH|9H|8H+8D|7D|AS|KS|QS|JS|TS|9S+AD|9D|TD|8S|3S|9C|8C|7C"
+
+func TestShownVoids(t *testing.T) {
+	e := playTo(t, NewRules(), searchHands, "7", "P", "P", "P", "C", "KD", "5H", "8D", "AD", "8S", "TC")
+	view, err := e.SeatView(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := shownVoids(view)
+
+	want := []map[string]bool{{"S": true}, {"D": true}, {}, {}}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("shownVoids() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestSampleHands(t *testing.T) {
+	e := playTo(t, NewRules(), searchHands, "7", "P", "P", "P", "C", "KD", "5H", "8D", "AD", "8S")
+	view, err := e.SeatView(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	voids := shownVoids(view)
+	rnd := rand.New(rand.NewSource(1))
+
+	for i := 0; i < 100; i++ {
+		hands, err := sampleHands(view, rnd)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		all := append([]deck.Card(nil), e.Tricks()[0].Cards()...)
+		all = append(all, e.CurrentTrick().Cards()...)
+		for pos := 0; pos < 4; pos++ {
+			hand, err := hands.Hand(pos)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got, want := len(hand.Cards()), view.HandCounts[pos]; got != want {
+				t.Fatalf("sample %d: player %d has %d cards, want %d", i, pos, got, want)
+			}
+			for _, card := range hand.Cards() {
+				if voids[pos][card.Suit().Encoded()] {
+					t.Fatalf("sample %d: player %d has %s, but is out of the suit", i, pos, card.Encoded())
+				}
+			}
+			all = append(all, hand.Cards()...)
+		}
+		own, err := hands.Hand(0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, want := encodeCards(own.Cards()), encodeCards(view.Hand); !cmp.Equal(got, want) {
+			t.Fatalf("sample %d: own hand=%v want=%v", i, got, want)
+		}
+		if missing := outstanding(deckCards(4), all); len(missing) > 0 || len(all) != len(deckCards(4)) {
+			t.Fatalf("sample %d: dealt %d cards, missing %v", i, len(all), encodeCards(missing))
+		}
+	}
+}
+
+func TestSampleHandsPassedCards(t *testing.T) {
+	rules, err := NewRulesFromPreset("prairie-pass-card")
+	if err != nil {
+		t.Fatal(err)
+	}
+	e := playTo(t, rules, searchHands, "TC", "8H", "9S", "7C")
+	view, err := e.SeatView(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rnd := rand.New(rand.NewSource(1))
+
+	for i := 0; i < 20; i++ {
+		hands, err := sampleHands(view, rnd)
+		if err != nil {
+			t.Fatal(err)
+		}
+		to, err := hands.Hand(view.PassTo)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !to.Contains(buildCard(t, "TC")) {
+			t.Fatalf("sample %d: hand %s of player %d does not have the passed TC", i, to.Encoded(), view.PassTo)
+		}
+	}
+}
+
+// encodeView returns what the player can see in the view, save the score.
+func encodeView(view *SeatView) string {
+	var bids, legalBids, trumps, tricks []string
+	for _, b := range view.Bids {
+		bids = append(bids, b.Encoded())
+	}
+	for _, b := range view.Legal.Bids {
+		legalBids = append(legalBids, b.Encoded())
+	}
+	for _, trump := range view.Legal.Trumps {
+		trumps = append(trumps, trump.Encoded())
+	}
+	for _, trick := range view.Tricks {
+		tricks = append(tricks, trick.Encoded())
+	}
+	winningBid, trump, trick := "", "", ""
+	if view.WinningBid != nil {
+		winningBid = view.WinningBid.Encoded()
+	}
+	if view.Trump != nil {
+		trump = view.Trump.Encoded()
+	}
+	if view.Trick != nil {
+		trick = view.Trick.Encoded()
+	}
+	return fmt.Sprintf("state=%v pass=%v bids=%v trumps=%v play=%v hand=%v counts=%v passTo=%d passed=%v received=%v bids=%v winning=%s/%d trump=%s trick=%s tricks=%v tally=%v",
+		view.State, encodeCards(view.Legal.Pass), legalBids, trumps, encodeCards(view.Legal.Play), encodeCards(view.Hand), view.HandCounts,
+		view.PassTo, encodeCards(view.Passed), encodeCards(view.Received), bids, winningBid, view.WinningBidPos, trump, trick, tricks, view.Tally)
+}
+
+func TestSampleEngine(t *testing.T) {
+	pass, err := NewRulesFromPreset("prairie-pass-card")
+	if err != nil {
+		t.Fatal(err)
+	}
+	after, err := NewRulesFromPreset("prairie-pass-card")
+	if err != nil {
+		t.Fatal(err)
+	}
+	after.SetPassTiming(PassAfterAuction)
+
+	testCases := []struct {
+		name    string
+		rules   Rules
+		actions []string
+		pos     int
+	}{
+		{name: "bidding", rules: NewRules(), actions: []string{"7", "P"}, pos: 2},
+		{name: "calling", rules: NewRules(), actions: []string{"7", "P", "P", "P"}, pos: 0},
+		{name: "leading", rules: NewRules(), actions: []string{"7", "P", "P", "P", "C"}, pos: 0},
+		{name: "following", rules: NewRules(), actions: []string{"7", "P", "P", "P", "C", "KD", "5H", "8D", "AD", "8S", "TC"}, pos: 1},
+		{name: "no trump", rules: NewRules(), actions: []string{"7N", "P", "P", "P", "KD"}, pos: 1},
+		{name: "after passing", rules: pass, actions: []string{"TC", "8H", "9S", "7C", "7", "P", "P", "P", "C", "KD"}, pos: 1},
+		{name: "bidding before the partner exchange", rules: after, actions: []string{"7", "P"}, pos: 2},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			e := playTo(t, tc.rules, searchHands, tc.actions...)
+			if pos, err := e.PosToPlay(); err != nil || pos != tc.pos {
+				t.Fatalf("PosToPlay()=%d, %v want=%d", pos, err, tc.pos)
+			}
+			view, err := e.SeatView(tc.pos)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			sampled, err := sampleEngine(view, rand.New(rand.NewSource(1)))
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := sampled.SeatView(tc.pos)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got, want := encodeView(got), encodeView(view); got != want {
+				t.Errorf("sampled SeatView()=\n%s\nwant=\n%s", got, want)
+			}
+		})
+	}
+}
+
+func TestSearchStrategyTakesTheFiveOfHearts(t *testing.T) {
+	e := playTo(t, NewRules(), searchHands, "7", "P", "P", "P", "C", "KD", "5H", "8D")
+	view, err := e.SeatView(3)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := NewSearchStrategy(SearchBudget{Iterations: 200}, rand.NewSource(1)).Play(view)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got.Encoded() != "AD" {
+		t.Errorf("Play()=%s want=AD", got.Encoded())
+	}
+}
+
+func TestSearchBudget(t *testing.T) {
+	e, err := NewEngine(NewRules(), 0, rand.NewSource(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	view, err := e.SeatView(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Now()
+	if _, err := NewSearchStrategy(SearchBudget{Duration: 20 * time.Millisecond}, rand.NewSource(1)).Bid(view); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("search with 20ms budget took %v", elapsed)
+	}
+
+	// The same source and iterations give the same bid.
+	var bids []string
+	for i := 0; i < 2; i++ {
+		b, err := NewSearchStrategy(SearchBudget{Iterations: 50}, rand.NewSource(1)).Bid(view)
+		if err != nil {
+			t.Fatal(err)
+		}
+		bids = append(bids, b.Encoded())
+	}
+	if bids[0] != bids[1] {
+		t.Errorf("bids with the same source differ: %v", bids)
+	}
+}
+
+func TestSearchStrategyPlaysLegally(t *testing.T) {
+	for _, preset := range Presets() {
+		t.Run(preset.ID, func(t *testing.T) {
+			rules, err := NewRulesFromPreset(preset.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			e, err := NewEngine(rules, 0, rand.NewSource(3))
+			if err != nil {
+				t.Fatal(err)
+			}
+			strategy := NewSearchStrategy(SearchBudget{Iterations: 10}, rand.NewSource(3))
+
+			for len(e.Score().Scores()) < 2 && e.State() != CompletedState {
+				pos, err := e.PosToPlay()
+				if err != nil {
+					t.Fatal(err)
+				}
+				if err := e.Act(pos, strategy); err != nil {
+					t.Fatalf("Act(%d) in %v: %v", pos, e.State(), err)
+				}
+			}
+		})
+	}
+}
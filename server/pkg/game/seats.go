@@ -0,0 +1,113 @@
+package game
+
+import (
+	"context"
+	"strconv"
+)
+
+var (
+	ErrNotJoining           = newError(CodeNotJoining, "The game has already started")
+	ErrNotInProgress        = newError(CodeNotInProgress, "The game is not in progress")
+	ErrNotOrganizer         = newError(CodeNotOrganizer, "Only the organizer can do this")
+	ErrOrganizerCannotLeave = newError(CodeOrganizerCannotLeave, "The organizer cannot leave the game")
+	ErrSeatSwapPending      = newError(CodeSeatSwapPending, "A seat swap has already been requested")
+	ErrNoSeatSwap           = newError(CodeNoSeatSwap, "No seat swap has been requested of you")
+)
+
+// SeatSwap is a player's request to swap seats with another player before the game starts, awaiting their consent.
+type SeatSwap struct {
+	// Pos is the position of the requesting player.
+	Pos int
+	// ToPos is the position asked for; the player there responds to the request.
+	ToPos int
+}
+
+func (g *game) IsOrganizer(player Player) bool {
+	return player != nil && player.ID() == g.organizerID
+}
+
+func (g *game) SeatSwap() *SeatSwap {
+	return g.seatSwap
+}
+
+func (g *game) LeaveGame(ctx context.Context, player Player) (Game, error) {
+	if g.State() != JoiningState {
+		return nil, ErrNotJoining
+	}
+	if g.IsOrganizer(player) {
+		return nil, ErrOrganizerCannotLeave
+	}
+	pos, err := g.PlayerPos(player)
+	if err != nil {
+		return nil, err
+	}
+	if g.seatSwap != nil && (g.seatSwap.Pos == pos || g.seatSwap.ToPos == pos) {
+		g.seatSwap = nil
+	}
+	g.players[pos] = nil
+	g.record(LeaveAction, player, pos, "")
+	return g.save(ctx)
+}
+
+func (g *game) ChangeSeat(ctx context.Context, player Player, pos int) (Game, error) {
+	if g.State() != JoiningState {
+		return nil, ErrNotJoining
+	}
+	from, err := g.PlayerPos(player)
+	if err != nil {
+		return nil, err
+	}
+	if pos < 0 || pos >= len(g.players) || pos == from {
+		return nil, ErrInvalidPosition
+	}
+	if g.seatSwap != nil {
+		return nil, ErrSeatSwapPending
+	}
+
+	if g.players[pos] == nil {
+		g.players[pos], g.players[from] = player, nil
+		g.record(MoveAction, player, from, strconv.Itoa(pos))
+		return g.save(ctx)
+	}
+	g.seatSwap = &SeatSwap{Pos: from, ToPos: pos}
+	g.record(SeatSwapRequestAction, player, from, strconv.Itoa(pos))
+	return g.save(ctx)
+}
+
+func (g *game) RespondToSeatSwap(ctx context.Context, player Player, approve bool) (Game, error) {
+	pos, err := g.PlayerPos(player)
+	if err != nil {
+		return nil, err
+	}
+	if g.seatSwap == nil || g.seatSwap.ToPos != pos {
+		return nil, ErrNoSeatSwap
+	}
+
+	from := g.seatSwap.Pos
+	g.seatSwap = nil
+	if !approve {
+		g.record(SeatSwapRejectAction, player, pos, strconv.Itoa(from))
+		return g.save(ctx)
+	}
+	g.players[pos], g.players[from] = g.players[from], g.players[pos]
+	g.record(SeatSwapApproveAction, player, pos, strconv.Itoa(from))
+	return g.save(ctx)
+}
+
+func (g *game) ReplacePlayer(ctx context.Context, organizer Player, pos int, substitute Player) (Game, error) {
+	if !g.IsOrganizer(organizer) {
+		return nil, ErrNotOrganizer
+	}
+	if state := g.State(); state == JoiningState || state == CompletedState {
+		return nil, ErrNotInProgress
+	}
+	if pos < 0 || pos >= len(g.players) {
+		return nil, ErrInvalidPosition
+	}
+	if _, err := g.PlayerPos(substitute); err == nil {
+		return nil, ErrPlayerAlreadyAdded
+	}
+	g.players[pos] = substitute
+	g.record(ReplaceAction, organizer, pos, substitute.ID())
+	return g.save(ctx)
+}
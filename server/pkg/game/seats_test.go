@@ -0,0 +1,253 @@
+package game
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/squee1945/threespot/server/pkg/storage"
+)
+
+// startSeatGame returns a new game with players seated at the positions, and all the players that may join it.
+// The organizer is player 0, at position 0.
+func startSeatGame(t *testing.T, ctx context.Context, positions ...int) (Game, []Player) {
+	t.Helper()
+	gameStore := storage.NewFakeGameStore(nil)
+	playerStore := storage.NewFakePlayerStore()
+	var ps []Player
+	for i := 0; i < 5; i++ {
+		ps = append(ps, buildPlayer(t, playerStore, fmt.Sprintf("PLAYER%d", i)))
+	}
+	g, err := NewGame(ctx, gameStore, playerStore, "ABC123", ps[0], NewRules())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, pos := range positions {
+		if g, err = g.AddPlayer(ctx, ps[pos], pos); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return g, ps
+}
+
+func playerIDs(g Game) []string {
+	var ids []string
+	for _, p := range g.Players() {
+		if p == nil {
+			ids = append(ids, "")
+			continue
+		}
+		ids = append(ids, p.ID())
+	}
+	return ids
+}
+
+func TestLeaveGame(t *testing.T) {
+	ctx := context.Background()
+	g, ps := startSeatGame(t, ctx, 1, 2)
+
+	if _, err := g.LeaveGame(ctx, ps[0]); err != ErrOrganizerCannotLeave {
+		t.Errorf("LeaveGame(organizer)=%v want=%v", err, ErrOrganizerCannotLeave)
+	}
+	if _, err := g.LeaveGame(ctx, ps[3]); err != ErrNotInGame {
+		t.Errorf("LeaveGame(not seated)=%v want=%v", err, ErrNotInGame)
+	}
+
+	g, err := g.LeaveGame(ctx, ps[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := playerIDs(g), []string{"PLAYER0", "", "PLAYER2", ""}; !cmp.Equal(got, want) {
+		t.Errorf("players=%v want=%v", got, want)
+	}
+
+	// The seat can be taken again, and the game starts once every seat is filled.
+	if g, err = g.AddPlayer(ctx, ps[4], 1); err != nil {
+		t.Fatal(err)
+	}
+	if g, err = g.AddPlayer(ctx, ps[3], 3); err != nil {
+		t.Fatal(err)
+	}
+	if g.State() == JoiningState {
+		t.Fatalf("State()=%v after all seats were filled", g.State())
+	}
+	if _, err := g.LeaveGame(ctx, ps[4]); err != ErrNotJoining {
+		t.Errorf("LeaveGame(started)=%v want=%v", err, ErrNotJoining)
+	}
+}
+
+func TestChangeSeat(t *testing.T) {
+	ctx := context.Background()
+	g, ps := startSeatGame(t, ctx, 1)
+
+	// An empty seat is taken straight away.
+	g, err := g.ChangeSeat(ctx, ps[1], 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := playerIDs(g), []string{"PLAYER0", "", "PLAYER1", ""}; !cmp.Equal(got, want) {
+		t.Errorf("players=%v want=%v", got, want)
+	}
+	if _, err := g.ChangeSeat(ctx, ps[1], 2); err != ErrInvalidPosition {
+		t.Errorf("ChangeSeat(own seat)=%v want=%v", err, ErrInvalidPosition)
+	}
+	if _, err := g.ChangeSeat(ctx, ps[1], 4); err != ErrInvalidPosition {
+		t.Errorf("ChangeSeat(no seat)=%v want=%v", err, ErrInvalidPosition)
+	}
+
+	// A taken seat needs the consent of the player there.
+	if g, err = g.ChangeSeat(ctx, ps[0], 2); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := g.SeatSwap(), (&SeatSwap{Pos: 0, ToPos: 2}); !cmp.Equal(got, want) {
+		t.Errorf("SeatSwap()=%+v want=%+v", got, want)
+	}
+	if _, err := g.ChangeSeat(ctx, ps[1], 3); err != ErrSeatSwapPending {
+		t.Errorf("ChangeSeat(swap pending)=%v want=%v", err, ErrSeatSwapPending)
+	}
+	if _, err := g.RespondToSeatSwap(ctx, ps[0], true); err != ErrNoSeatSwap {
+		t.Errorf("RespondToSeatSwap(requester)=%v want=%v", err, ErrNoSeatSwap)
+	}
+	if g, err = g.RespondToSeatSwap(ctx, ps[1], true); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := playerIDs(g), []string{"PLAYER1", "", "PLAYER0", ""}; !cmp.Equal(got, want) {
+		t.Errorf("players=%v want=%v", got, want)
+	}
+	if g.SeatSwap() != nil {
+		t.Errorf("SeatSwap()=%+v want=nil", g.SeatSwap())
+	}
+	if !g.IsOrganizer(ps[0]) || g.IsOrganizer(ps[1]) {
+		t.Errorf("organizer changed with seats")
+	}
+
+	// A swap can be refused.
+	if g, err = g.ChangeSeat(ctx, ps[1], 2); err != nil {
+		t.Fatal(err)
+	}
+	if g, err = g.RespondToSeatSwap(ctx, ps[0], false); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := playerIDs(g), []string{"PLAYER1", "", "PLAYER0", ""}; !cmp.Equal(got, want) {
+		t.Errorf("players=%v want=%v", got, want)
+	}
+
+	// The seats are stored, and replaying the log seats the players the same way.
+	gi := g.(*game)
+	stored, err := GetGame(ctx, gi.gameStore, gi.playerStore, g.ID())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := playerIDs(stored), playerIDs(g); !cmp.Equal(got, want) {
+		t.Errorf("stored players=%v want=%v", got, want)
+	}
+	replayed, err := ReplayGame(ctx, gi.gameStore, gi.playerStore, g.ID(), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := playerIDs(replayed), playerIDs(g); !cmp.Equal(got, want) {
+		t.Errorf("replayed players=%v want=%v", got, want)
+	}
+}
+
+func TestLeaveGameWithdrawsSeatSwap(t *testing.T) {
+	ctx := context.Background()
+	g, ps := startSeatGame(t, ctx, 1, 2)
+	g, err := g.ChangeSeat(ctx, ps[1], 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if g, err = g.LeaveGame(ctx, ps[2]); err != nil {
+		t.Fatal(err)
+	}
+
+	if g.SeatSwap() != nil {
+		t.Errorf("SeatSwap()=%+v want=nil", g.SeatSwap())
+	}
+}
+
+func TestReplacePlayer(t *testing.T) {
+	ctx := context.Background()
+	g, ps := startSeatGame(t, ctx, 1, 2)
+	if _, err := g.ReplacePlayer(ctx, ps[0], 1, ps[4]); err != ErrNotInProgress {
+		t.Errorf("ReplacePlayer(joining)=%v want=%v", err, ErrNotInProgress)
+	}
+	g, err := g.AddPlayer(ctx, ps[3], 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 3; i++ {
+		g = playNextAction(t, ctx, g, ps)
+	}
+	hand, err := g.PlayerHand(ps[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	score := g.Score().Encoded()
+
+	testCases := []struct {
+		name       string
+		organizer  Player
+		pos        int
+		substitute Player
+		wantErr    error
+	}{
+		{name: "not organizer", organizer: ps[2], pos: 1, substitute: ps[4], wantErr: ErrNotOrganizer},
+		{name: "invalid position", organizer: ps[0], pos: 4, substitute: ps[4], wantErr: ErrInvalidPosition},
+		{name: "substitute already seated", organizer: ps[0], pos: 1, substitute: ps[3], wantErr: ErrPlayerAlreadyAdded},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := g.ReplacePlayer(ctx, tc.organizer, tc.pos, tc.substitute); err != tc.wantErr {
+				t.Errorf("ReplacePlayer()=%v want=%v", err, tc.wantErr)
+			}
+		})
+	}
+
+	if g, err = g.ReplacePlayer(ctx, ps[0], 1, ps[4]); err != nil {
+		t.Fatal(err)
+	}
+
+	// The substitute takes over the seat, its hand and the score.
+	if got, want := playerIDs(g), []string{"PLAYER0", "PLAYER4", "PLAYER2", "PLAYER3"}; !cmp.Equal(got, want) {
+		t.Errorf("players=%v want=%v", got, want)
+	}
+	if _, err := g.PlayerHand(ps[1]); err != ErrNotInGame {
+		t.Errorf("PlayerHand(replaced)=%v want=%v", err, ErrNotInGame)
+	}
+	got, err := g.PlayerHand(ps[4])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Encoded() != hand.Encoded() {
+		t.Errorf("substitute hand=%s want=%s", got.Encoded(), hand.Encoded())
+	}
+	if got := g.Score().Encoded(); got != score {
+		t.Errorf("Score()=%s want=%s", got, score)
+	}
+
+	// Play carries on, and replaying the log gives the same table.
+	ps[1] = ps[4]
+	g = playNextAction(t, ctx, g, ps)
+	gi := g.(*game)
+	replayed, err := ReplayGame(ctx, gi.gameStore, gi.playerStore, g.ID(), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(legacyStorage(t, gi), legacyStorage(t, replayed.(*game)), ignoreDates); diff != "" {
+		t.Errorf("replayed game mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestOrganizerOfOlderGame(t *testing.T) {
+	g, _, playerStore := buildGame(t, legacyGame())
+
+	if !g.IsOrganizer(getPlayer(t, playerStore, "ABE")) {
+		t.Errorf("IsOrganizer(first seat)=false want=true")
+	}
+	if g.IsOrganizer(getPlayer(t, playerStore, "BOB")) {
+		t.Errorf("IsOrganizer(second seat)=true want=false")
+	}
+}
@@ -0,0 +1,323 @@
+package game
+
+import (
+	"math/rand"
+	"sort"
+	"time"
+
+	"github.com/squee1945/threespot/server/pkg/deck"
+)
+
+var (
+	ErrUnknownStrategy = newError(CodeUnknownStrategy, "Unknown bot strategy")
+	ErrNoMove          = newError(CodeNoMove, "Player has nothing to do")
+)
+
+// Strategy decides the actions of a bot player from what it can see from its seat. It is asked only when the
+// action is its to take, and must choose from view.Legal. Bots always deal when it is their turn, and never claim misdeals.
+type Strategy interface {
+	// Pass chooses a card to pass.
+	Pass(view *SeatView) (deck.Card, error)
+	// Bid chooses a bid to place.
+	Bid(view *SeatView) (Bid, error)
+	// Trump chooses the suit to call as trump.
+	Trump(view *SeatView) (deck.Suit, error)
+	// Play chooses a card to play into the current trick.
+	Play(view *SeatView) (deck.Card, error)
+}
+
+// strategies are the registered strategies, by name.
+var strategies = map[string]func() Strategy{
+	SimpleStrategy:        func() Strategy { return simpleStrategy{} },
+	RandomStrategy:        func() Strategy { return NewRandomStrategy(rand.NewSource(time.Now().UnixNano())) },
+	HeuristicEasyStrategy: func() Strategy { return NewHeuristicStrategy(EasyLevel) },
+	HeuristicHardStrategy: func() Strategy { return NewHeuristicStrategy(HardLevel) },
+	SearchStrategy:        func() Strategy { return NewSearchStrategy(DefaultSearchBudget, rand.NewSource(time.Now().UnixNano())) },
+}
+
+// Built-in strategies.
+const (
+	// SimpleStrategy passes and plays its lowest legal card, passes the bid whenever it can and calls its longest suit.
+	// It is also what a player who runs out of time does.
+	SimpleStrategy = "simple"
+	// RandomStrategy takes legal actions at random.
+	RandomStrategy = "random"
+	// HeuristicEasyStrategy and HeuristicHardStrategy bid on an estimate of their hand and play by rules of thumb; see
+	// NewHeuristicStrategy.
+	HeuristicEasyStrategy = "heuristic-easy"
+	HeuristicHardStrategy = "heuristic-hard"
+	// SearchStrategy samples the unseen cards and plays the hand out to choose its actions, within the
+	// DefaultSearchBudget; see NewSearchStrategy.
+	SearchStrategy = "search"
+)
+
+// RegisterStrategy makes a strategy available to bot players by name. It is not safe for concurrent use;
+// register strategies on start up (e.g., in init).
+func RegisterStrategy(name string, newStrategy func() Strategy) {
+	strategies[name] = newStrategy
+}
+
+// NewStrategy returns a new instance of the named strategy, or ErrUnknownStrategy if it is not registered.
+func NewStrategy(name string) (Strategy, error) {
+	newStrategy, ok := strategies[name]
+	if !ok {
+		return nil, ErrUnknownStrategy
+	}
+	return newStrategy(), nil
+}
+
+// StrategyNames returns the names of the registered strategies, in alphabetical order.
+func StrategyNames() []string {
+	var names []string
+	for name := range strategies {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// SeatView is what the player in a seat can see of the game: their own hand, and the public state of play.
+type SeatView struct {
+	// Pos is the position of the player.
+	Pos   int
+	Rules Rules
+	State GameState
+	// Legal is what the player may do now.
+	Legal LegalActions
+
+	DealerPos int
+	// Hand is the player's cards.
+	Hand []deck.Card
+	// HandCounts are the number of cards held by each player.
+	HandCounts []int
+	// Score is the score of each team, indexed by team.
+	Score []int
+	// ToWin is the score needed to win.
+	ToWin int
+
+	// PassTo is the position of the player that receives the cards this player passes; -1 if they pass none.
+	PassTo int
+	// Passed are the cards the player passed in this hand; Received are the cards passed to them, once passing is done.
+	Passed   []deck.Card
+	Received []deck.Card
+
+	// Bids are the bids placed in this hand, starting with LeadBidPos.
+	LeadBidPos int
+	Bids       []Bid
+	// WinningBid is the winning bid once bidding is done, and WinningBidPos the player that placed it; nil and -1 before.
+	WinningBid    Bid
+	WinningBidPos int
+	// Trump is the trump suit once it is called (deck.NoTrump for a no trump bid); nil before.
+	Trump deck.Suit
+
+	// Trick is the trick being played; nil before play starts. Tricks are the tricks played in this hand, oldest first.
+	Trick  Trick
+	Tricks []Trick
+	// Tally is the points taken in this hand, indexed by team.
+	Tally []int
+}
+
+// SeatView returns what the player in pos can see of the game.
+func (e *Engine) SeatView(pos int) (*SeatView, error) {
+	if pos < 0 || pos >= e.rules.Players() {
+		return nil, ErrInvalidPosition
+	}
+	hand, err := e.currentHands.Hand(pos)
+	if err != nil {
+		return nil, err
+	}
+	legal, err := e.LegalActions(pos)
+	if err != nil {
+		return nil, err
+	}
+	v := &SeatView{
+		Pos:           pos,
+		Rules:         e.rules,
+		State:         e.State(),
+		Legal:         legal,
+		DealerPos:     e.currentDealerPos,
+		Hand:          append([]deck.Card(nil), hand.Cards()...),
+		HandCounts:    e.HandCounts(),
+		Score:         append([]int(nil), e.score.CurrentScore()...),
+		ToWin:         e.score.ToWin(),
+		LeadBidPos:    e.currentBidding.LeadPos(),
+		Bids:          append([]Bid(nil), e.currentBidding.Bids()...),
+		PassTo:        -1,
+		WinningBidPos: -1,
+		Tricks:        append([]Trick(nil), e.tricks...),
+	}
+	if e.currentTally != nil {
+		v.Tally = append([]int(nil), e.currentTally.Points()...)
+	}
+	if e.currentBidding.IsDone() && !e.currentBidding.AllPassed() {
+		if v.WinningBid, v.WinningBidPos, err = e.currentBidding.WinningBidAndPos(); err != nil {
+			return nil, err
+		}
+	}
+	if e.currentTrick != nil {
+		v.Trick = e.currentTrick.clone()
+		v.Trump = e.currentTrick.Trump()
+	}
+	if e.rules.PassCard() && e.passedCards != nil {
+		for _, passer := range e.passedCards.Passers() {
+			if passer == pos {
+				v.PassTo = e.passedCards.ToPos(pos)
+			}
+		}
+		if e.passedCards.IsDone() {
+			passed, err := e.passedCards.FromPlayer(pos)
+			if err != nil {
+				return nil, err
+			}
+			received, err := e.passedCards.ReceivedBy(pos)
+			if err != nil {
+				return nil, err
+			}
+			v.Passed = append([]deck.Card(nil), passed...)
+			v.Received = append([]deck.Card(nil), received...)
+		}
+	}
+	return v, nil
+}
+
+// Move is an action chosen for a player.
+type Move struct {
+	// Action is DealAction, PassAction, BidAction, TrumpAction or PlayAction.
+	Action Action
+	// Card is the card passed or played.
+	Card deck.Card
+	// Bid is the bid placed.
+	Bid Bid
+	// Trump is the suit called.
+	Trump deck.Suit
+}
+
+// ChooseMove asks the strategy for the action of the player whose view it is. ErrNoMove is returned if the player has
+// nothing to do.
+func ChooseMove(s Strategy, view *SeatView) (Move, error) {
+	var m Move
+	var err error
+	switch {
+	case view.Legal.Deal:
+		m.Action = DealAction
+	case len(view.Legal.Pass) > 0:
+		m.Action = PassAction
+		m.Card, err = s.Pass(view)
+	case len(view.Legal.Bids) > 0:
+		m.Action = BidAction
+		m.Bid, err = s.Bid(view)
+	case len(view.Legal.Trumps) > 0:
+		m.Action = TrumpAction
+		m.Trump, err = s.Trump(view)
+	case len(view.Legal.Play) > 0:
+		m.Action = PlayAction
+		m.Card, err = s.Play(view)
+	default:
+		return m, ErrNoMove
+	}
+	return m, err
+}
+
+// ApplyMove takes the move for the player in pos.
+func (e *Engine) ApplyMove(pos int, m Move) error {
+	switch m.Action {
+	case DealAction:
+		return e.Deal(pos)
+	case PassAction:
+		return e.Pass(pos, m.Card)
+	case BidAction:
+		return e.Bid(pos, m.Bid)
+	case TrumpAction:
+		return e.Call(pos, m.Trump)
+	case PlayAction:
+		return e.Play(pos, m.Card)
+	}
+	return ErrNoMove
+}
+
+// Act takes the turn of the player in pos with the strategy.
+func (e *Engine) Act(pos int, s Strategy) error {
+	view, err := e.SeatView(pos)
+	if err != nil {
+		return err
+	}
+	m, err := ChooseMove(s, view)
+	if err != nil {
+		return err
+	}
+	return e.ApplyMove(pos, m)
+}
+
+// simpleStrategy is the SimpleStrategy.
+type simpleStrategy struct{}
+
+func (simpleStrategy) Pass(view *SeatView) (deck.Card, error) {
+	return lowestCard(view.Legal.Pass), nil
+}
+
+func (simpleStrategy) Bid(view *SeatView) (Bid, error) {
+	// Pass is listed first if it is allowed; otherwise the lowest bid is.
+	return view.Legal.Bids[0], nil
+}
+
+func (simpleStrategy) Trump(view *SeatView) (deck.Suit, error) {
+	return longestSuit(view.Hand, view.Legal.Trumps), nil
+}
+
+func (simpleStrategy) Play(view *SeatView) (deck.Card, error) {
+	return lowestCard(view.Legal.Play), nil
+}
+
+// lowestCard returns the card with the lowest num; the first of them if there are several.
+func lowestCard(cards []deck.Card) deck.Card {
+	lowest := cards[0]
+	for _, card := range cards[1:] {
+		if isNumHigher(card.Num(), lowest.Num()) {
+			lowest = card
+		}
+	}
+	return lowest
+}
+
+// longestSuit returns the suit with the most cards; the first of them if there are several.
+func longestSuit(cards []deck.Card, suits []deck.Suit) deck.Suit {
+	longest, most := suits[0], -1
+	for _, suit := range suits {
+		count := 0
+		for _, card := range cards {
+			if card.Suit() == suit {
+				count++
+			}
+		}
+		if count > most {
+			longest, most = suit, count
+		}
+	}
+	return longest
+}
+
+// randomStrategy is the RandomStrategy.
+type randomStrategy struct {
+	rnd *rand.Rand
+}
+
+// NewRandomStrategy returns a strategy that takes legal actions at random, drawn from the source.
+func NewRandomStrategy(src rand.Source) Strategy {
+	return &randomStrategy{rnd: rand.New(src)}
+}
+
+func (s *randomStrategy) Pass(view *SeatView) (deck.Card, error) {
+	return view.Legal.Pass[s.rnd.Intn(len(view.Legal.Pass))], nil
+}
+
+func (s *randomStrategy) Bid(view *SeatView) (Bid, error) {
+	return view.Legal.Bids[s.rnd.Intn(len(view.Legal.Bids))], nil
+}
+
+func (s *randomStrategy) Trump(view *SeatView) (deck.Suit, error) {
+	return view.Legal.Trumps[s.rnd.Intn(len(view.Legal.Trumps))], nil
+}
+
+func (s *randomStrategy) Play(view *SeatView) (deck.Card, error) {
+	return view.Legal.Play[s.rnd.Intn(len(view.Legal.Play))], nil
+}
@@ -0,0 +1,328 @@
+package game
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/squee1945/threespot/server/pkg/deck"
+	"github.com/squee1945/threespot/server/pkg/storage"
+)
+
+func TestNewStrategy(t *testing.T) {
+	for _, name := range []string{SimpleStrategy, RandomStrategy, HeuristicEasyStrategy, HeuristicHardStrategy} {
+		if _, err := NewStrategy(name); err != nil {
+			t.Errorf("NewStrategy(%q)=%v", name, err)
+		}
+	}
+	if _, err := NewStrategy("nope"); err != ErrUnknownStrategy {
+		t.Errorf("NewStrategy(unknown)=%v want=%v", err, ErrUnknownStrategy)
+	}
+	if got, want := StrategyNames(), []string{HeuristicEasyStrategy, HeuristicHardStrategy, RandomStrategy, SearchStrategy, SimpleStrategy}; !cmp.Equal(got, want) {
+		t.Errorf("StrategyNames()=%v want=%v", got, want)
+	}
+}
+
+func TestEngineActPlaysGames(t *testing.T) {
+	for _, preset := range Presets() {
+		for seed := int64(1); seed <= 3; seed++ {
+			t.Run(fmt.Sprintf("%s/%d", preset.ID, seed), func(t *testing.T) {
+				rules, err := NewRulesFromPreset(preset.ID)
+				if err != nil {
+					t.Fatal(err)
+				}
+				e, err := NewEngine(rules, 0, rand.NewSource(seed))
+				if err != nil {
+					t.Fatal(err)
+				}
+				strategy := NewRandomStrategy(rand.NewSource(seed))
+
+				// Random bidding rarely wins a game; a few hands exercise every action.
+				for len(e.Score().Scores()) < 10 && e.State() != CompletedState {
+					pos, err := e.PosToPlay()
+					if err != nil {
+						t.Fatal(err)
+					}
+					if err := e.Act(pos, strategy); err != nil {
+						t.Fatalf("Act(%d) in %v: %v", pos, e.State(), err)
+					}
+				}
+			})
+		}
+	}
+}
+
+func TestSeatView(t *testing.T) {
+	e, err := NewEngine(NewRules(), 0, rand.NewSource(7))
+	if err != nil {
+		t.Fatal(err)
+	}
+	hands, err := NewHandsFromEncoded("AH|KH|QH|JH|TH|9H|8H|5H+AS|KS|QS|JS|TS|9S|8S|3S+AD|KD|QD|JD|TD|9D|8D|7D+AC|KC|QC|JC|TC|9C|8C|7C")
+	if err != nil {
+		t.Fatal(err)
+	}
+	e.QueueDeals(Dealt{DealerPos: 3, Hands: hands})
+	for len(e.Score().Scores()) == 0 {
+		playEngineAction(t, e)
+	}
+	if err := e.Deal(e.DealerPos()); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, bid := range []string{"7", "P", "P", "P"} {
+		pos, err := e.PosToPlay()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := e.Bid(pos, buildBid(t, bid)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := e.Call(0, deck.Hearts); err != nil {
+		t.Fatal(err)
+	}
+	for _, card := range []string{"AH", "8S", "7D", "7C"} {
+		pos, err := e.PosToPlay()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := e.Play(pos, buildCard(t, card)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got, err := e.SeatView(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got.Pos != 1 || got.DealerPos != 3 || got.State != PlayingState {
+		t.Errorf("SeatView()=%+v want Pos=1 DealerPos=3 State=%v", got, PlayingState)
+	}
+	if got, want := encodeCards(got.Hand), []string{"AS", "KS", "QS", "JS", "TS", "9S", "3S"}; !cmp.Equal(got, want) {
+		t.Errorf("Hand=%v want=%v", got, want)
+	}
+	if got, want := got.HandCounts, []int{7, 7, 7, 7}; !cmp.Equal(got, want) {
+		t.Errorf("HandCounts=%v want=%v", got, want)
+	}
+	if got.WinningBid.Encoded() != "7" || got.WinningBidPos != 0 || got.Trump != deck.Hearts {
+		t.Errorf("WinningBid=%v WinningBidPos=%d Trump=%v want=7, 0, H", got.WinningBid, got.WinningBidPos, got.Trump)
+	}
+	if len(got.Tricks) != 1 || got.Tricks[0].Encoded() != "0|H|AH|8S|7D|7C" {
+		t.Errorf("Tricks=%v want=[0|H|AH|8S|7D|7C]", got.Tricks)
+	}
+	if got, want := got.Tally, []int{1, 0}; !cmp.Equal(got, want) {
+		t.Errorf("Tally=%v want=%v", got, want)
+	}
+	if !got.Legal.IsEmpty() {
+		t.Errorf("Legal=%+v want empty; it is not their turn", got.Legal)
+	}
+
+	// The view is the player's own copy; changing it leaves the engine as it was.
+	score := append([]int(nil), e.Score().CurrentScore()...)
+	got.Score[0]++
+	got.Tally[0]++
+	if got, want := e.Score().CurrentScore(), score; !cmp.Equal(got, want) {
+		t.Errorf("engine CurrentScore()=%v after changing the view, want=%v", got, want)
+	}
+	if got, want := e.Tally().Points(), []int{1, 0}; !cmp.Equal(got, want) {
+		t.Errorf("engine Tally().Points()=%v after changing the view, want=%v", got, want)
+	}
+}
+
+func TestTricksStored(t *testing.T) {
+	ctx := context.Background()
+	g, ps := startUndoGame(t, ctx)
+	for len(g.(*game).tricks) == 0 {
+		g = playNextAction(t, ctx, g, ps)
+	}
+
+	gi := g.(*game)
+	stored, err := GetGame(ctx, gi.gameStore, gi.playerStore, g.ID())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var want, got []string
+	for _, trick := range gi.tricks {
+		want = append(want, trick.Encoded())
+	}
+	for _, trick := range stored.(*game).tricks {
+		got = append(got, trick.Encoded())
+	}
+	if !cmp.Equal(got, want) {
+		t.Errorf("stored tricks=%v want=%v", got, want)
+	}
+}
+
+// startBotGame returns a new game with a person in position 0 and bots playing the strategy in the other seats.
+func startBotGame(t *testing.T, ctx context.Context, strategy string) (Game, []Player) {
+	t.Helper()
+	gameStore := storage.NewFakeGameStore(nil)
+	playerStore := storage.NewFakePlayerStore()
+	ps := []Player{buildPlayer(t, playerStore, "PERSON")}
+	g, err := NewGame(ctx, gameStore, playerStore, "ABC123", ps[0], NewRules())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for pos := 1; pos < 4; pos++ {
+		bot, err := NewBotPlayer(ctx, playerStore, fmt.Sprintf("BOTPLAYER%d", pos), "Bot", strategy)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if g, err = g.AddPlayer(ctx, bot, pos); err != nil {
+			t.Fatal(err)
+		}
+		ps = append(ps, bot)
+	}
+	return g, ps
+}
+
+func TestPlayBots(t *testing.T) {
+	ctx := context.Background()
+	g, ps := startBotGame(t, ctx, RandomStrategy)
+	if got := getPlayer(t, g.(*game).playerStore, "BOTPLAYER1").Strategy(); got != RandomStrategy {
+		t.Errorf("stored Strategy()=%q want=%q", got, RandomStrategy)
+	}
+
+	for hands := 0; hands < 3 && g.State() != CompletedState; {
+		var err error
+		if g, err = g.PlayBots(ctx); err != nil {
+			t.Fatal(err)
+		}
+		pos, err := g.PosToPlay()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if g.State() == CompletedState {
+			break
+		}
+		if pos != 0 {
+			// The bots stopped after maxBotActions; carry on.
+			continue
+		}
+		if g.State() == DealingState {
+			hands++
+		}
+		g = playNextAction(t, ctx, g, ps)
+	}
+
+	gi := g.(*game)
+	replayed, err := ReplayGame(ctx, gi.gameStore, gi.playerStore, g.ID(), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(legacyStorage(t, gi), legacyStorage(t, replayed.(*game)), ignoreDates); diff != "" {
+		t.Errorf("replayed game mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestPlayBotsSearchTime(t *testing.T) {
+	ctx := context.Background()
+	g, ps := startBotGame(t, ctx, SearchStrategy)
+
+	for hands := 0; hands < 2 && g.State() != CompletedState; {
+		start := time.Now()
+		var err error
+		if g, err = g.PlayBots(ctx); err != nil {
+			t.Fatal(err)
+		}
+		if elapsed := time.Since(start); elapsed > maxBotTime+time.Second {
+			t.Fatalf("PlayBots() took %v", elapsed)
+		}
+		if g.State() == CompletedState {
+			break
+		}
+		pos, err := g.PosToPlay()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if pos != 0 {
+			continue
+		}
+		if g.State() == DealingState {
+			hands++
+		}
+		g = playNextAction(t, ctx, g, ps)
+	}
+}
+
+func TestPlayBotsApproveUndo(t *testing.T) {
+	ctx := context.Background()
+	g, ps := startBotGame(t, ctx, SimpleStrategy)
+	g, err := g.PlayBots(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for {
+		pos, err := g.PosToPlay()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if pos != 0 {
+			t.Fatalf("PosToPlay()=%d after PlayBots want=0", pos)
+		}
+		if g.State() == BiddingState {
+			break
+		}
+		if g, err = playNextAction(t, ctx, g, ps).PlayBots(ctx); err != nil {
+			t.Fatal(err)
+		}
+	}
+	before := legacyStorage(t, g.(*game))
+
+	g = playNextAction(t, ctx, g, ps)
+	if g, err = g.RequestUndo(ctx, ps[0]); err != nil {
+		t.Fatal(err)
+	}
+	if g, err = g.PlayBots(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	if g.UndoRequest() != nil {
+		t.Errorf("UndoRequest()=%+v want=nil", g.UndoRequest())
+	}
+	if diff := cmp.Diff(before, legacyStorage(t, g.(*game)), ignoreDates); diff != "" {
+		t.Errorf("game after undo mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestPlayBotsSwapSeats(t *testing.T) {
+	ctx := context.Background()
+	gameStore := storage.NewFakeGameStore(nil)
+	playerStore := storage.NewFakePlayerStore()
+	person := buildPlayer(t, playerStore, "PERSON")
+	g, err := NewGame(ctx, gameStore, playerStore, "ABC123", person, NewRules())
+	if err != nil {
+		t.Fatal(err)
+	}
+	bot, err := NewBotPlayer(ctx, playerStore, "BOTPLAYER", "Bot", SimpleStrategy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g, err = g.AddPlayer(ctx, bot, 2); err != nil {
+		t.Fatal(err)
+	}
+
+	if g, err = g.ChangeSeat(ctx, person, 2); err != nil {
+		t.Fatal(err)
+	}
+	if g, err = g.PlayBots(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	if got, want := playerIDs(g), []string{"BOTPLAYER", "", "PERSON", ""}; !cmp.Equal(got, want) {
+		t.Errorf("players=%v want=%v", got, want)
+	}
+}
+
+func TestNewBotPlayerUnknownStrategy(t *testing.T) {
+	_, err := NewBotPlayer(context.Background(), storage.NewFakePlayerStore(), "BOTPLAYER", "Bot", "nope")
+
+	if err != ErrUnknownStrategy {
+		t.Errorf("NewBotPlayer()=%v want=%v", err, ErrUnknownStrategy)
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+
+	"github.com/squee1945/threespot/server/pkg/deck"
 )
 
 // Tally keeps track of the score during an individial hand.
@@ -12,52 +14,130 @@ type Tally interface {
 	addTrick(Trick) error
 	// IsDone returns true if the tally is complete.
 	IsDone() bool
-	// Points returns the points of the tally. first int is players 0/2; second int is players 1/3.
-	Points() (int, int)
+	// Points returns the points of the tally, one entry per team (e.g., players 0/2, then players 1/3).
+	Points() []int
+	// Tricks returns the number of tricks taken, one entry per team.
+	Tricks() []int
+	// FiveOfHeartsTeam returns the team that took the 5 of hearts, or NoTeam if it has not been taken.
+	FiveOfHeartsTeam() int
+	// ThreeOfSpadesTeam returns the team that took the 3 of spades, or NoTeam if it has not been taken.
+	ThreeOfSpadesTeam() int
 	// Encoded returns the encoded tally.
 	Encoded() string
+	// clone returns a copy of the tally that can be changed independently.
+	clone() Tally
+	// stored returns the structured form of the tally, for storage.
+	stored() storedTally
 }
 
 type tally struct {
-	points02   int
-	points13   int
+	points     []int // Indexed by team.
+	tricks     []int // Indexed by team.
 	trickCount int
+	fiveTeam   int // The team that took the 5 of hearts.
+	threeTeam  int // The team that took the 3 of spades.
 }
 
 var _ Tally = (*tally)(nil) // Ensure interface is implemented.
 
 // NewTallyFromEncoded builds a tally from the Encoded() form.
 func NewTallyFromEncoded(encoded string) (Tally, error) {
-	// "cardCount|points02|points13"
+	// "cardCount|points02|points13|tricks02|tricks13"
+	// With more than two teams, there are points for each team followed by tricks for each team:
+	// "cardCount|points0|points1|points2|tricks0|tricks1|tricks2"
+	// Older tallies were encoded without the trick counts ("cardCount|points02|points13").
+	// Once the 5 of hearts or 3 of spades has been taken, the teams that took them follow a semicolon:
+	// "cardCount|points02|points13|tricks02|tricks13;fiveTeam|threeTeam"
 	if encoded == "" {
-		encoded = "0|0|0"
+		encoded = "0|0|0|0|0"
+	}
+	encodedSpecials := ""
+	if i := strings.Index(encoded, ";"); i >= 0 {
+		encoded, encodedSpecials = encoded[:i], encoded[i+1:]
 	}
 	parts := strings.Split(encoded, "|")
-	if len(parts) != 3 {
-		return nil, fmt.Errorf("encoded %q does not contain 3 parts", encoded)
+	if len(parts) != 3 && (len(parts) < 5 || len(parts)%2 != 1) {
+		return nil, fmt.Errorf("encoded %q does not contain 3 parts, or points and tricks for each team", encoded)
 	}
 	count, err := strconv.Atoi(parts[0])
 	if err != nil {
 		return nil, fmt.Errorf("count %q is not an int", parts[0])
 	}
-	points02, err := strconv.Atoi(parts[1])
-	if err != nil {
-		return nil, fmt.Errorf("points02 %q is not an int", parts[1])
+	teams := (len(parts) - 1) / 2
+	if len(parts) == 3 {
+		teams = 2
 	}
-	points13, err := strconv.Atoi(parts[2])
-	if err != nil {
-		return nil, fmt.Errorf("points13 %q is not an int", parts[2])
+	t := newTallyForTeams(teams).(*tally)
+	t.trickCount = count
+	for team := 0; team < teams; team++ {
+		t.points[team], err = strconv.Atoi(parts[1+team])
+		if err != nil {
+			return nil, fmt.Errorf("points for team %d %q is not an int", team, parts[1+team])
+		}
+		if len(parts) == 3 {
+			continue
+		}
+		t.tricks[team], err = strconv.Atoi(parts[1+teams+team])
+		if err != nil {
+			return nil, fmt.Errorf("tricks for team %d %q is not an int", team, parts[1+teams+team])
+		}
+	}
+	if encodedSpecials != "" {
+		specials := strings.Split(encodedSpecials, "|")
+		if len(specials) != 2 {
+			return nil, fmt.Errorf("special cards %q must have two parts", encodedSpecials)
+		}
+		for i, dest := range []*int{&t.fiveTeam, &t.threeTeam} {
+			team, err := strconv.Atoi(specials[i])
+			if err != nil {
+				return nil, fmt.Errorf("special card team %q is not an int", specials[i])
+			}
+			if team < NoTeam || team >= teams {
+				return nil, fmt.Errorf("special card team %d must be on the interval [%d,%d]", team, NoTeam, teams-1)
+			}
+			*dest = team
+		}
+	}
+	return t, nil
+}
+
+// storedTally is the structured form of a tally, kept in storage.
+type storedTally struct {
+	TrickCount int
+	Points     []int // Indexed by team.
+	Tricks     []int // Indexed by team.
+	FiveTeam   int
+	ThreeTeam  int
+}
+
+// newTallyFromStored builds a tally from the stored() form.
+func newTallyFromStored(st storedTally) (Tally, error) {
+	teams := len(st.Points)
+	if teams < 2 || len(st.Tricks) != teams {
+		return nil, fmt.Errorf("tally must have points and tricks for each team (at least two), got %v and %v", st.Points, st.Tricks)
+	}
+	for _, team := range []int{st.FiveTeam, st.ThreeTeam} {
+		if team < NoTeam || team >= teams {
+			return nil, fmt.Errorf("special card team %d must be on the interval [%d,%d]", team, NoTeam, teams-1)
+		}
 	}
 	return &tally{
-		points02:   points02,
-		points13:   points13,
-		trickCount: count,
+		points:     append([]int(nil), st.Points...),
+		tricks:     append([]int(nil), st.Tricks...),
+		trickCount: st.TrickCount,
+		fiveTeam:   st.FiveTeam,
+		threeTeam:  st.ThreeTeam,
 	}, nil
 }
 
-// NewTally builds an empty tally.
+// NewTally builds an empty tally for two teams.
 func NewTally() Tally {
-	return &tally{}
+	return newTallyForTeams(2)
+}
+
+// newTallyForTeams builds an empty tally for the given number of teams.
+func newTallyForTeams(teams int) Tally {
+	return &tally{points: make([]int, teams), tricks: make([]int, teams), fiveTeam: NoTeam, threeTeam: NoTeam}
 }
 
 func (t *tally) addTrick(trick Trick) error {
@@ -73,32 +153,72 @@ func (t *tally) addTrick(trick Trick) error {
 		return err
 	}
 
+	team := teamOfForTeams(winningPos, len(t.points))
+
 	score := 1
 	if trick.ContainsThreeOfSpades() {
 		score -= 3
+		t.threeTeam = team
 	}
 	if trick.ContainsFiveOfHearts() {
 		score += 5
+		t.fiveTeam = team
 	}
 
-	switch winningPos {
-	case 0, 2:
-		t.points02 += score
-	case 1, 3:
-		t.points13 += score
-	}
+	t.points[team] += score
+	t.tricks[team]++
 	t.trickCount++
 	return nil
 }
 
+func (t *tally) clone() Tally {
+	c := *t
+	c.points = append([]int(nil), t.points...)
+	c.tricks = append([]int(nil), t.tricks...)
+	return &c
+}
+
 func (t *tally) IsDone() bool {
-	return t.trickCount == 8
+	return t.trickCount == deck.CardsPerHand
+}
+
+func (t *tally) Points() []int {
+	return t.points
+}
+
+func (t *tally) Tricks() []int {
+	return t.tricks
+}
+
+func (t *tally) FiveOfHeartsTeam() int {
+	return t.fiveTeam
+}
+
+func (t *tally) ThreeOfSpadesTeam() int {
+	return t.threeTeam
 }
 
-func (t *tally) Points() (int, int) {
-	return t.points02, t.points13
+func (t *tally) stored() storedTally {
+	return storedTally{
+		TrickCount: t.trickCount,
+		Points:     append([]int(nil), t.points...),
+		Tricks:     append([]int(nil), t.tricks...),
+		FiveTeam:   t.fiveTeam,
+		ThreeTeam:  t.threeTeam,
+	}
 }
 
 func (t *tally) Encoded() string {
-	return fmt.Sprintf("%d|%d|%d", t.trickCount, t.points02, t.points13)
+	parts := []string{strconv.Itoa(t.trickCount)}
+	for _, p := range t.points {
+		parts = append(parts, strconv.Itoa(p))
+	}
+	for _, tr := range t.tricks {
+		parts = append(parts, strconv.Itoa(tr))
+	}
+	res := strings.Join(parts, "|")
+	if t.fiveTeam != NoTeam || t.threeTeam != NoTeam {
+		res += fmt.Sprintf(";%d|%d", t.fiveTeam, t.threeTeam)
+	}
+	return res
 }
@@ -3,11 +3,14 @@ package game
 import (
 	"fmt"
 	"testing"
+
+	"github.com/google/go-cmp/cmp"
 )
 
 func TestNewTally(t *testing.T) {
 	tally := NewTally()
-	got02, got13 := tally.Points()
+	points := tally.Points()
+	got02, got13 := points[0], points[1]
 	if got02 != 0 {
 		t.Errorf("points02 is not 0")
 	}
@@ -25,6 +28,8 @@ func TestNewTallyFromEncoded(t *testing.T) {
 		encoded          string
 		wantPoints02     int
 		wantPoints13     int
+		wantTricks02     int
+		wantTricks13     int
 		wantIsDone       bool
 		wantErr          bool
 		encodingOverride string
@@ -34,7 +39,7 @@ func TestNewTallyFromEncoded(t *testing.T) {
 			wantPoints02:     0,
 			wantPoints13:     0,
 			wantIsDone:       false,
-			encodingOverride: "0|0|0",
+			encodingOverride: "0|0|0|0|0",
 		},
 		{
 			name:    "too short",
@@ -46,6 +51,11 @@ func TestNewTallyFromEncoded(t *testing.T) {
 			encoded: "0|1|2|3",
 			wantErr: true,
 		},
+		{
+			name:    "way too long",
+			encoded: "0|1|2|3|4|5",
+			wantErr: true,
+		},
 		{
 			name:    "bad count",
 			encoded: "A|2|3",
@@ -61,27 +71,67 @@ func TestNewTallyFromEncoded(t *testing.T) {
 			encoded: "1|2|A",
 			wantErr: true,
 		},
+		{
+			name:    "bad tricks02",
+			encoded: "1|2|3|A|0",
+			wantErr: true,
+		},
+		{
+			name:    "bad tricks13",
+			encoded: "1|2|3|0|A",
+			wantErr: true,
+		},
 		{
 			name:         "valid",
-			encoded:      "1|2|3",
+			encoded:      "1|2|3|0|1",
 			wantPoints02: 2,
 			wantPoints13: 3,
+			wantTricks13: 1,
 			wantIsDone:   false,
 		},
 		{
 			name:         "valid, tally is done",
-			encoded:      "8|2|3",
+			encoded:      "8|2|3|5|3",
 			wantPoints02: 2,
 			wantPoints13: 3,
+			wantTricks02: 5,
+			wantTricks13: 3,
 			wantIsDone:   true,
 		},
 		{
 			name:         "valid can encode negative",
-			encoded:      "1|-2|0",
+			encoded:      "1|-2|0|1|0",
 			wantPoints02: -2,
 			wantPoints13: 0,
+			wantTricks02: 1,
 			wantIsDone:   false,
 		},
+		{
+			name:         "valid with special cards",
+			encoded:      "2|6|-2|1|1;0|1",
+			wantPoints02: 6,
+			wantPoints13: -2,
+			wantTricks02: 1,
+			wantTricks13: 1,
+		},
+		{
+			name:    "special cards missing a team",
+			encoded: "2|6|-2|1|1;0",
+			wantErr: true,
+		},
+		{
+			name:    "special card team out of range",
+			encoded: "2|6|-2|1|1;0|2",
+			wantErr: true,
+		},
+		{
+			name:             "legacy encoding without tricks",
+			encoded:          "1|2|3",
+			wantPoints02:     2,
+			wantPoints13:     3,
+			wantIsDone:       false,
+			encodingOverride: "1|2|3|0|0",
+		},
 	}
 
 	for _, tc := range testCases {
@@ -98,13 +148,22 @@ func TestNewTallyFromEncoded(t *testing.T) {
 				return
 			}
 
-			got02, got13 := tally.Points()
+			points := tally.Points()
+			got02, got13 := points[0], points[1]
 			if got, want := got02, tc.wantPoints02; got != want {
 				t.Errorf("incorrect points02 got=%d want=%d", got, want)
 			}
 			if got, want := got13, tc.wantPoints13; got != want {
 				t.Errorf("incorrect points13 got=%d want=%d", got, want)
 			}
+			tricks := tally.Tricks()
+			gotTricks02, gotTricks13 := tricks[0], tricks[1]
+			if got, want := gotTricks02, tc.wantTricks02; got != want {
+				t.Errorf("incorrect tricks02 got=%d want=%d", got, want)
+			}
+			if got, want := gotTricks13, tc.wantTricks13; got != want {
+				t.Errorf("incorrect tricks13 got=%d want=%d", got, want)
+			}
 			if got, want := tally.IsDone(), tc.wantIsDone; got != want {
 				t.Errorf("IsDone()=%t want=%t", got, want)
 			}
@@ -126,6 +185,8 @@ func TestTallyAddTrick(t *testing.T) {
 		isDone   bool
 		points02 int
 		points13 int
+		tricks02 int
+		tricks13 int
 	}
 	testCases := []struct {
 		name    string
@@ -138,49 +199,49 @@ func TestTallyAddTrick(t *testing.T) {
 			name:  "02 wins, no specials",
 			tally: buildTally(t, 0, 0, 0),
 			trick: buildTrick(t, "N", 0, "AH", "8S", "7D", "7C"),
-			want:  want{false, 1, 0},
+			want:  want{false, 1, 0, 1, 0},
 		},
 		{
 			name:  "13 wins, no specials",
 			tally: buildTally(t, 0, 0, 0),
 			trick: buildTrick(t, "N", 0, "8H", "AH", "7D", "7C"),
-			want:  want{false, 0, 1},
+			want:  want{false, 0, 1, 0, 1},
 		},
 		{
 			name:  "02 wins, with 5",
 			tally: buildTally(t, 0, 0, 0),
 			trick: buildTrick(t, "N", 0, "5H", "8S", "7D", "7C"),
-			want:  want{false, 6, 0},
+			want:  want{false, 6, 0, 1, 0},
 		},
 		{
 			name:  "02 wins, with 3",
 			tally: buildTally(t, 0, 0, 0),
 			trick: buildTrick(t, "N", 0, "3S", "8H", "7D", "7C"),
-			want:  want{false, -2, 0},
+			want:  want{false, -2, 0, 1, 0},
 		},
 		{
 			name:  "02 wins, with 5 and 3",
 			tally: buildTally(t, 0, 0, 0),
 			trick: buildTrick(t, "N", 0, "3S", "5H", "7D", "7C"),
-			want:  want{false, 3, 0},
+			want:  want{false, 3, 0, 1, 0},
 		},
 		{
 			name:  "02 tally incremented",
 			tally: buildTally(t, 1, 2, 0),
 			trick: buildTrick(t, "N", 0, "AH", "8S", "7D", "7C"),
-			want:  want{false, 3, 0},
+			want:  want{false, 3, 0, 1, 0},
 		},
 		{
 			name:  "13 tally incremented",
 			tally: buildTally(t, 2, 2, 5),
 			trick: buildTrick(t, "N", 0, "8H", "AH", "7D", "7C"),
-			want:  want{false, 2, 6},
+			want:  want{false, 2, 6, 0, 1},
 		},
 		{
 			name:  "02 wins final trick",
 			tally: buildTally(t, 7, 0, 9),
 			trick: buildTrick(t, "N", 0, "AH", "8S", "7D", "7C"),
-			want:  want{true, 1, 9},
+			want:  want{true, 1, 9, 1, 0},
 		},
 		{
 			name:    "incomplete trick returns error",
@@ -210,13 +271,22 @@ func TestTallyAddTrick(t *testing.T) {
 				return
 			}
 
-			got02, got13 := tc.tally.Points()
+			points := tc.tally.Points()
+			got02, got13 := points[0], points[1]
 			if got, want := got02, tc.want.points02; got != want {
 				t.Errorf("incorrect points02 got=%d want=%d", got, want)
 			}
 			if got, want := got13, tc.want.points13; got != want {
 				t.Errorf("incorrect points13 got=%d want=%d", got, want)
 			}
+			tricks := tc.tally.Tricks()
+			gotTricks02, gotTricks13 := tricks[0], tricks[1]
+			if got, want := gotTricks02, tc.want.tricks02; got != want {
+				t.Errorf("incorrect tricks02 got=%d want=%d", got, want)
+			}
+			if got, want := gotTricks13, tc.want.tricks13; got != want {
+				t.Errorf("incorrect tricks13 got=%d want=%d", got, want)
+			}
 			if got, want := tc.tally.IsDone(), tc.want.isDone; got != want {
 				t.Errorf("IsDone()=%t want=%t", got, want)
 			}
@@ -224,6 +294,58 @@ func TestTallyAddTrick(t *testing.T) {
 	}
 }
 
+func TestTallyThreeTeams(t *testing.T) {
+	tally, err := NewTallyFromEncoded("2|1|-2|0|1|1|0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	trick, err := NewTrickFromEncoded("0/6|N|8H|AH|7D|7C|5H|9S")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Player 1 wins; partners 1 and 4 are team 1.
+	if err := tally.addTrick(trick); err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([]int{1, 4, 0}, tally.Points()); diff != "" {
+		t.Errorf("tally.Points() mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff([]int{1, 2, 0}, tally.Tricks()); diff != "" {
+		t.Errorf("tally.Tricks() mismatch (-want +got):\n%s", diff)
+	}
+	if got, want := tally.Encoded(), "3|1|4|0|1|2|0;1|-1"; got != want {
+		t.Errorf("tally.Encoded()=%q want=%q", got, want)
+	}
+}
+
+func TestTallySpecialCards(t *testing.T) {
+	tally := NewTally()
+	if got, want := tally.FiveOfHeartsTeam(), NoTeam; got != want {
+		t.Errorf("FiveOfHeartsTeam()=%d want=%d", got, want)
+	}
+	if got, want := tally.ThreeOfSpadesTeam(), NoTeam; got != want {
+		t.Errorf("ThreeOfSpadesTeam()=%d want=%d", got, want)
+	}
+
+	// Player 1 takes the 5 of hearts.
+	if err := tally.addTrick(buildTrick(t, "N", 0, "8H", "AH", "5H", "7C")); err != nil {
+		t.Fatal(err)
+	}
+	// Player 2 takes the 3 of spades.
+	if err := tally.addTrick(buildTrick(t, "N", 0, "8S", "3S", "AS", "7C")); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := tally.FiveOfHeartsTeam(), 1; got != want {
+		t.Errorf("FiveOfHeartsTeam()=%d want=%d", got, want)
+	}
+	if got, want := tally.ThreeOfSpadesTeam(), 0; got != want {
+		t.Errorf("ThreeOfSpadesTeam()=%d want=%d", got, want)
+	}
+	if got, want := tally.Encoded(), "2|-2|6|1|1;1|0"; got != want {
+		t.Errorf("tally.Encoded()=%q want=%q", got, want)
+	}
+}
+
 func TestTallyIsDone(t *testing.T) {
 	testCases := []struct {
 		count int
@@ -246,8 +368,17 @@ func TestTallyIsDone(t *testing.T) {
 func buildTally(t *testing.T, count, points02, points13 int) Tally {
 	t.Helper()
 	return &tally{
-		points02:   points02,
-		points13:   points13,
+		points:     []int{points02, points13},
+		tricks:     []int{0, 0},
+		trickCount: count,
+	}
+}
+
+func buildTallyWithTricks(t *testing.T, count, points02, points13, tricks02, tricks13 int) Tally {
+	t.Helper()
+	return &tally{
+		points:     []int{points02, points13},
+		tricks:     []int{tricks02, tricks13},
 		trickCount: count,
 	}
 }
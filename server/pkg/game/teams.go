@@ -0,0 +1,39 @@
+package game
+
+// NoTeam means no team (e.g., no team has taken a card yet).
+const NoTeam int = -1
+
+// NumTeams returns the number of teams for the number of players.
+// An odd number of players plays cutthroat, each player on their own team; otherwise partners sit across the table.
+func NumTeams(players int) int {
+	if players%2 == 1 {
+		return players
+	}
+	return players / 2
+}
+
+// TeamOf returns the team (0..NumTeams-1) of the player in playerPos.
+func TeamOf(playerPos, players int) int {
+	return teamOfForTeams(playerPos, NumTeams(players))
+}
+
+// teamOfForTeams returns the team of the player in playerPos when there are the given number of teams.
+// Partners sit across the table, so the team is the position modulo the number of teams.
+func teamOfForTeams(playerPos, teams int) int {
+	return playerPos % teams
+}
+
+// TeamPositions returns the positions of the players on each team, indexed by team.
+func TeamPositions(players int) [][]int {
+	teams := make([][]int, NumTeams(players))
+	for pos := 0; pos < players; pos++ {
+		team := TeamOf(pos, players)
+		teams[team] = append(teams[team], pos)
+	}
+	return teams
+}
+
+// hasPartners returns true if each player has a partner sitting across the table.
+func hasPartners(players int) bool {
+	return NumTeams(players) < players
+}
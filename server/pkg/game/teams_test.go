@@ -0,0 +1,37 @@
+package game
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestTeamPositions(t *testing.T) {
+	testCases := []struct {
+		players int
+		want    [][]int
+	}{
+		{3, [][]int{{0}, {1}, {2}}},
+		{4, [][]int{{0, 2}, {1, 3}}},
+		{5, [][]int{{0}, {1}, {2}, {3}, {4}}},
+		{6, [][]int{{0, 3}, {1, 4}, {2, 5}}},
+	}
+	for _, tc := range testCases {
+		t.Run(fmt.Sprintf("%d players", tc.players), func(t *testing.T) {
+			if diff := cmp.Diff(tc.want, TeamPositions(tc.players)); diff != "" {
+				t.Errorf("TeamPositions() mismatch (-want +got):\n%s", diff)
+			}
+			if got, want := NumTeams(tc.players), len(tc.want); got != want {
+				t.Errorf("NumTeams()=%d want=%d", got, want)
+			}
+			for team, positions := range tc.want {
+				for _, pos := range positions {
+					if got, want := TeamOf(pos, tc.players), team; got != want {
+						t.Errorf("TeamOf(%d)=%d want=%d", pos, got, want)
+					}
+				}
+			}
+		})
+	}
+}
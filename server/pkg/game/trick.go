@@ -9,9 +9,9 @@ import (
 	"github.com/squee1945/threespot/server/pkg/deck"
 )
 
-// Trick is an in-progress trick of up to 4 cards, one card from each player.
+// Trick is an in-progress trick, one card from each player.
 type Trick interface {
-	// IsDone returns true if the trick is complete (4 cards played).
+	// IsDone returns true if the trick is complete (one card played by each player).
 	IsDone() bool
 
 	// playCard adds a card to the trick for the player in playerPos position.
@@ -41,6 +41,12 @@ type Trick interface {
 	// Encoded returns the entire trick encoded into a single string.
 	Encoded() string
 
+	// clone returns a copy of the trick that can be changed independently.
+	clone() Trick
+
+	// stored returns the structured form of the trick, for storage.
+	stored() storedTrick
+
 	// ContainsThreeOfSpades returns true if the 3 of Spades is in the trick.
 	ContainsThreeOfSpades() bool
 
@@ -49,7 +55,7 @@ type Trick interface {
 }
 
 const (
-	orderedCards = "35789TJQKA"
+	orderedCards = "3456789TJQKA"
 )
 
 var (
@@ -65,10 +71,14 @@ var (
 type trick struct {
 	// trump is the trump for the hand this trick belongs to.
 	trump deck.Suit
-	// leadPos is the position (0..3) of the leadoff player.
+	// leadPos is the position (0..players-1) of the leadoff player.
 	leadPos int
+	// players is the number of players, and the number of cards in a complete trick.
+	players int
 	// cards are the cards played. cards[0] is the card played by the player in leadPos.
 	cards []deck.Card
+	// buf holds the cards played, so a trick is a single allocation.
+	buf [deck.MaxPlayers]deck.Card
 }
 
 var _ Trick = (*trick)(nil) // Ensure interface is implemented.
@@ -76,6 +86,7 @@ var _ Trick = (*trick)(nil) // Ensure interface is implemented.
 // NewTrickFromEncoded returns a trick from the Encoded() form.
 func NewTrickFromEncoded(encoded string) (Trick, error) {
 	// "{leadPos}|{trump}|{card0}|{card1}|{card2}|{card3}"
+	// If there are not four players, the leadPos is followed by the number of players: "{leadPos}/{players}|{trump}|..."
 	if encoded == "" {
 		return nil, fmt.Errorf("empty string is not valid")
 	}
@@ -84,20 +95,31 @@ func NewTrickFromEncoded(encoded string) (Trick, error) {
 	if len(parts) < 2 {
 		return nil, fmt.Errorf("encoded string %q must have at least two parts", encoded)
 	}
-	leadPos, err := strconv.Atoi(parts[0])
+	header := strings.Split(parts[0], "/")
+	if len(header) > 2 {
+		return nil, fmt.Errorf("encoded string part[0] %q must have one or two parts", parts[0])
+	}
+	leadPos, err := strconv.Atoi(header[0])
 	if err != nil {
 		return nil, fmt.Errorf("encoded string part[0] %q not int: %v", parts[0], err)
 	}
+	players := defaultPlayers
+	if len(header) == 2 {
+		players, err = strconv.Atoi(header[1])
+		if err != nil {
+			return nil, fmt.Errorf("encoded string part[0] %q not int: %v", parts[0], err)
+		}
+	}
 	trump, err := deck.NewSuitFromEncoded(strings.ToUpper(parts[1]))
 	if err != nil {
 		return nil, fmt.Errorf("encoded string part[1] %q not suit: %v", parts[1], err)
 	}
-	tt, err := NewTrick(trump, leadPos)
+	tt, err := newTrickForPlayers(trump, leadPos, players)
 	if err != nil {
 		return nil, err
 	}
 	t := tt.(*trick)
-	added := make(map[string]bool, 4)
+	added := make(map[string]bool, players)
 	for i := 2; i < len(parts); i++ {
 		encodedCard := strings.ToUpper(parts[i])
 		if _, present := added[encodedCard]; present {
@@ -110,31 +132,92 @@ func NewTrickFromEncoded(encoded string) (Trick, error) {
 		}
 		t.cards = append(t.cards, card)
 	}
-	if len(t.cards) > 4 {
+	if len(t.cards) > players {
 		return nil, fmt.Errorf("too many cards in %q", encoded)
 	}
 	return t, nil
 }
 
-// NewTrick creates a new trick with no cards played.
+// NewTrick creates a new trick for four players with no cards played.
 func NewTrick(trump deck.Suit, leadPos int) (Trick, error) {
-	if leadPos < 0 || leadPos > 3 {
-		return nil, fmt.Errorf("leadPos %d not in range [0,3]", leadPos)
+	return newTrickForPlayers(trump, leadPos, defaultPlayers)
+}
+
+// newTrickForPlayers creates a new trick for the given number of players with no cards played.
+func newTrickForPlayers(trump deck.Suit, leadPos, players int) (Trick, error) {
+	t := &trick{}
+	if err := t.reset(trump, leadPos, players); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// reset sets the trick to a new trick for the given number of players with no cards played.
+func (t *trick) reset(trump deck.Suit, leadPos, players int) error {
+	if players < deck.MinPlayers || players > deck.MaxPlayers {
+		return fmt.Errorf("players %d not in range [%d,%d]", players, deck.MinPlayers, deck.MaxPlayers)
 	}
-	return &trick{
+	if leadPos < 0 || leadPos >= players {
+		return fmt.Errorf("leadPos %d not in range [0,%d]", leadPos, players-1)
+	}
+	*t = trick{
 		trump:   trump,
 		leadPos: leadPos,
-	}, nil
+		players: players,
+	}
+	return nil
 }
 
 func (t *trick) Encoded() string {
-	s := fmt.Sprintf("%d|%s", t.leadPos, t.trump.Encoded())
+	header := strconv.Itoa(t.leadPos)
+	if t.players != defaultPlayers {
+		header = fmt.Sprintf("%d/%d", t.leadPos, t.players)
+	}
+	s := fmt.Sprintf("%s|%s", header, t.trump.Encoded())
 	for _, c := range t.cards {
 		s += fmt.Sprintf("|%s", c.Encoded())
 	}
 	return s
 }
 
+// storedTrick is the structured form of a trick, kept in storage.
+type storedTrick struct {
+	LeadPos int
+	Players int
+	Trump   string
+	Cards   []string // The cards played, starting with the card of the player in LeadPos.
+}
+
+// newTrickFromStored builds a trick from the stored() form.
+func newTrickFromStored(st storedTrick) (Trick, error) {
+	trump, err := deck.NewSuitFromEncoded(st.Trump)
+	if err != nil {
+		return nil, fmt.Errorf("trick trump %q not suit: %v", st.Trump, err)
+	}
+	tt, err := newTrickForPlayers(trump, st.LeadPos, st.Players)
+	if err != nil {
+		return nil, err
+	}
+	if len(st.Cards) > st.Players {
+		return nil, fmt.Errorf("too many cards in trick %v", st.Cards)
+	}
+	cards, err := decodeCards(st.Cards)
+	if err != nil {
+		return nil, err
+	}
+	t := tt.(*trick)
+	for i, card := range cards {
+		if err := t.playCard(t.toPos(i), card); err != nil {
+			return nil, err
+		}
+	}
+	return t, nil
+}
+
+func (t *trick) stored() storedTrick {
+	return storedTrick{LeadPos: t.leadPos, Players: t.players, Trump: t.trump.Encoded(), Cards: encodeCards(t.cards)}
+}
+
 func (t *trick) playCard(playerPos int, card deck.Card) error {
 	ord := t.toOrd(playerPos)
 	if len(t.cards) != ord {
@@ -145,10 +228,21 @@ func (t *trick) playCard(playerPos int, card deck.Card) error {
 			return fmt.Errorf("card %s already in trick", c.Encoded())
 		}
 	}
+	if t.cards == nil {
+		t.cards = t.buf[:0:t.players]
+	}
 	t.cards = append(t.cards, card)
 	return nil
 }
 
+func (t *trick) clone() Trick {
+	c := *t
+	if t.cards != nil {
+		c.cards = append(c.buf[:0:t.players], t.cards...)
+	}
+	return &c
+}
+
 func (t *trick) Trump() deck.Suit {
 	return t.trump
 }
@@ -172,11 +266,11 @@ func (t *trick) CurrentTurnPos() (int, error) {
 	if t.IsDone() {
 		return -1, fmt.Errorf("trick is complete")
 	}
-	return (t.leadPos + len(t.cards)) % 4, nil
+	return (t.leadPos + len(t.cards)) % t.players, nil
 }
 
 func (t *trick) IsDone() bool {
-	return len(t.cards) == 4
+	return len(t.cards) == t.players
 }
 
 func (t *trick) Cards() []deck.Card {
@@ -187,18 +281,20 @@ func (t *trick) WinningPos() (int, error) {
 	if !t.IsDone() {
 		return 0, fmt.Errorf("trick is incomplete")
 	}
-	highOrd := 0
-	for i := 1; i < 4; i++ {
-		if t.isHigher(t.cards[0].Suit(), t.cards[highOrd], t.cards[i]) {
-			highOrd = i
+	trump, lead := t.trump.Index(), t.cards[0].Index()/numsPerSuit
+	highOrd, high := 0, -1
+	for i, card := range t.cards {
+		if strength := cardStrength(card, lead, trump); strength > high {
+			highOrd, high = i, strength
 		}
 	}
 	return t.toPos(highOrd), nil
 }
 
 func (t *trick) contains(card deck.Card) bool {
+	index := card.Index()
 	for _, c := range t.cards {
-		if c.Encoded() == card.Encoded() {
+		if c.Index() == index {
 			return true
 		}
 	}
@@ -213,53 +309,42 @@ func (t *trick) ContainsFiveOfHearts() bool {
 	return t.contains(deck.FiveOfHearts)
 }
 
-// toOrd returns the player order for this trick (0..3), computed from the leadPos.
+// toOrd returns the player order for this trick (0..players-1), computed from the leadPos.
 func (t *trick) toOrd(playerPos int) int {
-	return (playerPos + 4 - t.leadPos) % 4
+	return (playerPos + t.players - t.leadPos) % t.players
 }
 
 func (t *trick) toPos(playerOrd int) int {
-	return (t.leadPos + playerOrd) % 4
+	return (t.leadPos + playerOrd) % t.players
 }
 
 // isHigher returns true if b is higher than a, considering the lead suit and the trump.
 func (t *trick) isHigher(lead deck.Suit, a, b deck.Card) bool {
-	// First, consider the trump suit.
-	if t.trump != deck.NoTrump {
-		// If a and b are trump, return true if b is higher.
-		if a.Suit() == t.trump && b.Suit() == t.trump {
-			return isNumHigher(a.Num(), b.Num())
-		}
-		// If a is trump and b is not, return false.
-		if a.Suit() == t.trump && b.Suit() != t.trump {
-			return false
-		}
-		// If b is trump and a is not, return true.
-		if a.Suit() != t.trump && b.Suit() == t.trump {
-			return true
-		}
-	}
-
-	// Otherwise, no trumps are in play.
+	trump, leadSuit := t.trump.Index(), lead.Index()
+	return cardStrength(b, leadSuit, trump) > cardStrength(a, leadSuit, trump)
+}
 
-	// If both are suited, return true if b is higher.
-	if a.Suit() == lead && b.Suit() == lead {
-		return isNumHigher(a.Num(), b.Num())
-	}
-	// If a is suited, but b is not, return false.
-	if a.Suit() == lead && b.Suit() != lead {
-		return false
-	}
-	// If b is suited, but a is not, return true.
-	if a.Suit() != lead && b.Suit() == lead {
-		return true
+// cardStrength ranks the card in a trick with the lead suit and trump, given by deck.Suit Index: trumps rank above the
+// lead suit, by num, and the other suits cannot win the trick.
+func cardStrength(card deck.Card, lead, trump int) int {
+	index := card.Index()
+	suit, num := index/numsPerSuit, index%numsPerSuit
+	switch suit {
+	case trump:
+		return 2*numsPerSuit + num
+	case lead:
+		return numsPerSuit + num
 	}
-	// Otherwise, neither are suited, just return false.
-	return false
+	return -1
 }
 
 // isNumHigher returns true if b is a higher "num" than a.
 // NOTE: this method is only valid if cards are the same suit.
 func isNumHigher(a, b string) bool {
-	return strings.Index(orderedCards, a) < strings.Index(orderedCards, b)
+	return numRank(a) < numRank(b)
+}
+
+// numRank returns the rank of the card num, 0 for the lowest.
+func numRank(num string) int {
+	return strings.IndexByte(orderedCards, num[0])
 }
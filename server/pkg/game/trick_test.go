@@ -95,6 +95,23 @@ func TestNewTrickFromEncoded(t *testing.T) {
 			wantLeadPos: 1,
 			wantCards:   []string{"5H", "8D", "9C", "TS"},
 		},
+		{
+			name:        "valid with max cards for six players",
+			encoded:     "5/6|C|5H|8D|9C|TS|4H|6C",
+			wantTrump:   "C",
+			wantLeadPos: 5,
+			wantCards:   []string{"5H", "8D", "9C", "TS", "4H", "6C"},
+		},
+		{
+			name:    "too many cards for three players",
+			encoded: "0/3|N|7D|8D|9D|TD",
+			wantErr: true,
+		},
+		{
+			name:    "bad players",
+			encoded: "0/?|N",
+			wantErr: true,
+		},
 	}
 
 	for _, tc := range testCases {
@@ -0,0 +1,214 @@
+package game
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/squee1945/threespot/server/pkg/storage"
+)
+
+var (
+	ErrNothingToUndo       = newError(CodeNothingToUndo, "No action of yours to take back")
+	ErrUndoTooLate         = newError(CodeUndoTooLate, "Too late to take back the action")
+	ErrUndoPending         = newError(CodeUndoPending, "An undo has already been requested")
+	ErrNoUndoRequest       = newError(CodeNoUndoRequest, "No undo has been requested")
+	ErrUndoRequester       = newError(CodeUndoRequester, "Player cannot respond to their own undo request")
+	ErrAlreadyApprovedUndo = newError(CodeAlreadyApproved, "Player has already approved the undo")
+)
+
+// UndoRequest is a player's request to take back their last action, awaiting the approval of the other players.
+type UndoRequest struct {
+	// Pos is the position of the requesting player.
+	Pos int
+	// Seq is the Seq of the event to take back; the events after it are rolled back with it.
+	Seq int
+	// Approvals are the positions of the players that have approved, in the order they approved.
+	Approvals []int
+}
+
+// undoable returns true if the action can be taken back.
+func (a Action) undoable() bool {
+	return a == PassAction || a == BidAction || a == TrumpAction || a == PlayAction
+}
+
+// isUndo returns true if the action is part of an undo request, rather than a change to play.
+func (a Action) isUndo() bool {
+	return a == UndoRequestAction || a == UndoApproveAction || a == UndoRejectAction || a == UndoAction
+}
+
+func (g *game) UndoRequest() *UndoRequest {
+	return g.undoRequest
+}
+
+func (g *game) RequestUndo(ctx context.Context, player Player) (Game, error) {
+	if g.undoRequest != nil {
+		return nil, ErrUndoPending
+	}
+	pos, err := g.PlayerPos(player)
+	if err != nil {
+		return nil, err
+	}
+	events, err := GetEvents(ctx, g.gameStore, g.id)
+	if err != nil {
+		return nil, err
+	}
+	target, err := g.undoTarget(events, player)
+	if err != nil {
+		return nil, err
+	}
+
+	g.record(UndoRequestAction, player, pos, strconv.Itoa(target.Seq))
+	g.undoRequest = &UndoRequest{Pos: pos, Seq: target.Seq}
+	return g.save(ctx)
+}
+
+func (g *game) RespondToUndo(ctx context.Context, player Player, approve bool) (Game, error) {
+	if g.undoRequest == nil {
+		return nil, ErrNoUndoRequest
+	}
+	pos, err := g.PlayerPos(player)
+	if err != nil {
+		return nil, err
+	}
+	if pos == g.undoRequest.Pos {
+		return nil, ErrUndoRequester
+	}
+	for _, approved := range g.undoRequest.Approvals {
+		if approved == pos {
+			return nil, ErrAlreadyApprovedUndo
+		}
+	}
+
+	seq := strconv.Itoa(g.undoRequest.Seq)
+	if !approve {
+		g.record(UndoRejectAction, player, pos, seq)
+		g.undoRequest = nil
+		return g.save(ctx)
+	}
+
+	g.record(UndoApproveAction, player, pos, seq)
+	g.undoRequest.Approvals = append(g.undoRequest.Approvals, pos)
+	if len(g.undoRequest.Approvals) < g.rules.Players()-1 {
+		return g.save(ctx)
+	}
+
+	// Everyone approved; roll back by replaying the log without the action.
+	events, err := GetEvents(ctx, g.gameStore, g.id)
+	if err != nil {
+		return nil, err
+	}
+	undone := newEvent(UndoAction, nil, g.undoRequest.Pos, seq)
+	rolledBack, err := replay(ctx, storage.NewFakeGameStore(nil), g.playerStore, g.id, g.rules, append(events, undone))
+	if err != nil {
+		return nil, fmt.Errorf("rolling back: %v", err)
+	}
+	newG := rolledBack.(*game)
+	newG.gameStore = g.gameStore
+	newG.created = g.created
+	newG.updated = g.updated
+	newG.seeds = g.seeds
+	newG.pending = append(g.pending, undone)
+	return newG.save(ctx)
+}
+
+// undoTarget returns the event of the player's last action that can be taken back. It must be in the current trick (or
+// the hand, before the first card), and only the moves of bots and of players who ran out of time may follow it; they
+// are rolled back with it.
+func (g *game) undoTarget(events []Event, player Player) (Event, error) {
+	// The action is taken back by replaying the log without it, so the log must start with the game.
+	if len(events) == 0 || events[0].Action != CreateAction {
+		return Event{}, ErrNothingToUndo
+	}
+	undone := undoneSeqs(events)
+	inTrick := 0
+	if g.currentTrick != nil {
+		inTrick = g.currentTrick.NumPlayed()
+	}
+	for i := len(events) - 1; i > 0; i-- {
+		e := events[i]
+		if e.Action.isUndo() || e.Action == TimeoutAction || undone[e.Seq] {
+			continue
+		}
+		// Once the trick is collected or the next hand is dealt, the action stands.
+		if e.Action == DealtAction || (e.Action == PlayAction && inTrick == 0) {
+			return Event{}, ErrUndoTooLate
+		}
+		if e.Action == PlayAction {
+			inTrick--
+		}
+		timedOut := events[i-1].Action == TimeoutAction
+		if e.PlayerID == player.ID() && !timedOut {
+			if !e.Action.undoable() {
+				return Event{}, ErrNothingToUndo
+			}
+			return e, nil
+		}
+		if !timedOut && !g.isBot(e.Pos, e.PlayerID) {
+			return Event{}, ErrNothingToUndo
+		}
+	}
+	return Event{}, ErrNothingToUndo
+}
+
+// isBot returns true if the player with the ID is the bot seated at pos.
+func (g *game) isBot(pos int, id string) bool {
+	if pos < 0 || pos >= len(g.players) {
+		return false
+	}
+	p := g.players[pos]
+	return p != nil && p.ID() == id && p.Strategy() != ""
+}
+
+// undoneSeqs returns the Seqs of the events that have been taken back. An UndoAction takes back the event of its
+// payload's Seq along with every event that followed it, up to the undo.
+func undoneSeqs(events []Event) map[int]bool {
+	undone := make(map[int]bool)
+	for i, e := range events {
+		if e.Action != UndoAction {
+			continue
+		}
+		seq, err := strconv.Atoi(e.Payload)
+		if err != nil {
+			continue
+		}
+		for j := i - 1; j >= 0 && events[j].Seq >= seq; j-- {
+			if !events[j].Action.isUndo() {
+				undone[events[j].Seq] = true
+			}
+		}
+	}
+	return undone
+}
+
+// encodeUndoRequest encodes the request as "pos|seq|approval,approval,..."; nil is encoded as "".
+func encodeUndoRequest(r *UndoRequest) string {
+	if r == nil {
+		return ""
+	}
+	return strings.Join([]string{strconv.Itoa(r.Pos), strconv.Itoa(r.Seq), encodeInts(r.Approvals)}, "|")
+}
+
+// decodeUndoRequest builds a request from the encodeUndoRequest() form.
+func decodeUndoRequest(encoded string) (*UndoRequest, error) {
+	if encoded == "" {
+		return nil, nil
+	}
+	parts := strings.Split(encoded, "|")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("encoded undo request %q must have 3 parts", encoded)
+	}
+	r := &UndoRequest{}
+	var err error
+	if r.Pos, err = strconv.Atoi(parts[0]); err != nil {
+		return nil, fmt.Errorf("parsing position of undo request %q: %v", encoded, err)
+	}
+	if r.Seq, err = strconv.Atoi(parts[1]); err != nil {
+		return nil, fmt.Errorf("parsing seq of undo request %q: %v", encoded, err)
+	}
+	if r.Approvals, err = decodeInts(parts[2]); err != nil {
+		return nil, fmt.Errorf("parsing approvals of undo request %q: %v", encoded, err)
+	}
+	return r, nil
+}
@@ -0,0 +1,307 @@
+package game
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/squee1945/threespot/server/pkg/storage"
+)
+
+func TestUndo(t *testing.T) {
+	ctx := context.Background()
+	g, ps := startUndoGame(t, ctx)
+
+	// The first bidder bids, then takes it back.
+	before := legacyStorage(t, g.(*game))
+	pos, err := g.PosToPlay()
+	if err != nil {
+		t.Fatal(err)
+	}
+	g = playNextAction(t, ctx, g, ps)
+	if g, err = g.RequestUndo(ctx, ps[pos]); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := g.UndoRequest(), (&UndoRequest{Pos: pos, Seq: 6}); !cmp.Equal(got, want) {
+		t.Errorf("UndoRequest()=%+v want=%+v", got, want)
+	}
+
+	// The requester cannot approve their own request.
+	if _, err := g.RespondToUndo(ctx, ps[pos], true); err != ErrUndoRequester {
+		t.Errorf("RespondToUndo(requester)=%v want=%v", err, ErrUndoRequester)
+	}
+
+	for i := 1; i < 4; i++ {
+		other := ps[(pos+i)%4]
+		if g, err = g.RespondToUndo(ctx, other, true); err != nil {
+			t.Fatal(err)
+		}
+		if i < 3 && g.UndoRequest() == nil {
+			t.Fatalf("UndoRequest() is nil after %d approvals", i)
+		}
+	}
+	if g.UndoRequest() != nil {
+		t.Errorf("UndoRequest()=%+v want=nil", g.UndoRequest())
+	}
+
+	ignoreTimes := cmpopts.IgnoreFields(storage.Game{}, "Created", "Updated")
+	if diff := cmp.Diff(before, legacyStorage(t, g.(*game)), ignoreTimes); diff != "" {
+		t.Errorf("game after undo mismatch (-want +got):\n%s", diff)
+	}
+
+	// The undo is in the history, and replaying it gives the same game.
+	events, err := GetEvents(ctx, g.(*game).gameStore, g.ID())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := events[len(events)-1], (Event{Seq: len(events), Action: UndoAction, Pos: pos, Payload: "6"}); !cmp.Equal(got, want, cmpopts.IgnoreFields(Event{}, "Created")) {
+		t.Errorf("last event=%+v want=%+v", got, want)
+	}
+	replayed, err := ReplayGame(ctx, g.(*game).gameStore, g.(*game).playerStore, g.ID(), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(legacyStorage(t, g.(*game)), legacyStorage(t, replayed.(*game)), ignoreTimes); diff != "" {
+		t.Errorf("replayed game mismatch (-want +got):\n%s", diff)
+	}
+
+	// The same bidder can bid again.
+	if got, err := g.PosToPlay(); err != nil || got != pos {
+		t.Errorf("PosToPlay()=%d, %v want=%d", got, err, pos)
+	}
+	playNextAction(t, ctx, g, ps)
+}
+
+func TestUndoRejected(t *testing.T) {
+	ctx := context.Background()
+	g, ps := startUndoGame(t, ctx)
+
+	pos, err := g.PosToPlay()
+	if err != nil {
+		t.Fatal(err)
+	}
+	g = playNextAction(t, ctx, g, ps)
+	after := legacyStorage(t, g.(*game))
+	if g, err = g.RequestUndo(ctx, ps[pos]); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := g.RequestUndo(ctx, ps[pos]); err != ErrUndoPending {
+		t.Errorf("RequestUndo(again)=%v want=%v", err, ErrUndoPending)
+	}
+	if g, err = g.RespondToUndo(ctx, ps[(pos+1)%4], true); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := g.RespondToUndo(ctx, ps[(pos+1)%4], true); err != ErrAlreadyApprovedUndo {
+		t.Errorf("RespondToUndo(again)=%v want=%v", err, ErrAlreadyApprovedUndo)
+	}
+	if g, err = g.RespondToUndo(ctx, ps[(pos+2)%4], false); err != nil {
+		t.Fatal(err)
+	}
+	if g.UndoRequest() != nil {
+		t.Errorf("UndoRequest()=%+v want=nil", g.UndoRequest())
+	}
+	if _, err := g.RespondToUndo(ctx, ps[(pos+3)%4], true); err != ErrNoUndoRequest {
+		t.Errorf("RespondToUndo(after reject)=%v want=%v", err, ErrNoUndoRequest)
+	}
+
+	ignoreTimes := cmpopts.IgnoreFields(storage.Game{}, "Created", "Updated")
+	if diff := cmp.Diff(after, legacyStorage(t, g.(*game)), ignoreTimes); diff != "" {
+		t.Errorf("game after rejected undo mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestRequestUndoErrors(t *testing.T) {
+	ctx := context.Background()
+	g, ps := startUndoGame(t, ctx)
+
+	// Nothing has been done since the deal.
+	pos, err := g.PosToPlay()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := g.RequestUndo(ctx, ps[pos]); err != ErrUndoTooLate {
+		t.Errorf("RequestUndo(after deal)=%v want=%v", err, ErrUndoTooLate)
+	}
+
+	// Only the player that acted last can take it back.
+	g = playNextAction(t, ctx, g, ps)
+	if _, err := g.RequestUndo(ctx, ps[(pos+1)%4]); err != ErrNothingToUndo {
+		t.Errorf("RequestUndo(other player)=%v want=%v", err, ErrNothingToUndo)
+	}
+
+	// Another action withdraws a pending request.
+	if g, err = g.RequestUndo(ctx, ps[pos]); err != nil {
+		t.Fatal(err)
+	}
+	g = playNextAction(t, ctx, g, ps)
+	if g.UndoRequest() != nil {
+		t.Errorf("UndoRequest()=%+v want=nil", g.UndoRequest())
+	}
+
+	// Once the trick is collected, the last card stands.
+	for g.State() != PlayingState || g.CurrentTrick().NumPlayed() < 3 {
+		g = playNextAction(t, ctx, g, ps)
+	}
+	pos, err = g.PosToPlay()
+	if err != nil {
+		t.Fatal(err)
+	}
+	g = playNextAction(t, ctx, g, ps)
+	if _, err := g.RequestUndo(ctx, ps[pos]); err != ErrUndoTooLate {
+		t.Errorf("RequestUndo(after trick)=%v want=%v", err, ErrUndoTooLate)
+	}
+}
+
+func TestDecodeUndoRequest(t *testing.T) {
+	testCases := []struct {
+		name    string
+		encoded string
+		want    *UndoRequest
+		wantErr bool
+	}{
+		{name: "none", encoded: "", want: nil},
+		{name: "no approvals", encoded: "2|17|", want: &UndoRequest{Pos: 2, Seq: 17}},
+		{name: "approvals", encoded: "2|17|3,0", want: &UndoRequest{Pos: 2, Seq: 17, Approvals: []int{3, 0}}},
+		{name: "missing part", encoded: "2|17", wantErr: true},
+		{name: "bad seq", encoded: "2|X|", wantErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := decodeUndoRequest(tc.encoded)
+			if tc.wantErr {
+				if err == nil {
+					t.Fatal("missing expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("decodeUndoRequest() mismatch (-want +got):\n%s", diff)
+			}
+			if got, want := encodeUndoRequest(got), tc.encoded; got != want {
+				t.Errorf("encodeUndoRequest()=%q want=%q", got, want)
+			}
+		})
+	}
+}
+
+// startUndoGame creates a four player game and deals the first hand.
+func startUndoGame(t *testing.T, ctx context.Context) (Game, []Player) {
+	t.Helper()
+	gameStore := storage.NewFakeGameStore(nil)
+	playerStore := storage.NewFakePlayerStore()
+	var ps []Player
+	for i := 0; i < 4; i++ {
+		ps = append(ps, buildPlayer(t, playerStore, fmt.Sprintf("PLAYER%d", i)))
+	}
+	g, err := NewGame(ctx, gameStore, playerStore, "ABC123", ps[0], NewRules())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for pos := 1; pos < 4; pos++ {
+		if g, err = g.AddPlayer(ctx, ps[pos], pos); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return g, ps
+}
+
+func TestRequestUndoLegacyLog(t *testing.T) {
+	ctx := context.Background()
+	// The game was stored before its actions were logged, so its log does not start with the game.
+	g, _, playerStore := buildGame(t, &storage.Game{
+		PlayerIDs:      []string{"ABE", "BOB", "CAL", "DON"},
+		CurrentHands:   "AH|KH|7D+AS|KS+AC|KC+AD|KD",
+		CurrentBidding: "0",
+	})
+	abe := getPlayer(t, playerStore, "ABE")
+	g, err := g.PlaceBid(ctx, abe, buildBid(t, "7"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := g.RequestUndo(ctx, abe); err != ErrNothingToUndo {
+		t.Errorf("RequestUndo()=%v want=%v", err, ErrNothingToUndo)
+	}
+}
+
+func TestUndoWithBots(t *testing.T) {
+	ctx := context.Background()
+	gameStore := storage.NewFakeGameStore(nil)
+	playerStore := storage.NewFakePlayerStore()
+	// People sit at 0 and 2, with bots between them.
+	ps := []Player{buildPlayer(t, playerStore, "PERSON0")}
+	g, err := NewGame(ctx, gameStore, playerStore, "ABC123", ps[0], NewRules())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for pos := 1; pos < 4; pos++ {
+		var p Player
+		if pos == 2 {
+			p = buildPlayer(t, playerStore, "PERSON2")
+		} else if p, err = NewBotPlayer(ctx, playerStore, fmt.Sprintf("BOTPLAYER%d", pos), "Bot", SimpleStrategy); err != nil {
+			t.Fatal(err)
+		}
+		if g, err = g.AddPlayer(ctx, p, pos); err != nil {
+			t.Fatal(err)
+		}
+		ps = append(ps, p)
+	}
+
+	// Play until the person at 0 is to play a card that the bot after them follows before the person at 2 plays.
+	for {
+		if g, err = g.PlayBots(ctx); err != nil {
+			t.Fatal(err)
+		}
+		if g.State() == CompletedState {
+			t.Fatal("game completed without a card to take back")
+		}
+		pos, err := g.PosToPlay()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if pos == 0 && g.State() == PlayingState && g.CurrentTrick().NumPlayed() <= 1 {
+			break
+		}
+		g = playNextAction(t, ctx, g, ps)
+	}
+	before := legacyStorage(t, g.(*game))
+	g = playNextAction(t, ctx, g, ps)
+	if g, err = g.PlayBots(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := g.PosToPlay(); err != nil || got != 2 {
+		t.Fatalf("PosToPlay()=%d, %v want=2", got, err)
+	}
+
+	// The person at 0 takes back their card; the bot's card after it is rolled back with it.
+	if g, err = g.RequestUndo(ctx, ps[0]); err != nil {
+		t.Fatal(err)
+	}
+	if g, err = g.PlayBots(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if g, err = g.RespondToUndo(ctx, ps[2], true); err != nil {
+		t.Fatal(err)
+	}
+	if g.UndoRequest() != nil {
+		t.Errorf("UndoRequest()=%+v want=nil", g.UndoRequest())
+	}
+
+	ignoreTimes := cmpopts.IgnoreFields(storage.Game{}, "Created", "Updated")
+	if diff := cmp.Diff(before, legacyStorage(t, g.(*game)), ignoreTimes); diff != "" {
+		t.Errorf("game after undo mismatch (-want +got):\n%s", diff)
+	}
+	replayed, err := ReplayGame(ctx, gameStore, playerStore, g.ID(), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(legacyStorage(t, g.(*game)), legacyStorage(t, replayed.(*game)), ignoreTimes); diff != "" {
+		t.Errorf("replayed game mismatch (-want +got):\n%s", diff)
+	}
+}
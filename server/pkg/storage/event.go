@@ -0,0 +1,51 @@
+package storage
+
+import (
+	"context"
+	"time"
+
+	"google.golang.org/appengine/datastore"
+)
+
+const GameEventEntity = "KaiserGameEvent"
+
+// GameEvent is an entry in a game's append-only action log. Events are stored as children of the game.
+type GameEvent struct {
+	Seq      int       // Position in the game's log, starting at 1.
+	Action   string    `datastore:",noindex"` // The action taken (e.g., "BID").
+	PlayerID string    `datastore:",noindex"` // The acting player; empty for events generated by the game (e.g., dealt cards).
+	Pos      int       `datastore:",noindex"` // The seat of the acting player, or the dealer for dealt cards.
+	Payload  string    `datastore:",noindex"` // The encoded details of the action (e.g., the bid or card).
+	Created  time.Time `datastore:",noindex"`
+}
+
+func (s *datastoreGameStore) Events(ctx context.Context, id string) ([]*GameEvent, error) {
+	// The Seq is the key ID, and the built-in indexes serve ancestor queries in key order.
+	query := datastore.NewQuery(GameEventEntity).
+		Ancestor(gameKey(ctx, id)).
+		Order("__key__")
+
+	var events []*GameEvent
+	if _, err := query.GetAll(ctx, &events); err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+// appendEvents stores the events after the last event of the game, assigning their Seq from the Seq of the last
+// (the game's EventCount before the events). It must be called inside a transaction.
+func appendEvents(tc context.Context, k *datastore.Key, last int, events []*GameEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	var keys []*datastore.Key
+	for i, e := range events {
+		e.Seq = last + i + 1
+		keys = append(keys, datastore.NewKey(tc, GameEventEntity, "", int64(e.Seq), k))
+	}
+	if _, err := datastore.PutMulti(tc, keys, events); err != nil {
+		return err
+	}
+	return nil
+}
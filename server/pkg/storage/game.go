@@ -10,28 +10,64 @@ import (
 const GameEntity = "KaiserGame"
 
 type Game struct {
-	Key       *datastore.Key
-	PlayerIDs []string // Organizing player is 0-index; clockwise afterwards (e.g., 0 and 2 are partners).
-	Created   time.Time
-	Updated   time.Time
-	Complete  bool
-
-	Score string `datastore:",noindex"` // The running tally of the game.
-
-	CurrentDealerPos int    `datastore:",noindex"` // The position of the current dealer.
-	CurrentBidding   string `datastore:",noindex"` // The bids for the current hand; 0-index is the player clockwise from the CurrentDealerPos (one higher, wrapping at 4).
-	CurrentHands     string `datastore:",noindex"` // Cards held by each player, parallel with the PlayerIDs above.
-	CurrentTrick     string `datastore:",noindex"` // Cards played for current trick; 0-index is the lead player (i.e., the order the cards were played).
-	LastTrick        string `datastore:",noindex"` // Cards played for the previous trick.
-	CurrentTally     string `datastore:",noindex"` // The running tally for the current hand.
-
-	PassedCards string `datastore:",noindex"` // Cards passed for the current trick.
+	Key *datastore.Key
+	// PlayerIDs are the seated players, clockwise (e.g., 0 and 2 are partners in a four player game); empty for empty seats.
+	// GetCurrentGames matches on them, so players who leave or are replaced no longer find the game.
+	PlayerIDs   []string
+	OrganizerID string `datastore:",noindex"` // The player that created the game, at position 0; empty for games that predate it.
+	Created     time.Time
+	Updated     time.Time
+	Complete    bool
+
+	TurnDeadline time.Time `datastore:",noindex"` // When the player to play runs out of time; zero if there is no turn limit.
+	EventCount   int       `datastore:",noindex"` // The number of events in the game's action log, and so the Seq of the last.
+
+	// StateVersion is the version of the encoding of State. Version 0 games predate State and keep the state of play
+	// in the encoded strings below (Score, CurrentBidding, ...); they are rewritten in the current version when next saved.
+	StateVersion int    `datastore:",noindex"`
+	State        string `datastore:",noindex"` // The state of play (score, bids, hands, tricks, tally, passed cards and undo request), JSON encoded.
+
+	Score string `datastore:",noindex"` // The running tally of the game. Version 0 only.
+
+	CurrentDealerPos   int    `datastore:",noindex"` // The position of the current dealer.
+	CurrentBidding     string `datastore:",noindex"` // The bids for the current hand; 0-index is the player clockwise from the CurrentDealerPos (one higher, wrapping at 4). Version 0 only.
+	CurrentHands       string `datastore:",noindex"` // Cards held by each player, parallel with the PlayerIDs above. Version 0 only.
+	CurrentSeed        int64  `datastore:",noindex"` // The seed the current hand was shuffled with; 0 if unknown.
+	CurrentDealVersion int    `datastore:",noindex"` // The deal version the current hand was shuffled with; 0 for games saved before deal versions.
+	CurrentTrick       string `datastore:",noindex"` // Cards played for current trick; 0-index is the lead player (i.e., the order the cards were played). Version 0 only.
+	LastTrick          string `datastore:",noindex"` // Cards played for the previous trick. Version 0 only.
+	CurrentTally       string `datastore:",noindex"` // The running tally for the current hand. Version 0 only.
+
+	PassedCards string `datastore:",noindex"` // Cards passed for the current trick. Version 0 only.
+	UndoRequest string `datastore:",noindex"` // The pending request to take back an action; empty if none. Version 0 only.
 
 	Rules Rules
 }
 
 type Rules struct {
-	PassCard bool `datastore:",noindex"` // Players pass one card before bidding.
+	Preset            string `datastore:",noindex"` // The ID of the rule preset; empty means custom rules.
+	Players           int    `datastore:",noindex"` // Number of players; 0 means the default (4).
+	PassCard          bool   `datastore:",noindex"` // Players pass cards.
+	PassCount         int    `datastore:",noindex"` // Cards passed by each player; 0 means the default (1).
+	PassDirection     string `datastore:",noindex"` // Who receives the passed cards; empty means across (to partners).
+	PassTiming        string `datastore:",noindex"` // When cards are passed; empty means before bidding.
+	ToWin             int    `datastore:",noindex"` // Score needed to win; 0 means the default (52).
+	FixedToWin        bool   `datastore:",noindex"` // A successful no trump bid does not raise ToWin.
+	NoTrumpToWin      int    `datastore:",noindex"` // Score needed to win after a successful no trump bid; 0 means the default (62).
+	DefenderCap       int    `datastore:",noindex"` // The non-bidding team cannot score past this; 0 means no cap.
+	AllPass           string `datastore:",noindex"` // What happens when everyone passes; empty means the dealer is stuck with a bid.
+	MinBid            int    `datastore:",noindex"` // The lowest bid that may be placed; 0 means the default (7).
+	DealerMustOverbid bool   `datastore:",noindex"` // The dealer must bid higher than the high bid, rather than take it.
+	Misdeal           string `datastore:",noindex"` // The kind of hand a player may claim a misdeal for; empty means no claims.
+	TurnSeconds       int    `datastore:",noindex"` // Seconds a player has for each turn before a default action is taken; 0 means no limit.
+}
+
+// NumPlayers returns the number of players in the game.
+func (r Rules) NumPlayers() int {
+	if r.Players == 0 {
+		return 4
+	}
+	return r.Players
 }
 
 func (x *Game) LoadKey(k *datastore.Key) error {
@@ -47,12 +83,17 @@ func (x *Game) Save() ([]datastore.Property, error) {
 	return datastore.SaveStruct(x)
 }
 
+// GameStore stores games. Create, Set and AddPlayer append any events to the game's action log in the same transaction.
 type GameStore interface {
-	Create(ctx context.Context, id, organizingPlayerID string, rules Rules) (*Game, error)
+	Create(ctx context.Context, id, organizingPlayerID string, rules Rules, events ...*GameEvent) (*Game, error)
 	Get(ctx context.Context, id string) (*Game, error)
-	Set(ctx context.Context, id string, g *Game) error
-	AddPlayer(ctx context.Context, id, playerID string, pos int) (*Game, error)
+	// Set stores the game, if it has not changed since it was read; lastUpdated is the Updated time of the game as read.
+	// ErrConflict is returned if the stored game has been updated since.
+	Set(ctx context.Context, id string, g *Game, lastUpdated time.Time, events ...*GameEvent) error
+	AddPlayer(ctx context.Context, id, playerID string, pos int, events ...*GameEvent) (*Game, error)
 	GetCurrentGames(ctx context.Context, playerID string, count int) ([]*Game, error)
+	// Events returns the game's action log, oldest first.
+	Events(ctx context.Context, id string) ([]*GameEvent, error)
 }
 
 type datastoreGameStore struct{}
@@ -63,12 +104,12 @@ func NewDatastoreGameStore() GameStore {
 	return &datastoreGameStore{}
 }
 
-func (s *datastoreGameStore) Create(ctx context.Context, id, organizingPlayerID string, rules Rules) (*Game, error) {
+func (s *datastoreGameStore) Create(ctx context.Context, id, organizingPlayerID string, rules Rules, events ...*GameEvent) (*Game, error) {
 	k := gameKey(ctx, id)
 	gs := &Game{}
 	err := datastore.RunInTransaction(ctx, func(tc context.Context) error {
 		found := true
-		if err := datastore.Get(ctx, k, gs); err != nil {
+		if err := datastore.Get(tc, k, gs); err != nil {
 			if err != datastore.ErrNoSuchEntity {
 				return err
 			}
@@ -79,17 +120,19 @@ func (s *datastoreGameStore) Create(ctx context.Context, id, organizingPlayerID
 		}
 
 		gs = &Game{}
-		gs.PlayerIDs = make([]string, 4)
+		gs.PlayerIDs = make([]string, rules.NumPlayers())
 		gs.PlayerIDs[0] = organizingPlayerID
+		gs.OrganizerID = organizingPlayerID
 		gs.Created = time.Now().UTC()
 		gs.Updated = gs.Created
 		gs.Rules = rules
+		gs.EventCount = len(events)
 
-		if _, err := datastore.Put(ctx, k, gs); err != nil {
+		if _, err := datastore.Put(tc, k, gs); err != nil {
 			return err
 		}
 
-		return nil
+		return appendEvents(tc, k, 0, events)
 	}, &datastore.TransactionOptions{Attempts: 3})
 
 	if err != nil {
@@ -111,6 +154,8 @@ func (s *datastoreGameStore) Get(ctx context.Context, id string) (*Game, error)
 
 }
 
+// GetCurrentGames returns the player's incomplete games, most recently updated first. Games are matched on the seats
+// they hold now, as stored by Set and AddPlayer.
 func (s *datastoreGameStore) GetCurrentGames(ctx context.Context, playerID string, count int) ([]*Game, error) {
 	query := datastore.NewQuery(GameEntity).
 		Filter("PlayerIDs =", playerID).
@@ -135,20 +180,36 @@ func (s *datastoreGameStore) GetCurrentGames(ctx context.Context, playerID strin
 	return games, nil
 }
 
-func (s *datastoreGameStore) Set(ctx context.Context, id string, gs *Game) error {
+func (s *datastoreGameStore) Set(ctx context.Context, id string, gs *Game, lastUpdated time.Time, events ...*GameEvent) error {
 	k := gameKey(ctx, id)
-	gs.Updated = time.Now().UTC()
-	if _, err := datastore.Put(ctx, k, gs); err != nil {
-		return err
+	if gs.Updated.IsZero() {
+		gs.Updated = time.Now().UTC()
 	}
-	return nil
+	return datastore.RunInTransaction(ctx, func(tc context.Context) error {
+		current := &Game{}
+		if err := datastore.Get(tc, k, current); err != nil {
+			if err == datastore.ErrNoSuchEntity {
+				return ErrNotFound
+			}
+			return err
+		}
+		// Datastore stores times to the microsecond.
+		if !current.Updated.Truncate(time.Microsecond).Equal(lastUpdated.Truncate(time.Microsecond)) {
+			return ErrConflict
+		}
+		gs.EventCount = current.EventCount + len(events)
+		if _, err := datastore.Put(tc, k, gs); err != nil {
+			return err
+		}
+		return appendEvents(tc, k, current.EventCount, events)
+	}, &datastore.TransactionOptions{Attempts: retries})
 }
 
-func (s *datastoreGameStore) AddPlayer(ctx context.Context, id, playerID string, pos int) (*Game, error) {
+func (s *datastoreGameStore) AddPlayer(ctx context.Context, id, playerID string, pos int, events ...*GameEvent) (*Game, error) {
 	k := gameKey(ctx, id)
 	gs := &Game{}
 	err := datastore.RunInTransaction(ctx, func(tc context.Context) error {
-		if err := datastore.Get(ctx, k, gs); err != nil {
+		if err := datastore.Get(tc, k, gs); err != nil {
 			if err == datastore.ErrNoSuchEntity {
 				return ErrNotFound
 			}
@@ -170,10 +231,12 @@ func (s *datastoreGameStore) AddPlayer(ctx context.Context, id, playerID string,
 
 		gs.PlayerIDs[pos] = playerID
 		gs.Updated = time.Now().UTC()
-		if _, err := datastore.Put(ctx, k, gs); err != nil {
+		last := gs.EventCount
+		gs.EventCount += len(events)
+		if _, err := datastore.Put(tc, k, gs); err != nil {
 			return err
 		}
-		return nil
+		return appendEvents(tc, k, last, events)
 	}, &datastore.TransactionOptions{Attempts: 3})
 
 	if err != nil {
@@ -3,10 +3,12 @@ package storage
 import (
 	"context"
 	"errors"
+	"time"
 )
 
 type fakeGameStore struct {
-	games map[string]*Game
+	games  map[string]*Game
+	events map[string][]*GameEvent
 }
 
 var _ GameStore = (*fakeGameStore)(nil) // Ensure interface is implemented.
@@ -14,7 +16,8 @@ var _ GameStore = (*fakeGameStore)(nil) // Ensure interface is implemented.
 // NewFakeGameStore creates an in-memory game store, accepting an initial map of id->*Game.
 func NewFakeGameStore(games map[string]*Game) GameStore {
 	f := &fakeGameStore{
-		games: make(map[string]*Game),
+		games:  make(map[string]*Game),
+		events: make(map[string][]*GameEvent),
 	}
 	for k, v := range games {
 		f.games[k] = v
@@ -22,18 +25,20 @@ func NewFakeGameStore(games map[string]*Game) GameStore {
 	return f
 }
 
-func (s *fakeGameStore) Create(ctx context.Context, id, organizingPlayerID string, rules Rules) (*Game, error) {
+func (s *fakeGameStore) Create(ctx context.Context, id, organizingPlayerID string, rules Rules, events ...*GameEvent) (*Game, error) {
 	for k := range s.games {
 		if k == id {
 			return nil, ErrNotUnique
 		}
 	}
 	g := &Game{
-		PlayerIDs: make([]string, 4),
-		Rules:     rules,
+		PlayerIDs:   make([]string, rules.NumPlayers()),
+		OrganizerID: organizingPlayerID,
+		Rules:       rules,
 	}
 	g.PlayerIDs[0] = organizingPlayerID
 	s.games[id] = g
+	s.appendEvents(id, events)
 	return g, nil
 }
 
@@ -49,12 +54,16 @@ func (s *fakeGameStore) GetCurrentGames(ctx context.Context, playerID string, co
 	return nil, errors.New("Not implemented")
 }
 
-func (s *fakeGameStore) Set(ctx context.Context, id string, g *Game) error {
+func (s *fakeGameStore) Set(ctx context.Context, id string, g *Game, lastUpdated time.Time, events ...*GameEvent) error {
+	if current, present := s.games[id]; present && !current.Updated.Equal(lastUpdated) {
+		return ErrConflict
+	}
 	s.games[id] = g
+	s.appendEvents(id, events)
 	return nil
 }
 
-func (s *fakeGameStore) AddPlayer(ctx context.Context, id, playerID string, pos int) (*Game, error) {
+func (s *fakeGameStore) AddPlayer(ctx context.Context, id, playerID string, pos int, events ...*GameEvent) (*Game, error) {
 	g, present := s.games[id]
 	if !present {
 		return nil, ErrNotFound
@@ -68,5 +77,21 @@ func (s *fakeGameStore) AddPlayer(ctx context.Context, id, playerID string, pos
 		return nil, ErrPlayerPositionFilled
 	}
 	g.PlayerIDs[pos] = playerID
+	g.Updated = time.Now().UTC()
+	s.appendEvents(id, events)
 	return g, nil
 }
+
+func (s *fakeGameStore) Events(ctx context.Context, id string) ([]*GameEvent, error) {
+	return s.events[id], nil
+}
+
+func (s *fakeGameStore) appendEvents(id string, events []*GameEvent) {
+	for _, e := range events {
+		e.Seq = len(s.events[id]) + 1
+		s.events[id] = append(s.events[id], e)
+	}
+	if g, present := s.games[id]; present {
+		g.EventCount = len(s.events[id])
+	}
+}
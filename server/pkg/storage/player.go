@@ -8,7 +8,8 @@ import (
 )
 
 type Player struct {
-	Name string `datastore:",noindex"`
+	Name     string `datastore:",noindex"`
+	Strategy string `datastore:",noindex"` // The strategy that plays for a bot player; empty for a person.
 }
 
 type PlayerStore interface {
@@ -9,4 +9,5 @@ var (
 	ErrNotUnique            = errors.New("ID is not unique")
 	ErrPlayerPositionFilled = errors.New("Player position is already filled")
 	ErrPlayerAlreadyAdded   = errors.New("Player is already added")
+	ErrConflict             = errors.New("Game was changed by another request")
 )
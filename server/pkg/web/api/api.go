@@ -22,6 +22,9 @@ func NewServer(gameStore storage.GameStore, playerStore storage.PlayerStore, cac
 	}
 }
 
+// updateAttempts is how many times an update is tried before a conflict is reported.
+const updateAttempts = 3
+
 type ApiServer struct {
 	playerStore storage.PlayerStore
 	gameStore   storage.GameStore
@@ -29,8 +32,64 @@ type ApiServer struct {
 }
 
 type errorResponse struct {
-	Error string
-	Code  string
+	Error         string         // A human-readable message.
+	Code          game.ErrorCode // The kind of error, for clients to branch on; a game error code, or one of the Code* below.
+	CorrelationID string         // Identifies the error in the server logs.
+}
+
+// Error codes for errors that are not game errors.
+const (
+	CodeBadRequest       game.ErrorCode = "BAD_REQUEST"
+	CodeMethodNotAllowed game.ErrorCode = "METHOD_NOT_ALLOWED"
+	CodeInternal         game.ErrorCode = "INTERNAL"
+)
+
+// codeStatuses are the HTTP statuses of the error codes; any other code is a bad request.
+// Actions that are not allowed in the current state of the game conflict with it; actions that are never allowed
+// (e.g., a card that does not follow suit) are unprocessable.
+var codeStatuses = map[game.ErrorCode]int{
+	CodeMethodNotAllowed:          http.StatusMethodNotAllowed,
+	CodeInternal:                  http.StatusInternalServerError,
+	game.CodeNotFound:             http.StatusNotFound,
+	game.CodeNotInGame:            http.StatusForbidden,
+	game.CodeNotOrganizer:         http.StatusForbidden,
+	game.CodeConflict:             http.StatusConflict,
+	game.CodePositionFilled:       http.StatusConflict,
+	game.CodeAlreadyJoined:        http.StatusConflict,
+	game.CodeNotYourTurn:          http.StatusConflict,
+	game.CodeNotDealing:           http.StatusConflict,
+	game.CodeNotPassing:           http.StatusConflict,
+	game.CodeNotBidding:           http.StatusConflict,
+	game.CodeNotCalling:           http.StatusConflict,
+	game.CodeNotPlaying:           http.StatusConflict,
+	game.CodeAlreadyPassed:        http.StatusConflict,
+	game.CodeMisdealTooLate:       http.StatusConflict,
+	game.CodeNothingToUndo:        http.StatusConflict,
+	game.CodeUndoTooLate:          http.StatusConflict,
+	game.CodeUndoPending:          http.StatusConflict,
+	game.CodeNoUndoRequest:        http.StatusConflict,
+	game.CodeAlreadyApproved:      http.StatusConflict,
+	game.CodeNotJoining:           http.StatusConflict,
+	game.CodeNotInProgress:        http.StatusConflict,
+	game.CodeSeatSwapPending:      http.StatusConflict,
+	game.CodeNoSeatSwap:           http.StatusConflict,
+	game.CodeInvalidBid:           http.StatusUnprocessableEntity,
+	game.CodeCardNotInHand:        http.StatusUnprocessableEntity,
+	game.CodeMustFollowSuit:       http.StatusUnprocessableEntity,
+	game.CodePassingNotAllowed:    http.StatusUnprocessableEntity,
+	game.CodeMisdealNotAllowed:    http.StatusUnprocessableEntity,
+	game.CodeInvalidMisdeal:       http.StatusUnprocessableEntity,
+	game.CodeUndoRequester:        http.StatusUnprocessableEntity,
+	game.CodeOrganizerCannotLeave: http.StatusUnprocessableEntity,
+	game.CodeUnknownStrategy:      http.StatusUnprocessableEntity,
+}
+
+// codeStatus returns the HTTP status for the error code.
+func codeStatus(code game.ErrorCode) int {
+	if status, ok := codeStatuses[code]; ok {
+		return status
+	}
+	return http.StatusBadRequest
 }
 
 func (s *ApiServer) lookupPlayer(ctx context.Context, w http.ResponseWriter, r *http.Request) game.Player {
@@ -42,7 +101,7 @@ func (s *ApiServer) lookupPlayer(ctx context.Context, w http.ResponseWriter, r *
 	player, err := game.GetPlayer(ctx, s.playerStore, playerID)
 	if err != nil {
 		if err == game.ErrNotFound {
-			sendUserError(w, "Player not found.")
+			sendUserError(w, game.CodeNotFound, "Player not found.")
 			return nil
 		}
 		sendServerError(w, "looking up player: %v", err)
@@ -55,7 +114,7 @@ func (s *ApiServer) lookupGame(ctx context.Context, w http.ResponseWriter, id st
 	g, err := game.GetGame(ctx, s.gameStore, s.playerStore, id)
 	if err != nil {
 		if err == game.ErrNotFound {
-			sendUserError(w, "Game not found.")
+			sendUserError(w, game.CodeNotFound, "Game not found.")
 			return nil
 		}
 		sendServerError(w, "looking up game: %v", err)
@@ -64,11 +123,40 @@ func (s *ApiServer) lookupGame(ctx context.Context, w http.ResponseWriter, id st
 	return g
 }
 
+// updateGame applies the update to the game, then plays the turns of any bots. If another request changed the game
+// first, the game is looked up again and the update retried; game.ErrConflict is returned if it keeps changing.
+func (s *ApiServer) updateGame(ctx context.Context, g game.Game, update func(game.Game) (game.Game, error)) (game.Game, error) {
+	for attempt := 1; ; attempt++ {
+		newG, err := update(g)
+		if err == nil {
+			return s.playBots(ctx, newG), nil
+		}
+		if err != game.ErrConflict || attempt == updateAttempts {
+			return newG, err
+		}
+		g, err = game.GetGame(ctx, s.gameStore, s.playerStore, g.ID())
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+// playBots plays the turns of any bots in the game. The update that led to them has been saved, so a failure is only
+// logged; the bots are played again with the next update, or when the turn clock is enforced.
+func (s *ApiServer) playBots(ctx context.Context, g game.Game) game.Game {
+	newG, err := g.PlayBots(ctx)
+	if err != nil {
+		log.Printf("Playing bots in game %q. Suppressing error: %v", g.ID(), err)
+		return g
+	}
+	return newG
+}
+
 func (s *ApiServer) sendGameState(ctx context.Context, w http.ResponseWriter, g game.Game, player game.Player) {
 	s.setGameStateVersion(ctx, g.ID(), g.Version())
 	state, err := BuildGameState(g, player)
 	if err != nil {
-		sendServerError(w, "building game state: %v", err)
+		sendGameError(w, "building game state", err)
 		return
 	}
 	if os.Getenv("DEBUG") != "" {
@@ -92,28 +180,55 @@ func (s *ApiServer) sendJoinState(ctx context.Context, w http.ResponseWriter, g
 	}
 }
 
-func sendUserError(w http.ResponseWriter, format string, args ...interface{}) {
+func sendUserError(w http.ResponseWriter, code game.ErrorCode, format string, args ...interface{}) {
 	msg := fmt.Sprintf(format, args...)
-	code := util.RandString(10)
+	correlationID := util.RandString(10)
+	resp := errorResponse{
+		Error:         msg,
+		Code:          code,
+		CorrelationID: correlationID,
+	}
+	log.Printf("User error %s [%s]: %v", code, correlationID, msg)
+	if err := sendResponseStatus(w, resp, codeStatus(code)); err != nil {
+		sendServerError(w, "sending response: %v", err)
+	}
+}
+
+// sendGameError sends the error from a game action; errors without a game error code are server errors.
+func sendGameError(w http.ResponseWriter, action string, err error) {
+	switch code := game.CodeOf(err); code {
+	case "":
+		sendServerError(w, "%s: %v", action, err)
+	case game.CodeConflict:
+		sendConflictError(w)
+	default:
+		sendUserError(w, code, "%v", err)
+	}
+}
+
+func sendConflictError(w http.ResponseWriter) {
+	correlationID := util.RandString(10)
 	resp := errorResponse{
-		Error: msg,
-		Code:  code,
+		Error:         "The game was changed by another player. Please try again.",
+		Code:          game.CodeConflict,
+		CorrelationID: correlationID,
 	}
-	log.Printf("User error [%s]: %v", code, msg)
-	if err := sendResponseStatus(w, resp, http.StatusBadRequest); err != nil {
+	log.Printf("Conflict error [%s]", correlationID)
+	if err := sendResponseStatus(w, resp, codeStatus(game.CodeConflict)); err != nil {
 		sendServerError(w, "sending response: %v", err)
 	}
 }
 
 func sendServerError(w http.ResponseWriter, format string, args ...interface{}) {
 	msg := fmt.Sprintf(format, args...)
-	code := util.RandString(10)
+	correlationID := util.RandString(10)
 	resp := errorResponse{
-		Error: fmt.Sprintf("Internal error. Please try again. [%s]", code),
-		Code:  code,
+		Error:         fmt.Sprintf("Internal error. Please try again. [%s]", correlationID),
+		Code:          CodeInternal,
+		CorrelationID: correlationID,
 	}
-	log.Printf("Server error [%s]: %v", code, msg)
-	if err := sendResponseStatus(w, resp, http.StatusInternalServerError); err != nil {
+	log.Printf("Server error [%s]: %v", correlationID, msg)
+	if err := sendResponseStatus(w, resp, codeStatus(CodeInternal)); err != nil {
 		log.Printf("Error response failed: %v", err)
 		w.WriteHeader(500)
 	}
@@ -16,7 +16,7 @@ type PlaceBidRequest struct {
 func (s *ApiServer) PlaceBid(w http.ResponseWriter, r *http.Request) {
 	ctx := appengine.NewContext(r)
 	if r.Method != "POST" {
-		sendUserError(w, "Invalid method")
+		sendUserError(w, CodeMethodNotAllowed, "Invalid method")
 		return
 	}
 
@@ -28,7 +28,7 @@ func (s *ApiServer) PlaceBid(w http.ResponseWriter, r *http.Request) {
 	var req PlaceBidRequest
 	err := json.NewDecoder(r.Body).Decode(&req)
 	if err != nil {
-		sendServerError(w, "decoding request: %v", err)
+		sendUserError(w, CodeBadRequest, "Invalid request: %v", err)
 		return
 	}
 
@@ -39,14 +39,15 @@ func (s *ApiServer) PlaceBid(w http.ResponseWriter, r *http.Request) {
 
 	bid, err := game.NewBidFromEncoded(req.Bid)
 	if err != nil {
-		sendServerError(w, "creating bid: %v", err)
+		sendUserError(w, CodeBadRequest, "Invalid bid: %v", err)
 		return
 	}
 
-	newG, err := g.PlaceBid(ctx, player, bid)
+	newG, err := s.updateGame(ctx, g, func(g game.Game) (game.Game, error) {
+		return g.PlaceBid(ctx, player, bid)
+	})
 	if err != nil {
-		// TODO: return user errors with better details for invalid bids
-		sendServerError(w, "placing bid: %v", err)
+		sendGameError(w, "placing bid", err)
 		return
 	}
 
@@ -0,0 +1,51 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/squee1945/threespot/server/pkg/game"
+	"google.golang.org/appengine"
+)
+
+type EnforceTurnClockRequest struct {
+	ID string
+}
+
+// EnforceTurnClock acts for the player to play if their turn clock has run out, then plays any bots still to play.
+// Clients post it once the TurnDeadline has passed, or when a bot is left to play; otherwise the game is not changed.
+func (s *ApiServer) EnforceTurnClock(w http.ResponseWriter, r *http.Request) {
+	ctx := appengine.NewContext(r)
+	if r.Method != "POST" {
+		sendUserError(w, CodeMethodNotAllowed, "Invalid method")
+		return
+	}
+
+	player := s.lookupPlayer(ctx, w, r)
+	if player == nil {
+		return
+	}
+
+	var req EnforceTurnClockRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		sendUserError(w, CodeBadRequest, "Invalid request: %v", err)
+		return
+	}
+
+	g := s.lookupGame(ctx, w, req.ID)
+	if g == nil {
+		return
+	}
+
+	newG, err := s.updateGame(ctx, g, func(g game.Game) (game.Game, error) {
+		return g.EnforceTurnClock(ctx, time.Now())
+	})
+	if err != nil {
+		sendGameError(w, "enforcing turn clock", err)
+		return
+	}
+
+	s.sendGameState(ctx, w, newG, player)
+}
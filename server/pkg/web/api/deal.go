@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"net/http"
 
+	"github.com/squee1945/threespot/server/pkg/game"
 	"google.golang.org/appengine"
 )
 
@@ -14,7 +15,7 @@ type DealCardsRequest struct {
 func (s *ApiServer) DealCards(w http.ResponseWriter, r *http.Request) {
 	ctx := appengine.NewContext(r)
 	if r.Method != "POST" {
-		sendUserError(w, "Invalid method")
+		sendUserError(w, CodeMethodNotAllowed, "Invalid method")
 		return
 	}
 
@@ -26,7 +27,7 @@ func (s *ApiServer) DealCards(w http.ResponseWriter, r *http.Request) {
 	var req DealCardsRequest
 	err := json.NewDecoder(r.Body).Decode(&req)
 	if err != nil {
-		sendServerError(w, "decoding request: %v", err)
+		sendUserError(w, CodeBadRequest, "Invalid request: %v", err)
 		return
 	}
 
@@ -35,9 +36,12 @@ func (s *ApiServer) DealCards(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	newG, err := g.DealCards(ctx, player)
+	newG, err := s.updateGame(ctx, g, func(g game.Game) (game.Game, error) {
+		return g.DealCards(ctx, player)
+	})
 	if err != nil {
-		sendUserError(w, "dealing cards: %v", err)
+		sendGameError(w, "dealing cards", err)
+		return
 	}
 
 	s.sendGameState(ctx, w, newG, player)
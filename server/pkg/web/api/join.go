@@ -3,8 +3,10 @@ package api
 import (
 	"encoding/json"
 	"net/http"
+	"strings"
 
 	"github.com/squee1945/threespot/server/pkg/game"
+	"github.com/squee1945/threespot/server/pkg/util"
 	"google.golang.org/appengine"
 )
 
@@ -16,7 +18,7 @@ type JoinGameRequest struct {
 func (s *ApiServer) JoinGame(w http.ResponseWriter, r *http.Request) {
 	ctx := appengine.NewContext(r)
 	if r.Method != "POST" {
-		sendUserError(w, "Invalid method")
+		sendUserError(w, CodeMethodNotAllowed, "Invalid method")
 		return
 	}
 
@@ -28,7 +30,7 @@ func (s *ApiServer) JoinGame(w http.ResponseWriter, r *http.Request) {
 	var req JoinGameRequest
 	err := json.NewDecoder(r.Body).Decode(&req)
 	if err != nil {
-		sendServerError(w, "decoding request: %v", err)
+		sendUserError(w, CodeBadRequest, "Invalid request: %v", err)
 		return
 	}
 
@@ -40,16 +42,71 @@ func (s *ApiServer) JoinGame(w http.ResponseWriter, r *http.Request) {
 	newG, err := g.AddPlayer(ctx, player, req.Position)
 	if err != nil {
 		if err == game.ErrInvalidPosition {
-			sendUserError(w, "Invalid player position.")
+			sendUserError(w, game.CodeInvalidPosition, "Invalid player position.")
 			return
 		}
 		if err == game.ErrPlayerAlreadyAdded {
-			sendUserError(w, "You're already in this game!")
+			sendUserError(w, game.CodeAlreadyJoined, "You're already in this game!")
 			return
 		}
-		sendServerError(w, "adding player: %v", err)
+		sendGameError(w, "adding player", err)
 		return
 	}
+	newG = s.playBots(ctx, newG)
+
+	s.sendJoinState(ctx, w, newG)
+}
+
+type AddBotRequest struct {
+	ID       string
+	Position int
+	Strategy string // The name of a registered game.Strategy (e.g., "simple").
+	Name     string // The name shown for the bot; empty for "Bot".
+}
+
+func (s *ApiServer) AddBot(w http.ResponseWriter, r *http.Request) {
+	ctx := appengine.NewContext(r)
+	if r.Method != "POST" {
+		sendUserError(w, CodeMethodNotAllowed, "Invalid method")
+		return
+	}
+
+	player := s.lookupPlayer(ctx, w, r)
+	if player == nil {
+		return
+	}
+
+	var req AddBotRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		sendUserError(w, CodeBadRequest, "Invalid request: %v", err)
+		return
+	}
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		name = "Bot"
+	}
+
+	g := s.lookupGame(ctx, w, req.ID)
+	if g == nil {
+		return
+	}
+	if !g.IsOrganizer(player) {
+		sendUserError(w, game.CodeNotOrganizer, "Only the organizer can add bots.")
+		return
+	}
+
+	bot, err := game.NewBotPlayer(ctx, s.playerStore, "BOT"+util.RandString(12), name, req.Strategy)
+	if err != nil {
+		sendGameError(w, "creating bot", err)
+		return
+	}
+	newG, err := g.AddPlayer(ctx, bot, req.Position)
+	if err != nil {
+		sendGameError(w, "adding bot", err)
+		return
+	}
+	newG = s.playBots(ctx, newG)
 
 	s.sendJoinState(ctx, w, newG)
 }
@@ -12,34 +12,50 @@ type JoinStateResponse struct {
 	ID          string
 	Version     string
 	PlayerNames []string
+	PlayerBots  []bool // Whether each player is a bot.
 	State       string
 	PlayerCount int
+
+	SeatSwapPosition   int // The position of the player asking to swap seats; -1 if none.
+	SeatSwapToPosition int // The position they asked for; that player may approve or reject the swap. -1 if none.
 }
 
 func BuildJoinState(g game.Game) *JoinStateResponse {
 	var names []string
+	var bots []bool
 	var count int
 	for _, p := range g.Players() {
 		if p == nil {
 			names = append(names, "")
+			bots = append(bots, false)
 			continue
 		}
 		count += 1
 		names = append(names, p.Name())
+		bots = append(bots, p.Strategy() != "")
 	}
-	return &JoinStateResponse{
+	state := &JoinStateResponse{
 		ID:          g.ID(),
 		Version:     g.Version(),
 		PlayerNames: names,
+		PlayerBots:  bots,
 		State:       string(g.State()),
 		PlayerCount: count,
+
+		SeatSwapPosition:   -1,
+		SeatSwapToPosition: -1,
+	}
+	if swap := g.SeatSwap(); swap != nil {
+		state.SeatSwapPosition = swap.Pos
+		state.SeatSwapToPosition = swap.ToPos
 	}
+	return state
 }
 
 func (s *ApiServer) JoinGameState(w http.ResponseWriter, r *http.Request) {
 	ctx := appengine.NewContext(r)
 	if r.Method != "GET" {
-		sendUserError(w, "Invalid method")
+		sendUserError(w, CodeMethodNotAllowed, "Invalid method")
 		return
 	}
 
@@ -47,7 +63,7 @@ func (s *ApiServer) JoinGameState(w http.ResponseWriter, r *http.Request) {
 	if strings.HasPrefix(r.URL.Path, "/api/join-state/") {
 		id = r.URL.Path[len("/api/join-state/"):]
 	} else {
-		sendUserError(w, "Missing ID")
+		sendUserError(w, CodeBadRequest, "Missing ID")
 		return
 	}
 
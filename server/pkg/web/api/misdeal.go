@@ -0,0 +1,48 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/squee1945/threespot/server/pkg/game"
+	"google.golang.org/appengine"
+)
+
+type ClaimMisdealRequest struct {
+	ID string
+}
+
+func (s *ApiServer) ClaimMisdeal(w http.ResponseWriter, r *http.Request) {
+	ctx := appengine.NewContext(r)
+	if r.Method != "POST" {
+		sendUserError(w, CodeMethodNotAllowed, "Invalid method")
+		return
+	}
+
+	player := s.lookupPlayer(ctx, w, r)
+	if player == nil {
+		return
+	}
+
+	var req ClaimMisdealRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		sendUserError(w, CodeBadRequest, "Invalid request: %v", err)
+		return
+	}
+
+	g := s.lookupGame(ctx, w, req.ID)
+	if g == nil {
+		return
+	}
+
+	newG, err := s.updateGame(ctx, g, func(g game.Game) (game.Game, error) {
+		return g.ClaimMisdeal(ctx, player)
+	})
+	if err != nil {
+		sendGameError(w, "claiming misdeal", err)
+		return
+	}
+
+	s.sendGameState(ctx, w, newG, player)
+}
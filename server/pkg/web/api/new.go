@@ -3,6 +3,7 @@ package api
 import (
 	"encoding/json"
 	"net/http"
+	"time"
 
 	"github.com/squee1945/threespot/server/pkg/game"
 	"github.com/squee1945/threespot/server/pkg/util"
@@ -10,13 +11,31 @@ import (
 )
 
 type NewGameRequest struct {
-	PassCard bool
+	// Preset is the ID of a rule preset (e.g., "standard-52"); if set, the remaining rule fields are ignored.
+	Preset        string
+	Players       int // Number of players (3 to 6); 0 for the default (4). An odd number of players plays cutthroat.
+	PassCard      bool
+	PassCount     int    // Cards passed by each player (1 to 3); 0 for the default (1).
+	PassDirection string // "across", "left", "right" or "rotate"; empty for "across".
+	PassTiming    string // "before" bidding, or "after" the auction; empty for "before".
+	ToWin         int    // Score needed to win; 0 for the default (52).
+	FixedToWin    bool   // If true, a successful no trump bid does not raise ToWin.
+	NoTrumpToWin  int    // Score needed to win after a successful no trump bid; 0 for the default (62).
+	DefenderCap   int    // The non-bidding team cannot score past this; 0 for no cap.
+	AllPass       string // What happens when everyone passes: "stick", "same" or "next"; empty for "stick".
+	MinBid        int    // The lowest bid that may be placed; 0 for the default (7).
+	// DealerMustOverbid requires the dealer to bid higher than the high bid, rather than take it.
+	DealerMustOverbid bool
+	Misdeal           string // The hand a player may claim a misdeal for: "none", "noface" or "noaces"; empty for "none".
+	// TurnSeconds is how long each player has to act before a default action is taken for them; 0 for no limit.
+	// It applies to presets too.
+	TurnSeconds int
 }
 
 func (s *ApiServer) NewGame(w http.ResponseWriter, r *http.Request) {
 	ctx := appengine.NewContext(r)
 	if r.Method != "POST" {
-		sendUserError(w, "Invalid method")
+		sendUserError(w, CodeMethodNotAllowed, "Invalid method")
 		return
 	}
 
@@ -27,12 +46,43 @@ func (s *ApiServer) NewGame(w http.ResponseWriter, r *http.Request) {
 
 	var req NewGameRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		sendServerError(w, "decoding request: %v", err)
+		sendUserError(w, CodeBadRequest, "Invalid request: %v", err)
 		return
 	}
 
-	rules := game.NewRules()
-	rules.SetPassCard(req.PassCard)
+	var rules game.Rules
+	if req.Preset != "" {
+		var err error
+		rules, err = game.NewRulesFromPreset(req.Preset)
+		if err != nil {
+			if err == game.ErrUnknownPreset {
+				sendUserError(w, game.CodeUnknownPreset, "Unknown rule preset.")
+				return
+			}
+			sendGameError(w, "building preset rules", err)
+			return
+		}
+	} else {
+		rules = game.NewRules()
+		rules.SetPlayers(req.Players)
+		rules.SetPassCard(req.PassCard)
+		rules.SetPassCount(req.PassCount)
+		rules.SetPassDirection(game.PassDirection(req.PassDirection))
+		rules.SetPassTiming(game.PassTiming(req.PassTiming))
+		rules.SetToWin(req.ToWin)
+		rules.SetNoTrumpRaisesToWin(!req.FixedToWin)
+		rules.SetNoTrumpToWin(req.NoTrumpToWin)
+		rules.SetDefenderCap(req.DefenderCap)
+		rules.SetAllPass(game.AllPassRule(req.AllPass))
+		rules.SetMinBid(req.MinBid)
+		rules.SetDealerMustOverbid(req.DealerMustOverbid)
+		rules.SetMisdeal(game.MisdealRule(req.Misdeal))
+	}
+	rules.SetTurnLimit(time.Duration(req.TurnSeconds) * time.Second)
+	if err := rules.Validate(); err != nil {
+		sendGameError(w, "validating rules", err)
+		return
+	}
 
 	id := util.RandString(7)
 	g, err := game.NewGame(ctx, s.gameStore, s.playerStore, id, player, rules)
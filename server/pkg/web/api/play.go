@@ -5,6 +5,7 @@ import (
 	"net/http"
 
 	"github.com/squee1945/threespot/server/pkg/deck"
+	"github.com/squee1945/threespot/server/pkg/game"
 	"google.golang.org/appengine"
 )
 
@@ -16,7 +17,7 @@ type PlayCardRequest struct {
 func (s *ApiServer) PlayCard(w http.ResponseWriter, r *http.Request) {
 	ctx := appengine.NewContext(r)
 	if r.Method != "POST" {
-		sendUserError(w, "Invalid method")
+		sendUserError(w, CodeMethodNotAllowed, "Invalid method")
 		return
 	}
 
@@ -28,7 +29,7 @@ func (s *ApiServer) PlayCard(w http.ResponseWriter, r *http.Request) {
 	var req PlayCardRequest
 	err := json.NewDecoder(r.Body).Decode(&req)
 	if err != nil {
-		sendServerError(w, "decoding request: %v", err)
+		sendUserError(w, CodeBadRequest, "Invalid request: %v", err)
 		return
 	}
 
@@ -39,13 +40,15 @@ func (s *ApiServer) PlayCard(w http.ResponseWriter, r *http.Request) {
 
 	card, err := deck.NewCardFromEncoded(req.Card)
 	if err != nil {
-		sendServerError(w, "creating card: %v", err)
+		sendUserError(w, CodeBadRequest, "Invalid card: %v", err)
 		return
 	}
 
-	newG, err := g.PlayCard(ctx, player, card)
+	newG, err := s.updateGame(ctx, g, func(g game.Game) (game.Game, error) {
+		return g.PlayCard(ctx, player, card)
+	})
 	if err != nil {
-		sendUserError(w, "playing card: %v", err)
+		sendGameError(w, "playing card", err)
 		return
 	}
 
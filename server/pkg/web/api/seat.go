@@ -0,0 +1,180 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/squee1945/threespot/server/pkg/game"
+	"google.golang.org/appengine"
+)
+
+type LeaveGameRequest struct {
+	ID string
+}
+
+type ChangeSeatRequest struct {
+	ID       string
+	Position int
+}
+
+type RespondToSeatSwapRequest struct {
+	ID      string
+	Approve bool
+}
+
+type ReplacePlayerRequest struct {
+	ID       string
+	Position int
+	PlayerID string // The substitute.
+}
+
+func (s *ApiServer) LeaveGame(w http.ResponseWriter, r *http.Request) {
+	ctx := appengine.NewContext(r)
+	if r.Method != "POST" {
+		sendUserError(w, CodeMethodNotAllowed, "Invalid method")
+		return
+	}
+
+	player := s.lookupPlayer(ctx, w, r)
+	if player == nil {
+		return
+	}
+
+	var req LeaveGameRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		sendUserError(w, CodeBadRequest, "Invalid request: %v", err)
+		return
+	}
+
+	g := s.lookupGame(ctx, w, req.ID)
+	if g == nil {
+		return
+	}
+
+	newG, err := s.updateGame(ctx, g, func(g game.Game) (game.Game, error) {
+		return g.LeaveGame(ctx, player)
+	})
+	if err != nil {
+		sendGameError(w, "leaving game", err)
+		return
+	}
+
+	s.sendJoinState(ctx, w, newG)
+}
+
+func (s *ApiServer) ChangeSeat(w http.ResponseWriter, r *http.Request) {
+	ctx := appengine.NewContext(r)
+	if r.Method != "POST" {
+		sendUserError(w, CodeMethodNotAllowed, "Invalid method")
+		return
+	}
+
+	player := s.lookupPlayer(ctx, w, r)
+	if player == nil {
+		return
+	}
+
+	var req ChangeSeatRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		sendUserError(w, CodeBadRequest, "Invalid request: %v", err)
+		return
+	}
+
+	g := s.lookupGame(ctx, w, req.ID)
+	if g == nil {
+		return
+	}
+
+	newG, err := s.updateGame(ctx, g, func(g game.Game) (game.Game, error) {
+		return g.ChangeSeat(ctx, player, req.Position)
+	})
+	if err != nil {
+		sendGameError(w, "changing seat", err)
+		return
+	}
+
+	s.sendJoinState(ctx, w, newG)
+}
+
+func (s *ApiServer) RespondToSeatSwap(w http.ResponseWriter, r *http.Request) {
+	ctx := appengine.NewContext(r)
+	if r.Method != "POST" {
+		sendUserError(w, CodeMethodNotAllowed, "Invalid method")
+		return
+	}
+
+	player := s.lookupPlayer(ctx, w, r)
+	if player == nil {
+		return
+	}
+
+	var req RespondToSeatSwapRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		sendUserError(w, CodeBadRequest, "Invalid request: %v", err)
+		return
+	}
+
+	g := s.lookupGame(ctx, w, req.ID)
+	if g == nil {
+		return
+	}
+
+	newG, err := s.updateGame(ctx, g, func(g game.Game) (game.Game, error) {
+		return g.RespondToSeatSwap(ctx, player, req.Approve)
+	})
+	if err != nil {
+		sendGameError(w, "responding to seat swap", err)
+		return
+	}
+
+	s.sendJoinState(ctx, w, newG)
+}
+
+func (s *ApiServer) ReplacePlayer(w http.ResponseWriter, r *http.Request) {
+	ctx := appengine.NewContext(r)
+	if r.Method != "POST" {
+		sendUserError(w, CodeMethodNotAllowed, "Invalid method")
+		return
+	}
+
+	player := s.lookupPlayer(ctx, w, r)
+	if player == nil {
+		return
+	}
+
+	var req ReplacePlayerRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		sendUserError(w, CodeBadRequest, "Invalid request: %v", err)
+		return
+	}
+
+	substitute, err := game.GetPlayer(ctx, s.playerStore, req.PlayerID)
+	if err != nil {
+		if err == game.ErrNotFound {
+			sendUserError(w, game.CodeNotFound, "Substitute player not found.")
+			return
+		}
+		sendServerError(w, "looking up substitute: %v", err)
+		return
+	}
+
+	g := s.lookupGame(ctx, w, req.ID)
+	if g == nil {
+		return
+	}
+
+	newG, err := s.updateGame(ctx, g, func(g game.Game) (game.Game, error) {
+		return g.ReplacePlayer(ctx, player, req.Position, substitute)
+	})
+	if err != nil {
+		sendGameError(w, "replacing player", err)
+		return
+	}
+
+	// The organizer may have handed over their own seat, so they get the state of the table rather than a hand.
+	s.sendJoinState(ctx, w, newG)
+}
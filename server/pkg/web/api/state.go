@@ -3,6 +3,7 @@ package api
 import (
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/squee1945/threespot/server/pkg/deck"
 	"github.com/squee1945/threespot/server/pkg/game"
@@ -15,14 +16,52 @@ type BidInfo struct {
 }
 
 type ScoreEntry struct {
-	Score02 int
-	Note02  string
-	Score13 int
-	Note13  string
+	Scores []int    // The score of each team, indexed by team.
+	Notes  []string // The note for each team, indexed by team.
+}
+
+// HandRecord is the breakdown of a completed (or thrown-in) hand.
+type HandRecord struct {
+	ScoreIndex         int // Index into Score.
+	DealerPosition     int
+	LeadBidPosition    int
+	BidsPlaced         []BidInfo
+	WinningBid         BidInfo // Empty if the hand was thrown in.
+	WinningBidPosition int     // -1 if the hand was thrown in.
+	Trump              string  // Empty if the hand was thrown in.
+	Tricks             []int   // Tricks taken, indexed by team.
+	Points             []int   // Points taken, indexed by team.
+	FiveOfHeartsTeam   int     // The team that took the 5 of hearts; -1 if none.
+	ThreeOfSpadesTeam  int     // The team that took the 3 of spades; -1 if none.
+}
+
+// LegalActions are what the player may do now.
+type LegalActions struct {
+	Deal    bool
+	Misdeal bool
+	Pass    []string // The cards the player may pass.
+	Bids    []BidInfo
+	Trumps  []string
+	Play    []string // The cards the player may play; the rest of the hand cannot be played.
 }
 
 type Rules struct {
-	PassCard bool
+	Preset             string // The rule preset ID; empty for custom rules.
+	PresetName         string
+	Players            int
+	PassCard           bool
+	PassCount          int
+	PassDirection      string
+	PassTiming         string
+	ToWin              int
+	NoTrumpRaisesToWin bool
+	NoTrumpToWin       int
+	DefenderCap        int
+	AllPass            string
+	MinBid             int
+	DealerMustOverbid  bool
+	Misdeal            string
+	TurnSeconds        int // Seconds each player has to act; 0 if there is no limit.
 }
 
 type GameStateResponse struct {
@@ -32,10 +71,13 @@ type GameStateResponse struct {
 
 	PlayerPosition int // player's original position
 	PlayerNames    []string
+	PlayerBots     []bool  // Whether each player is a bot.
+	TeamPositions  [][]int // The player positions on each team, indexed by team (e.g., [[0 2] [1 3]]).
 	Score          []ScoreEntry
+	Hands          []HandRecord // The breakdown of each hand, oldest first.
 	CurrentScore   []int
 	ToWin          int
-	WinningTeam    int // 0 is players 0/2, 1 is players 1/3; -1 is neither.
+	WinningTeam    int // Index into TeamPositions (e.g., 0 is players 0/2, 1 is players 1/3); -1 is neither.
 
 	DealerPosition           int // last bidder
 	PlayerHand               []string
@@ -47,19 +89,25 @@ type GameStateResponse struct {
 	LastTrickWinningPosition int
 
 	PositionToPlay int
+	TurnDeadline   int64 // When PositionToPlay runs out of time and a default action is taken, in Unix milliseconds; 0 if there is no limit.
 
 	LeadBidPosition int
 	BidsPlaced      []BidInfo
 
 	LeadPassPosition int
-	CardsPassed      []bool
-	CardReceived     string
+	CardsPassed      []int    // The number of cards passed by each player position.
+	CardsReceived    []string // The cards passed to this player, while the hand is still full.
 
 	AvailableBids      []BidInfo
 	WinningBid         BidInfo
 	WinningBidPosition int
 	Trump              string
-	TrickTally         []int
+	TrickTally         []int // Points taken in the current hand, indexed by team.
+
+	LegalActions LegalActions // What the player may do now; empty if it is not their turn.
+
+	UndoRequestPosition int   // The position of the player asking to take back their last action; -1 if none.
+	UndoApprovals       []int // The positions of the players that have approved the undo request.
 
 	Rules Rules
 }
@@ -67,7 +115,7 @@ type GameStateResponse struct {
 func (s *ApiServer) GameState(w http.ResponseWriter, r *http.Request) {
 	ctx := appengine.NewContext(r)
 	if r.Method != "GET" {
-		sendUserError(w, "Invalid method")
+		sendUserError(w, CodeMethodNotAllowed, "Invalid method")
 		return
 	}
 
@@ -75,7 +123,7 @@ func (s *ApiServer) GameState(w http.ResponseWriter, r *http.Request) {
 	if strings.HasPrefix(r.URL.Path, "/api/state/") {
 		id = r.URL.Path[len("/api/state/"):]
 	} else {
-		sendUserError(w, "Missing ID")
+		sendUserError(w, CodeBadRequest, "Missing ID")
 		return
 	}
 
@@ -103,24 +151,44 @@ func (s *ApiServer) GameState(w http.ResponseWriter, r *http.Request) {
 
 func BuildGameState(g game.Game, player game.Player) (*GameStateResponse, error) {
 	var playerNames []string
+	var playerBots []bool
 	for _, p := range g.Players() {
 		if p == nil {
 			playerNames = append(playerNames, "")
+			playerBots = append(playerBots, false)
 			continue
 		}
 		playerNames = append(playerNames, p.Name())
+		playerBots = append(playerBots, p.Strategy() != "")
 	}
 
 	rules := Rules{
-		PassCard: g.Rules().PassCard(),
+		Preset:             g.Rules().Preset(),
+		PresetName:         game.PresetName(g.Rules().Preset()),
+		Players:            g.Rules().Players(),
+		PassCard:           g.Rules().PassCard(),
+		PassCount:          g.Rules().PassCount(),
+		PassDirection:      string(g.Rules().PassDirection()),
+		PassTiming:         string(g.Rules().PassTiming()),
+		ToWin:              g.Rules().ToWin(),
+		NoTrumpRaisesToWin: g.Rules().NoTrumpRaisesToWin(),
+		NoTrumpToWin:       g.Rules().NoTrumpToWin(),
+		DefenderCap:        g.Rules().DefenderCap(),
+		AllPass:            string(g.Rules().AllPass()),
+		MinBid:             g.Rules().MinBid(),
+		DealerMustOverbid:  g.Rules().DealerMustOverbid(),
+		Misdeal:            string(g.Rules().Misdeal()),
+		TurnSeconds:        int(g.Rules().TurnLimit() / time.Second),
 	}
 
 	state := &GameStateResponse{
-		ID:          g.ID(),
-		Version:     g.Version(),
-		State:       string(g.State()),
-		PlayerNames: playerNames,
-		Rules:       rules,
+		ID:            g.ID(),
+		Version:       g.Version(),
+		State:         string(g.State()),
+		PlayerNames:   playerNames,
+		PlayerBots:    playerBots,
+		TeamPositions: game.TeamPositions(g.Rules().Players()),
+		Rules:         rules,
 	}
 	if g.State() == game.JoiningState {
 		return state, nil
@@ -143,20 +211,14 @@ func BuildGameState(g game.Game, player game.Player) (*GameStateResponse, error)
 
 	var scores []ScoreEntry
 	for _, round := range g.Score().Scores() {
-		score := ScoreEntry{Score02: round[0], Score13: round[1]}
+		score := ScoreEntry{Scores: round, Notes: make([]string, len(round))}
 		scores = append(scores, score)
 	}
 	for _, note := range g.Score().Notes() {
-		if note.Index >= len(scores) {
-			continue
-		}
-		if note.Team == 0 {
-			scores[note.Index].Note02 = note.Note
+		if note.Index >= len(scores) || note.Team >= len(scores[note.Index].Notes) {
 			continue
 		}
-		if note.Team == 1 {
-			scores[note.Index].Note13 = note.Note
-		}
+		scores[note.Index].Notes[note.Team] = note.Note
 	}
 
 	state = &GameStateResponse{
@@ -165,7 +227,10 @@ func BuildGameState(g game.Game, player game.Player) (*GameStateResponse, error)
 		State:          string(g.State()),
 		PlayerPosition: playerPos,
 		PlayerNames:    playerNames,
+		PlayerBots:     playerBots,
+		TeamPositions:  game.TeamPositions(g.Rules().Players()),
 		Score:          scores,
+		Hands:          handRecords(g.Score().Hands()),
 		CurrentScore:   g.Score().CurrentScore(),
 		ToWin:          g.Score().ToWin(),
 		WinningTeam:    g.Score().Winner(),
@@ -174,6 +239,30 @@ func BuildGameState(g game.Game, player game.Player) (*GameStateResponse, error)
 		HandCounts:     g.HandCounts(),
 		PositionToPlay: positionToPlay,
 		Rules:          rules,
+
+		UndoRequestPosition: -1,
+	}
+
+	if deadline := g.TurnDeadline(); !deadline.IsZero() {
+		state.TurnDeadline = deadline.UnixNano() / int64(time.Millisecond)
+	}
+
+	legal, err := g.LegalActions(player)
+	if err != nil {
+		return nil, err
+	}
+	state.LegalActions = LegalActions{
+		Deal:    legal.Deal,
+		Misdeal: legal.Misdeal,
+		Pass:    cardsToStrings(legal.Pass),
+		Bids:    bidsToBidInfos(legal.Bids),
+		Trumps:  suitsToStrings(legal.Trumps),
+		Play:    cardsToStrings(legal.Play),
+	}
+
+	if undo := g.UndoRequest(); undo != nil {
+		state.UndoRequestPosition = undo.Pos
+		state.UndoApprovals = undo.Approvals
 	}
 
 	if g.CurrentTrick() != nil {
@@ -194,19 +283,18 @@ func BuildGameState(g game.Game, player game.Player) (*GameStateResponse, error)
 
 	if g.PassedCards() != nil {
 		state.LeadPassPosition = g.PassedCards().LeadPos()
-		passed := []bool{false, false, false, false}
-		for i := 0; i < g.PassedCards().NumPassed(); i++ {
-			passed[i] = true
+		passed := make([]int, len(g.Players()))
+		for pos := range passed {
+			passed[pos] = g.PassedCards().NumPassedBy(pos)
 		}
 		state.CardsPassed = passed
 
-		if g.PassedCards().NumPassed() == 4 && len(playerHand.Cards()) == 8 {
-			partner := (playerPos + 2) % 4
-			card, err := g.PassedCards().FromPlayer(partner)
+		if g.PassedCards().IsDone() && len(playerHand.Cards()) == 8 {
+			cards, err := g.PassedCards().ReceivedBy(playerPos)
 			if err != nil {
 				return nil, err
 			}
-			state.CardReceived = card.Encoded()
+			state.CardsReceived = cardsToStrings(cards)
 		}
 	}
 
@@ -226,8 +314,7 @@ func BuildGameState(g game.Game, player game.Player) (*GameStateResponse, error)
 	}
 
 	if g.Tally() != nil {
-		tally02, tally13 := g.Tally().Points()
-		state.TrickTally = []int{tally02, tally13}
+		state.TrickTally = g.Tally().Points()
 	}
 
 	if g.State() == game.BiddingState && playerPos == positionToPlay {
@@ -241,6 +328,29 @@ func BuildGameState(g game.Game, player game.Player) (*GameStateResponse, error)
 	return state, nil
 }
 
+func handRecords(hands []game.HandRecord) []HandRecord {
+	var res []HandRecord
+	for _, h := range hands {
+		hr := HandRecord{
+			ScoreIndex:         h.ScoreIndex,
+			DealerPosition:     h.DealerPos,
+			LeadBidPosition:    h.BidLeadPos,
+			BidsPlaced:         bidsToBidInfos(h.Bids),
+			WinningBidPosition: h.BidderPos,
+			Tricks:             h.Tricks,
+			Points:             h.Points,
+			FiveOfHeartsTeam:   h.FiveOfHeartsTeam,
+			ThreeOfSpadesTeam:  h.ThreeOfSpadesTeam,
+		}
+		if !h.ThrownIn() {
+			hr.WinningBid = bidToBidInfo(h.WinningBid)
+			hr.Trump = h.Trump.Encoded()
+		}
+		res = append(res, hr)
+	}
+	return res
+}
+
 func bidToBidInfo(b game.Bid) BidInfo {
 	return BidInfo{Code: b.Encoded(), Human: b.Human()}
 }
@@ -253,6 +363,14 @@ func bidsToBidInfos(bids []game.Bid) []BidInfo {
 	return res
 }
 
+func suitsToStrings(suits []deck.Suit) []string {
+	var res []string
+	for _, s := range suits {
+		res = append(res, s.Encoded())
+	}
+	return res
+}
+
 func cardsToStrings(cards []deck.Card) []string {
 	var res []string
 	for _, c := range cards {
@@ -5,6 +5,7 @@ import (
 	"net/http"
 
 	"github.com/squee1945/threespot/server/pkg/deck"
+	"github.com/squee1945/threespot/server/pkg/game"
 	"google.golang.org/appengine"
 )
 
@@ -16,7 +17,7 @@ type CallTrumpRequest struct {
 func (s *ApiServer) CallTrump(w http.ResponseWriter, r *http.Request) {
 	ctx := appengine.NewContext(r)
 	if r.Method != "POST" {
-		sendUserError(w, "Invalid method")
+		sendUserError(w, CodeMethodNotAllowed, "Invalid method")
 		return
 	}
 
@@ -28,7 +29,7 @@ func (s *ApiServer) CallTrump(w http.ResponseWriter, r *http.Request) {
 	var req CallTrumpRequest
 	err := json.NewDecoder(r.Body).Decode(&req)
 	if err != nil {
-		sendServerError(w, "decoding request: %v", err)
+		sendUserError(w, CodeBadRequest, "Invalid request: %v", err)
 		return
 	}
 
@@ -39,13 +40,15 @@ func (s *ApiServer) CallTrump(w http.ResponseWriter, r *http.Request) {
 
 	suit, err := deck.NewSuitFromEncoded(req.Suit)
 	if err != nil {
-		sendServerError(w, "creating suit: %v", err)
+		sendUserError(w, CodeBadRequest, "Invalid suit: %v", err)
 		return
 	}
 
-	newG, err := g.CallTrump(ctx, player, suit)
+	newG, err := s.updateGame(ctx, g, func(g game.Game) (game.Game, error) {
+		return g.CallTrump(ctx, player, suit)
+	})
 	if err != nil {
-		sendServerError(w, "calling trump: %v", err)
+		sendGameError(w, "calling trump", err)
 		return
 	}
 
@@ -0,0 +1,88 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/squee1945/threespot/server/pkg/game"
+	"google.golang.org/appengine"
+)
+
+type RequestUndoRequest struct {
+	ID string
+}
+
+type RespondToUndoRequest struct {
+	ID      string
+	Approve bool
+}
+
+func (s *ApiServer) RequestUndo(w http.ResponseWriter, r *http.Request) {
+	ctx := appengine.NewContext(r)
+	if r.Method != "POST" {
+		sendUserError(w, CodeMethodNotAllowed, "Invalid method")
+		return
+	}
+
+	player := s.lookupPlayer(ctx, w, r)
+	if player == nil {
+		return
+	}
+
+	var req RequestUndoRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		sendUserError(w, CodeBadRequest, "Invalid request: %v", err)
+		return
+	}
+
+	g := s.lookupGame(ctx, w, req.ID)
+	if g == nil {
+		return
+	}
+
+	newG, err := s.updateGame(ctx, g, func(g game.Game) (game.Game, error) {
+		return g.RequestUndo(ctx, player)
+	})
+	if err != nil {
+		sendGameError(w, "requesting undo", err)
+		return
+	}
+
+	s.sendGameState(ctx, w, newG, player)
+}
+
+func (s *ApiServer) RespondToUndo(w http.ResponseWriter, r *http.Request) {
+	ctx := appengine.NewContext(r)
+	if r.Method != "POST" {
+		sendUserError(w, CodeMethodNotAllowed, "Invalid method")
+		return
+	}
+
+	player := s.lookupPlayer(ctx, w, r)
+	if player == nil {
+		return
+	}
+
+	var req RespondToUndoRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		sendUserError(w, CodeBadRequest, "Invalid request: %v", err)
+		return
+	}
+
+	g := s.lookupGame(ctx, w, req.ID)
+	if g == nil {
+		return
+	}
+
+	newG, err := s.updateGame(ctx, g, func(g game.Game) (game.Game, error) {
+		return g.RespondToUndo(ctx, player, req.Approve)
+	})
+	if err != nil {
+		sendGameError(w, "responding to undo", err)
+		return
+	}
+
+	s.sendGameState(ctx, w, newG, player)
+}
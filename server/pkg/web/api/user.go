@@ -22,7 +22,7 @@ type UpdateUserResponse struct {
 func (s *ApiServer) UpdateUser(w http.ResponseWriter, r *http.Request) {
 	ctx := appengine.NewContext(r)
 	if r.Method != "POST" {
-		sendUserError(w, "Invalid method")
+		sendUserError(w, CodeMethodNotAllowed, "Invalid method")
 		return
 	}
 
@@ -34,13 +34,13 @@ func (s *ApiServer) UpdateUser(w http.ResponseWriter, r *http.Request) {
 
 	var req UpdateUserRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		sendServerError(w, "decoding request: %v", err)
+		sendUserError(w, CodeBadRequest, "Invalid request: %v", err)
 		return
 	}
 
 	name := strings.TrimSpace(req.Name)
 	if name == "" {
-		sendUserError(w, "Name is required.")
+		sendUserError(w, CodeBadRequest, "Name is required.")
 		return
 	}
 
@@ -43,6 +43,7 @@ func (s *Server) Index(w http.ResponseWriter, r *http.Request) {
 	args := indexArgs{
 		Welcome:    "Welcome back " + player.Name(),
 		Registered: true,
+		Presets:    game.Presets(),
 	}
 
 	games, err := game.GetCurrentGames(ctx, s.gameStore, s.playerStore, playerID, 10)
@@ -70,6 +71,7 @@ func (s *Server) Index(w http.ResponseWriter, r *http.Request) {
 type indexArgs struct {
 	Welcome      string
 	Registered   bool
+	Presets      []game.Preset
 	CurrentGames []gameInfo
 }
 
@@ -42,6 +42,7 @@ func (s *Server) Join(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	args.Players = g.Players()
+	args.RulesName = game.PresetName(g.Rules().Preset())
 
 	if g.State() != game.JoiningState {
 		http.Redirect(w, r, "/game/"+id, 301)
@@ -70,6 +71,7 @@ func (s *Server) Join(w http.ResponseWriter, r *http.Request) {
 type joinArgs struct {
 	ID         string
 	Players    []game.Player
+	RulesName  string // The name of the rule preset, or "Custom".
 	PlayerName string
 	HasName    template.JS // "true" or "false"
 	Address    string
@@ -12,6 +12,7 @@ var kaiser = (function() {
     var _state = null;
     var _lastVersion = null;
     var _lastUpdateEpochMs = null;
+    var _enforcedVersion = null;
 
     function init(gameID, options) {
         if (options) {
@@ -36,6 +37,7 @@ var kaiser = (function() {
                     _opt.repaint(gameState);
                 }
             }
+            enforceTurnClock(gameState);
             if (Date.now() - _lastUpdateEpochMs > (_opt.maxPollSeconds * 1000)) {
                 alert("Timeout waiting for players. Refresh page to continue.");
                 return;
@@ -46,6 +48,22 @@ var kaiser = (function() {
         });
     }
 
+    // enforceTurnClock asks the server, once per version, to act for the player to play if their time has run out,
+    // or to play the bots left to play.
+    function enforceTurnClock(gameState) {
+        if (gameState.Version == _enforcedVersion) {
+            return;
+        }
+        var pos = gameState.PositionToPlay;
+        var botToPlay = pos >= 0 && gameState.PlayerBots && gameState.PlayerBots[pos];
+        var timedOut = gameState.TurnDeadline > 0 && Date.now() >= gameState.TurnDeadline;
+        if (!botToPlay && !timedOut) {
+            return;
+        }
+        _enforcedVersion = gameState.Version;
+        server.enforceTurnClock(_id, function() {});
+    }
+
     function state() {
         return _state;
     }
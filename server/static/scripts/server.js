@@ -91,6 +91,87 @@ var server = (function() {
         .fail(alertFailure);
     }
 
+    function addBot(id, pos, strategy, done) {
+        var data = {
+            ID: id,
+            Position: pos,
+            Strategy: strategy,
+        }
+        $.ajax({
+            url: "/api/add-bot",
+            type: "POST",
+            dataType: "json",
+            contentType: "json",
+            data: JSON.stringify(data),
+        })
+        .done(done)
+        .fail(alertFailure);
+    }
+
+    function leaveGame(id, done) {
+        var data = {
+            ID: id,
+        }
+        $.ajax({
+            url: "/api/leave",
+            type: "POST",
+            dataType: "json",
+            contentType: "json",
+            data: JSON.stringify(data),
+        })
+        .done(done)
+        .fail(alertFailure);
+    }
+
+    function changeSeat(id, pos, done) {
+        var data = {
+            ID: id,
+            Position: pos,
+        }
+        $.ajax({
+            url: "/api/seat",
+            type: "POST",
+            dataType: "json",
+            contentType: "json",
+            data: JSON.stringify(data),
+        })
+        .done(done)
+        .fail(alertFailure);
+    }
+
+    function respondToSeatSwap(id, approve, done) {
+        var data = {
+            ID: id,
+            Approve: approve,
+        }
+        $.ajax({
+            url: "/api/seat-respond",
+            type: "POST",
+            dataType: "json",
+            contentType: "json",
+            data: JSON.stringify(data),
+        })
+        .done(done)
+        .fail(alertFailure);
+    }
+
+    function replacePlayer(id, pos, playerID, done) {
+        var data = {
+            ID: id,
+            Position: pos,
+            PlayerID: playerID,
+        }
+        $.ajax({
+            url: "/api/replace",
+            type: "POST",
+            dataType: "json",
+            contentType: "json",
+            data: JSON.stringify(data),
+        })
+        .done(done)
+        .fail(alertFailure);
+    }
+
     function dealCards(id, done) {
         var data = {
             ID: id,
@@ -154,6 +235,21 @@ var server = (function() {
         .fail(alertFailure);
     }
 
+    function claimMisdeal(id, done) {
+        var data = {
+            ID: id,
+        }
+        $.ajax({
+            url: "/api/misdeal",
+            type: "POST",
+            dataType: "json",
+            contentType: "json",
+            data: JSON.stringify(data),
+        })
+        .done(done)
+        .fail(alertFailure);
+    }
+
     function callTrump(id, suit, done) {
         var data = {
             ID: id,
@@ -170,18 +266,73 @@ var server = (function() {
         .fail(alertFailure);
     }
 
+    function requestUndo(id, done) {
+        var data = {
+            ID: id,
+        }
+        $.ajax({
+            url: "/api/undo",
+            type: "POST",
+            dataType: "json",
+            contentType: "json",
+            data: JSON.stringify(data),
+        })
+        .done(done)
+        .fail(alertFailure);
+    }
+
+    function respondToUndo(id, approve, done) {
+        var data = {
+            ID: id,
+            Approve: approve,
+        }
+        $.ajax({
+            url: "/api/undo-respond",
+            type: "POST",
+            dataType: "json",
+            contentType: "json",
+            data: JSON.stringify(data),
+        })
+        .done(done)
+        .fail(alertFailure);
+    }
+
+    function enforceTurnClock(id, done) {
+        var data = {
+            ID: id,
+        }
+        $.ajax({
+            url: "/api/turn-clock",
+            type: "POST",
+            dataType: "json",
+            contentType: "json",
+            data: JSON.stringify(data),
+        })
+        .done(done)
+        .fail(alertFailure);
+    }
+
     return {
         init: init,
         gameState: gameState,
+        enforceTurnClock: enforceTurnClock,
         joinState: joinState,
         updateUser: updateUser,
         newGame: newGame,
         joinGame: joinGame,
+        addBot: addBot,
+        leaveGame: leaveGame,
+        changeSeat: changeSeat,
+        respondToSeatSwap: respondToSeatSwap,
+        replacePlayer: replacePlayer,
         dealCards: dealCards,
         passCard: passCard,
+        claimMisdeal: claimMisdeal,
         placeBid: placeBid,
         playCard: playCard,
         callTrump: callTrump,
+        requestUndo: requestUndo,
+        respondToUndo: respondToUndo,
     };
 })();
 